@@ -23,11 +23,16 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/spf13/pflag"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/alog"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/auth"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/metrics"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/services"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/tracing"
+	"github.com/victor-skurikhin/etcd-client/v1/pool/etcd_pool"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/reflection"
@@ -55,7 +60,23 @@ func run(ctx context.Context) {
 		"build_date", buildDate,
 		"build_commit", buildCommit,
 	)
-	serve(ctx, env.GetConfig())
+	cfg := env.GetConfig()
+
+	if args := pflag.Args(); len(args) > 0 && args[0] == cmdMigrate {
+		sLog = cfg.Logger()
+		if err := migrate(ctx, cfg, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if args := pflag.Args(); len(args) > 0 && args[0] == cmdRotateKeys {
+		sLog = cfg.Logger()
+		if err := rotateKeys(ctx, cfg, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	serve(ctx, cfg)
 }
 
 func serve(ctx context.Context, cfg env.Config) {
@@ -70,6 +91,10 @@ func serve(ctx context.Context, cfg env.Config) {
 	sigint := make(chan os.Signal, 1)
 	signal.Notify(sigint, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
 
+	migrateOnStart(ctx, cfg)
+	registerMetrics(cfg)
+	shutdownTracing := setupTracing(ctx, cfg)
+	defer shutdownTracing()
 	httpServer := makeHTTP(ctx, cfg)
 	grpcServer := makeGRPC(ctx, cfg)
 
@@ -140,10 +165,38 @@ func makeHTTP(ctx context.Context, cfg env.Config) *fiber.App {
 		app.Use(logHandler)
 		micro.Use(logHandler)
 	}
+	app.Get("/metrics", metrics.Handler())
+	micro.Use(metrics.New())
+	micro.Use(tracing.New())
+	micro.Use(auth.New(cfg))
+
 	ctrl := controllers.GetEtcdProxyController(ctx, cfg)
 	micro.Delete("/delete/:name", ctrl.Delete)
 	micro.Get("/get/:name", ctrl.Get)
+	micro.Post("/lease", ctrl.LeaseGrant)
+	micro.Put("/lease/:id", ctrl.LeaseKeepAlive)
+	micro.Delete("/lease/:id", ctrl.LeaseRevoke)
+	micro.Get("/list", ctrl.List)
 	micro.Put("/put/:name", ctrl.Put)
+	micro.Post("/txn", ctrl.Txn)
+	micro.Get("/watch", ctrl.Watch)
+	micro.Get("/watch/*", ctrl.Watch)
+
+	kvCtrl := controllers.GetKeyValueDataController(ctx, cfg)
+	kv := micro.Group("/v2/kv")
+	kv.Get("/", kvCtrl.List)
+	kv.Post("/txn", kvCtrl.Txn)
+	kv.Delete("/:key", kvCtrl.Delete)
+	kv.Get("/:key", kvCtrl.Get)
+	kv.Put("/:key", kvCtrl.Put)
+	micro.Get("/deleted", kvCtrl.Deleted)
+	micro.Get("/history/:key", kvCtrl.History)
+	micro.Post("/rollback/:key", kvCtrl.Rollback)
+	micro.Post("/undelete/:key", kvCtrl.Undelete)
+
+	adminCtrl := controllers.GetAdminController(ctx, cfg)
+	adm := micro.Group("/admin")
+	adm.Post("/reconcile", adminCtrl.Reconcile)
 	micro.All("*", func(c *fiber.Ctx) error {
 		path := c.Path()
 		return c.
@@ -167,12 +220,49 @@ func makeGRPC(ctx context.Context, cfg env.Config) *grpc.Server {
 		}
 	}
 	srv := services.GetEtcdProxyService(ctx, cfg)
+	kvSrv := services.GetKeyValueDataService(ctx, cfg)
+	opts = append(opts,
+		tracing.ServerOption(),
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor(), auth.UnaryServerInterceptor(cfg)),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor(), auth.StreamServerInterceptor(cfg)),
+	)
 	grpcServer := grpc.NewServer(opts...)
 	pb.RegisterEtcdClientServiceServer(grpcServer, srv)
+	pb.RegisterKeyValueDataServiceServer(grpcServer, kvSrv)
 	reflection.Register(grpcServer)
 
 	return grpcServer
 }
 
+func registerMetrics(cfg env.Config) {
+
+	if err := metrics.RegisterEtcdPool(etcd_pool.GetPool(cfg).Stats); err != nil {
+		sLog.Error(MSG+"metrics", "msg", "etcd pool", "err", err)
+	}
+	if err := metrics.RegisterPgxPool(cfg.DBPool()); err != nil {
+		sLog.Error(MSG+"metrics", "msg", "pgx pool", "err", err)
+	}
+}
+
+// setupTracing настройка трассировки OpenTelemetry, возвращает
+// выгрузку накопленных спанов при завершении работы.
+func setupTracing(ctx context.Context, cfg env.Config) func() {
+
+	shutdown, err := tracing.Setup(ctx, cfg.TracingConfig())
+
+	if err != nil {
+		sLog.Error(MSG+"tracing", "msg", "setup", "err", err)
+		return func() {}
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := shutdown(ctx); err != nil {
+			sLog.Error(MSG+"tracing", "msg", "shutdown", "err", err)
+		}
+	}
+}
+
 //!-
 /* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,98 @@
+/*
+ * This file was last modified at 2026-10-18 18:20 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * migrate.go
+ * $Id$
+ */
+//!+
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/migrations"
+)
+
+const cmdMigrate = "migrate"
+
+var errMigrateUsage = errors.New("usage: etcd-proxy migrate up|down|status")
+
+// migrate подкоманда etcd-proxy migrate up|down|status:
+// up — применение всех новых миграций, down — откат последней,
+// status — список миграций с временем применения.
+func migrate(ctx context.Context, cfg env.Config, args []string) error {
+
+	if len(args) != 1 {
+		return errMigrateUsage
+	}
+	migrator, err := migrations.New(cfg)
+
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migrations\n", count)
+	case "down":
+		migration, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reverted %d_%s\n", migration.Version, migration.Name)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+
+		for _, s := range status {
+			appliedAt := "pending"
+			if !s.AppliedAt.IsZero() {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errMigrateUsage
+	}
+	return nil
+}
+
+// migrateOnStart применение миграций при запуске, если включено db.migrate.
+func migrateOnStart(ctx context.Context, cfg env.Config) {
+
+	if !cfg.YamlConfig().DBMigrate() || cfg.DBPool() == nil {
+		return
+	}
+	migrator, err := migrations.New(cfg)
+
+	if err != nil {
+		sLog.Error(MSG+"migrate", "msg", "load migrations", "err", err)
+		os.Exit(1)
+	}
+	count, err := migrator.Up(ctx)
+
+	if err != nil {
+		sLog.Error(MSG+"migrate", "msg", "migrations up", "err", err)
+		os.Exit(1)
+	}
+	sLog.Info(MSG+"migrate", "msg", "migrations up", "applied", count)
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
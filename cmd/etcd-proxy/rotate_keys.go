@@ -0,0 +1,49 @@
+/*
+ * This file was last modified at 2026-10-18 23:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * rotate_keys.go
+ * $Id$
+ */
+//!+
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/services"
+)
+
+const cmdRotateKeys = "rotate-keys"
+
+var errRotateKeysUsage = errors.New("usage: etcd-proxy rotate-keys")
+
+// rotateKeys подкоманда etcd-proxy rotate-keys: перешифрование значений
+// текущим мастер-ключом после его смены. Предыдущий закрытый ключ должен
+// оставаться в encryption.previous_private_key_files. Версии записей
+// PostgreSQL не меняются, а версии перешифрованных ключей etcd
+// увеличиваются: условная запись через etcd-proxy с прочитанной до
+// перешифрования версией получит конфликт версий и должна быть повторена.
+func rotateKeys(ctx context.Context, cfg env.Config, args []string) error {
+
+	if len(args) != 0 {
+		return errRotateKeysUsage
+	}
+	results, err := services.RotateKeys(ctx, cfg)
+
+	for _, result := range results {
+		fmt.Printf("%s: rotated %d, skipped %d\n", result.Store, result.Rotated, result.Skipped)
+
+		if result.Store == services.SourceOfTruthEtcd && result.Rotated > 0 {
+			fmt.Println("etcd: versions of rotated keys are bumped, conditional writes with older versions get 409")
+		}
+	}
+	return err
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -37,6 +37,10 @@ func (h *HandlerJSON) Handle(ctx context.Context, r slog.Record) error {
 	}
 	r.Add("requestId", slog.StringValue(id))
 
+	if a, ok := principalAttr(ctx); ok {
+		r.AddAttrs(a)
+	}
+
 	return h.Handler.Handle(ctx, r)
 }
 
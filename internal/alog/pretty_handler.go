@@ -48,6 +48,9 @@ func (h *PrettyHandler) Handle(ctx context.Context, r slog.Record) error {
 	case slog.LevelError:
 		level = color.RedString(r.Level.String())
 	}
+	if a, ok := principalAttr(ctx); ok {
+		r.AddAttrs(a)
+	}
 	buf := bytes.NewBuffer(make([]byte, 1024))
 	first := true
 	r.Attrs(func(a slog.Attr) bool {
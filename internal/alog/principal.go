@@ -0,0 +1,37 @@
+/*
+ * This file was last modified at 2026-10-18 21:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * principal.go
+ * $Id$
+ */
+//!+
+
+// Package alog кастомизация slog логгера.
+package alog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// PrincipalKey ключ контекста (и атрибута журнала) клиента,
+// прошедшего проверку подлинности.
+const PrincipalKey = "principal"
+
+// principalAttr атрибут журнала с клиентом, прошедшим проверку подлинности,
+// если такой есть в контексте.
+func principalAttr(ctx context.Context) (slog.Attr, bool) {
+	return principalValueAttr(ctx.Value(PrincipalKey))
+}
+
+func principalValueAttr(v any) (slog.Attr, bool) {
+	if p, ok := v.(fmt.Stringer); ok {
+		return slog.String(PrincipalKey, p.String()), true
+	}
+	return slog.Attr{}, false
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -42,6 +42,7 @@ var (
 		"authorization": {},
 		"cookie":        {},
 		"set-cookie":    {},
+		"x-api-key":     {},
 		"x-auth-token":  {},
 		"x-csrf-token":  {},
 		"x-xsrf-token":  {},
@@ -88,8 +89,8 @@ func New(logger *slog.Logger) fiber.Handler {
 		WithRequestHeader:  false,
 		WithResponseBody:   false,
 		WithResponseHeader: false,
-		WithSpanID:         false,
-		WithTraceID:        false,
+		WithSpanID:         true,
+		WithTraceID:        true,
 
 		Filters: []slogf.Filter{},
 	})
@@ -172,8 +173,12 @@ func NewWithConfig(logger *slog.Logger, config Config) fiber.Handler {
 			baseAttributes = append(baseAttributes, slog.String(RequestIDKey, requestID))
 		}
 
+		if a, ok := principalValueAttr(c.Context().UserValue(PrincipalKey)); ok {
+			baseAttributes = append(baseAttributes, a)
+		}
+
 		// otel
-		baseAttributes = append(baseAttributes, extractTraceSpanID(c.Context(), config.WithTraceID, config.WithSpanID)...)
+		baseAttributes = append(baseAttributes, extractTraceSpanID(c.UserContext(), config.WithTraceID, config.WithSpanID)...)
 
 		// request body
 		requestAttributes = append(requestAttributes, slog.Int("length", len((c.Body()))))
@@ -281,8 +286,10 @@ func extractTraceSpanID(ctx context.Context, withTraceID bool, withSpanID bool)
 		return []slog.Attr{}
 	}
 
+	// Спан к этому моменту уже может быть завершён промежуточным
+	// обработчиком трассировки, достаточно валидного контекста спана.
 	span := trace.SpanFromContext(ctx)
-	if !span.IsRecording() {
+	if !span.SpanContext().IsValid() {
 		return []slog.Attr{}
 	}
 
@@ -0,0 +1,200 @@
+/*
+ * This file was last modified at 2026-10-18 21:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * auth.go
+ * $Id$
+ */
+//!+
+
+// Package auth проверка подлинности клиентов HTTP и gRPC по статичным
+// API-ключам, JWT, подписанным RSA-ключами, и клиентским сертификатам.
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/alog"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/tool"
+	"strings"
+	"time"
+)
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+	MethodMTLS   = "mtls"
+)
+
+var (
+	ErrInvalidAPIKey = fmt.Errorf("invalid API key")
+	ErrInvalidToken  = fmt.Errorf("invalid bearer token")
+	ErrNoCredentials = fmt.Errorf("no credentials")
+)
+
+var jwtMethods = []string{"RS256", "RS384", "RS512"}
+
+// Principal клиент, прошедший проверку подлинности: способ проверки
+// и имя — имя API-ключа, subject (sub) JWT или имя из клиентского сертификата.
+type Principal struct {
+	Method string
+	Name   string
+}
+
+func (p Principal) String() string {
+	return p.Method + ":" + p.Name
+}
+
+// Authenticator проверка API-ключей и JWT по настройкам env.AuthConfig.
+type Authenticator struct {
+	config env.AuthConfig
+}
+
+func NewAuthenticator(config env.AuthConfig) *Authenticator {
+	return &Authenticator{config: config}
+}
+
+// Enabled при выключенной проверке подлинности запросы пропускаются,
+// Principal определяется только по клиентскому сертификату.
+func (a *Authenticator) Enabled() bool {
+	return a.config.Enabled
+}
+
+// Authenticate проверка API-ключа, а если он не передан — значения
+// заголовка Authorization со схемой Bearer.
+func (a *Authenticator) Authenticate(apiKey, authorization string) (Principal, error) {
+
+	if apiKey != "" {
+		return a.authenticateAPIKey(apiKey)
+	}
+	if authorization == "" {
+		return Principal{}, ErrNoCredentials
+	}
+	scheme, token, ok := strings.Cut(authorization, " ")
+
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return Principal{}, fmt.Errorf("%w: expected Bearer scheme", ErrInvalidToken)
+	}
+	return a.authenticateJWT(strings.TrimSpace(token))
+}
+
+// AuthenticateTLS проверка API-ключа или JWT, а если они не переданы —
+// клиентского сертификата, проверенного при установке TLS-соединения.
+func (a *Authenticator) AuthenticateTLS(state *tls.ConnectionState, apiKey, authorization string) (Principal, error) {
+
+	cert, ok := PrincipalFromTLS(state)
+
+	if !a.Enabled() {
+		if ok {
+			return cert, nil
+		}
+		return Principal{}, ErrNoCredentials
+	}
+	p, err := a.Authenticate(apiKey, authorization)
+
+	if errors.Is(err, ErrNoCredentials) && ok {
+		return cert, nil
+	}
+	return p, err
+}
+
+// authenticateAPIKey ключи хранятся и сравниваются по SHA-256,
+// поэтому время поиска не зависит от совпадающего префикса ключа.
+func (a *Authenticator) authenticateAPIKey(apiKey string) (Principal, error) {
+
+	if name, ok := a.config.APIKeys[tool.HashSHA256(apiKey)]; ok {
+		return Principal{Method: MethodAPIKey, Name: name}, nil
+	}
+	return Principal{}, ErrInvalidAPIKey
+}
+
+// authenticateJWT подпись проверяется каждым из настроенных ключей,
+// что позволяет менять ключи без остановки; exp и sub обязательны.
+func (a *Authenticator) authenticateJWT(token string) (Principal, error) {
+
+	err := fmt.Errorf("%w: no public keys", ErrInvalidToken)
+
+	for _, key := range a.config.JWTPublicKeys {
+		var claims *jwt.RegisteredClaims
+
+		if claims, err = a.parseJWT(token, key); err == nil {
+			return Principal{Method: MethodJWT, Name: claims.Subject}, nil
+		}
+	}
+	return Principal{}, err
+}
+
+func (a *Authenticator) parseJWT(token string, key *rsa.PublicKey) (*jwt.RegisteredClaims, error) {
+
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return key, nil
+	}, jwt.WithValidMethods(jwtMethods))
+
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	case !claims.VerifyExpiresAt(time.Now(), true):
+		return nil, fmt.Errorf("%w: exp required", ErrInvalidToken)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: sub required", ErrInvalidToken)
+	case a.config.JWTIssuer != "" && !claims.VerifyIssuer(a.config.JWTIssuer, true):
+		return nil, fmt.Errorf("%w: unexpected iss", ErrInvalidToken)
+	case a.config.JWTAudience != "" && !claims.VerifyAudience(a.config.JWTAudience, true):
+		return nil, fmt.Errorf("%w: unexpected aud", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// PrincipalFromTLS клиент по сертификату, проверенному при установке
+// TLS-соединения; без проверенной цепочки сертификатов — false.
+func PrincipalFromTLS(state *tls.ConnectionState) (Principal, bool) {
+
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return Principal{}, false
+	}
+	name := certificateName(state.VerifiedChains[0][0])
+
+	if name == "" {
+		return Principal{}, false
+	}
+	return Principal{Method: MethodMTLS, Name: name}, true
+}
+
+// certificateName имя клиента из сертификата: URI из SAN (SPIFFE ID
+// сервисной сетки, где CN обычно пуст), иначе CN, DNS-имя или e-mail из SAN.
+func certificateName(cert *x509.Certificate) string {
+
+	switch {
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0]
+	}
+	return ""
+}
+
+// FromContext клиент, прошедший проверку подлинности, из контекста запроса.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(alog.PrincipalKey).(Principal)
+	return p, ok
+}
+
+// NewContext контекст с клиентом, прошедшим проверку подлинности;
+// alog добавляет его в записи журнала.
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, alog.PrincipalKey, p)
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,308 @@
+/*
+ * This file was last modified at 2026-10-18 21:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * auth_test.go
+ * $Id$
+ */
+//!+
+
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/tool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	testAPIKey         = "test-api-key"
+	testPrivateKeyFile = "../../tool/test_private-key.pem"
+	testPublicKeyFile  = "../../tool/test_public-key.pem"
+	testServerCertFile = "../../tool/test_server-cert.pem"
+	testServerKeyFile  = "../../tool/test_server-key.pem"
+)
+
+func TestAuth(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for method Authenticate(apiKey, \"\")",
+			positiveAuthenticateAPIKey,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, Principal{Method: MethodAPIKey, Name: "test"}, i)
+			},
+		},
+		{
+			"test #1 negative for method Authenticate(apiKey, \"\") unknown key",
+			negativeAuthenticateAPIKey,
+			func(t *testing.T, i interface{}) bool { return assert.ErrorIs(t, i.(error), ErrInvalidAPIKey) },
+		},
+		{
+			"test #2 positive for method Authenticate(\"\", \"Bearer \"+jwt)",
+			positiveAuthenticateJWT,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, Principal{Method: MethodJWT, Name: "client1"}, i)
+			},
+		},
+		{
+			"test #3 negative for method Authenticate(\"\", \"Bearer \"+jwt) expired token",
+			negativeAuthenticateJWTExpired,
+			func(t *testing.T, i interface{}) bool { return assert.ErrorIs(t, i.(error), ErrInvalidToken) },
+		},
+		{
+			"test #4 negative for method Authenticate(\"\", \"Bearer \"+jwt) unexpected audience",
+			negativeAuthenticateJWTAudience,
+			func(t *testing.T, i interface{}) bool { return assert.ErrorIs(t, i.(error), ErrInvalidToken) },
+		},
+		{
+			"test #5 positive for function New() with and without X-API-Key",
+			positiveNew,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []int{fiber.StatusOK, fiber.StatusUnauthorized}, i)
+			},
+		},
+		{
+			"test #6 positive for function UnaryServerInterceptor() with and without metadata",
+			positiveUnaryServerInterceptor,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []string{"api_key:test", codes.Unauthenticated.String()}, i)
+			},
+		},
+		{
+			"test #7 positive for method AuthenticateTLS(state, \"\", \"\") client certificate",
+			positiveAuthenticateTLS,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []string{"mtls:localhost", "api_key:test", "mtls:localhost"}, i)
+			},
+		},
+		{
+			"test #8 negative for method AuthenticateTLS(nil, \"\", \"\") auth disabled",
+			negativeAuthenticateTLS,
+			func(t *testing.T, i interface{}) bool { return assert.ErrorIs(t, i.(error), ErrNoCredentials) },
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveAuthenticateAPIKey(_ *testing.T) (interface{}, error) {
+	return NewAuthenticator(testAuthConfig()).Authenticate(testAPIKey, "")
+}
+
+func negativeAuthenticateAPIKey(_ *testing.T) (interface{}, error) {
+
+	_, err := NewAuthenticator(testAuthConfig()).Authenticate("unknown", "")
+
+	if err == nil {
+		return nil, errors.New("unknown API key accepted")
+	}
+	return err, nil
+}
+
+func positiveAuthenticateJWT(_ *testing.T) (interface{}, error) {
+
+	token, err := testJWT(jwt.RegisteredClaims{
+		Audience:  jwt.ClaimStrings{"etcd-proxy"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		Issuer:    "etcd-client-test",
+		Subject:   "client1",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewAuthenticator(testAuthConfig()).Authenticate("", "Bearer "+token)
+}
+
+func negativeAuthenticateJWTExpired(_ *testing.T) (interface{}, error) {
+
+	token, err := testJWT(jwt.RegisteredClaims{
+		Audience:  jwt.ClaimStrings{"etcd-proxy"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		Issuer:    "etcd-client-test",
+		Subject:   "client1",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = NewAuthenticator(testAuthConfig()).Authenticate("", "Bearer "+token); err == nil {
+		return nil, errors.New("expired token accepted")
+	}
+	return err, nil
+}
+
+func negativeAuthenticateJWTAudience(_ *testing.T) (interface{}, error) {
+
+	token, err := testJWT(jwt.RegisteredClaims{
+		Audience:  jwt.ClaimStrings{"other"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		Issuer:    "etcd-client-test",
+		Subject:   "client1",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = NewAuthenticator(testAuthConfig()).Authenticate("", "Bearer "+token); err == nil {
+		return nil, errors.New("token for other audience accepted")
+	}
+	return err, nil
+}
+
+func positiveNew(t *testing.T) (interface{}, error) {
+
+	app := fiber.New()
+	app.Use(New(testConfig(t)))
+	app.Get("/get/:name", func(c *fiber.Ctx) error {
+		if p, ok := FromContext(c.UserContext()); !ok || p.Name != "test" {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendString("ok")
+	})
+	var result []int
+
+	for _, key := range []string{testAPIKey, ""} {
+		req := httptest.NewRequest(fiber.MethodGet, "/get/key1", nil)
+		if key != "" {
+			req.Header.Set(HeaderAPIKey, key)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, resp.StatusCode)
+	}
+	return result, nil
+}
+
+func positiveUnaryServerInterceptor(t *testing.T) (interface{}, error) {
+
+	interceptor := UnaryServerInterceptor(testConfig(t))
+	info := &grpc.UnaryServerInfo{FullMethod: "/proto.KeyValueDataService/ApiGet"}
+	handler := func(ctx context.Context, _ any) (any, error) {
+		p, _ := FromContext(ctx)
+		return p.String(), nil
+	}
+	var result []string
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", testAPIKey))
+	got, err := interceptor(ctx, nil, info, handler)
+
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, got.(string))
+	_, err = interceptor(context.Background(), nil, info, handler)
+	result = append(result, status.Code(err).String())
+
+	return result, nil
+}
+
+func positiveAuthenticateTLS(_ *testing.T) (interface{}, error) {
+
+	state, err := testTLSState()
+
+	if err != nil {
+		return nil, err
+	}
+	a := NewAuthenticator(testAuthConfig())
+	cert, err := a.AuthenticateTLS(state, "", "")
+
+	if err != nil {
+		return nil, err
+	}
+	key, err := a.AuthenticateTLS(state, testAPIKey, "")
+
+	if err != nil {
+		return nil, err
+	}
+	disabled, err := NewAuthenticator(env.AuthConfig{}).AuthenticateTLS(state, "", "")
+
+	if err != nil {
+		return nil, err
+	}
+	return []string{cert.String(), key.String(), disabled.String()}, nil
+}
+
+func negativeAuthenticateTLS(_ *testing.T) (interface{}, error) {
+
+	_, err := NewAuthenticator(env.AuthConfig{}).AuthenticateTLS(nil, "", "")
+
+	if err == nil {
+		return nil, errors.New("principal without credentials")
+	}
+	return err, nil
+}
+
+func testAuthConfig() env.AuthConfig {
+	return env.AuthConfig{
+		APIKeys:       map[string]string{tool.HashSHA256(testAPIKey): "test"},
+		Enabled:       true,
+		JWTAudience:   "etcd-proxy",
+		JWTIssuer:     "etcd-client-test",
+		JWTPublicKeys: []*rsa.PublicKey{tool.LoadPublicKey(testPublicKeyFile)},
+	}
+}
+
+func testConfig(t *testing.T) env.Config {
+
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+
+	cfg := env.GetConfig().(env.TestConfig)
+
+	return cfg.GetTestConfig(env.WithAuthConfig(testAuthConfig()))
+}
+
+func testJWT(claims jwt.RegisteredClaims) (string, error) {
+
+	key := tool.LoadPrivateKey(testPrivateKeyFile)
+
+	if key == nil {
+		return "", errors.New("can't load " + testPrivateKeyFile)
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+}
+
+// testTLSState состояние соединения с проверенным клиентским сертификатом.
+func testTLSState() (*tls.ConnectionState, error) {
+
+	pair, err := tls.LoadX509KeyPair(testServerCertFile, testServerKeyFile)
+
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+
+	if err != nil {
+		return nil, err
+	}
+	return &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}, nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
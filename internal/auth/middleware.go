@@ -0,0 +1,144 @@
+/*
+ * This file was last modified at 2026-10-18 21:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * middleware.go
+ * $Id$
+ */
+//!+
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/gofiber/fiber/v2"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/alog"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+const (
+	HeaderAPIKey   = "X-API-Key"
+	metadataAPIKey = "x-api-key"
+	metadataBearer = "authorization"
+)
+
+// New — промежуточный обработчик Fiber: проверка API-ключа из заголовка
+// X-API-Key, JWT из заголовка Authorization или клиентского сертификата,
+// без них — 401. Principal доступен обработчикам через FromContext.
+func New(cfg env.Config) fiber.Handler {
+
+	a := NewAuthenticator(cfg.AuthConfig())
+	sLog := cfg.Logger()
+
+	return func(c *fiber.Ctx) error {
+
+		p, err := a.AuthenticateTLS(c.Context().TLSConnectionState(), c.Get(HeaderAPIKey), c.Get(fiber.HeaderAuthorization))
+
+		if err != nil {
+			if !a.Enabled() {
+				return c.Next()
+			}
+			sLog.WarnContext(c.Context(), env.MSG+"auth.New", "msg", "unauthenticated", "path", c.Path(), "err", err)
+			c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+			return c.
+				Status(fiber.StatusUnauthorized).
+				JSON(dto.StatusMessage{Status: "fail", Message: "unauthorized"})
+		}
+		c.Context().SetUserValue(alog.PrincipalKey, p)
+		c.SetUserContext(NewContext(c.UserContext(), p))
+
+		return c.Next()
+	}
+}
+
+// UnaryServerInterceptor — проверка API-ключа или JWT из метаданных
+// x-api-key и authorization или клиентского сертификата унарных gRPC запросов.
+func UnaryServerInterceptor(cfg env.Config) grpc.UnaryServerInterceptor {
+
+	a := NewAuthenticator(cfg.AuthConfig())
+	sLog := cfg.Logger()
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+
+		ctx, err := authenticateGRPC(ctx, a, sLog, info.FullMethod)
+
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor — проверка API-ключа или JWT из метаданных
+// x-api-key и authorization или клиентского сертификата потоковых gRPC запросов.
+func StreamServerInterceptor(cfg env.Config) grpc.StreamServerInterceptor {
+
+	a := NewAuthenticator(cfg.AuthConfig())
+	sLog := cfg.Logger()
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		ctx, err := authenticateGRPC(ss.Context(), a, sLog, info.FullMethod)
+
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticateGRPC(ctx context.Context, a *Authenticator, sLog *slog.Logger, method string) (context.Context, error) {
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	p, err := a.AuthenticateTLS(peerTLSState(ctx), firstMetadata(md, metadataAPIKey), firstMetadata(md, metadataBearer))
+
+	if err != nil {
+		if !a.Enabled() {
+			return ctx, nil
+		}
+		sLog.WarnContext(ctx, env.MSG+"auth.authenticateGRPC", "msg", "unauthenticated", "method", method, "err", err)
+		return ctx, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return NewContext(ctx, p), nil
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// peerTLSState состояние TLS-соединения gRPC клиента, nil — без TLS.
+func peerTLSState(ctx context.Context) *tls.ConnectionState {
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			return &info.State
+		}
+	}
+	return nil
+}
+
+// serverStream поток с контекстом, содержащим Principal.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
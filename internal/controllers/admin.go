@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/services"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// adminReconcileTimeout сверка обходит оба хранилища целиком,
+// поэтому таймаут больше чем у запросов по одному ключу.
+const adminReconcileTimeout = time.Minute
+
+type Admin interface {
+	Reconcile(*fiber.Ctx) error
+}
+
+type admin struct {
+	keyValueDataService services.KeyValueDataService
+	sLog                *slog.Logger
+}
+
+var _ Admin = (*admin)(nil)
+var (
+	onceAdmin = new(sync.Once)
+	adminCont *admin
+)
+
+// GetAdminController — потокобезопасное (thread-safe) создание
+// REST веб-сервиса административных операций.
+func GetAdminController(ctx context.Context, cfg env.Config) Admin {
+
+	onceAdmin.Do(func() {
+		adminCont = new(admin)
+		adminCont.keyValueDataService = services.GetKeyValueDataService(ctx, cfg)
+		adminCont.sLog = cfg.Logger()
+	})
+	return adminCont
+}
+
+// Reconcile запуск сверки etcd и PostgreSQL, по умолчанию dry_run=true —
+// только отчёт о расхождениях; с dry_run=false расхождения исправляются.
+func (a *admin) Reconcile(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := contextWithRequestIdentity(fCtx, adminReconcileTimeout)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	report, err := a.keyValueDataService.ApiReconcile(ctxCancel.ctx, fCtx.QueryBool("dry_run", true))
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultRequestID{Status: "success", Result: report, RequestID: identity.RequestID})
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/tool"
+)
+
+// AsOf момент чтения: ревизия etcd Revision или время At, нулевое
+// значение — чтение текущего состояния.
+type AsOf struct {
+	At       time.Time
+	Revision int64
+}
+
+func (a AsOf) IsZero() bool {
+	return a.At.IsZero() && a.Revision == 0
+}
+
+type KeyValueData struct {
+	Key       string     `json:"key"`
+	Value     string     `json:"value"`
+	Version   int64      `json:"version"`
+	Deleted   bool       `json:"deleted"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+func MakeKeyValueData(unit entity.KeyValue) KeyValueData {
+	return KeyValueData{
+		Key:       unit.Key(),
+		Value:     unit.Value(),
+		Version:   unit.Version(),
+		Deleted:   unit.Deleted(),
+		CreatedAt: unit.CreatedAt(),
+		UpdatedAt: tool.ConvertNullTimeToTimePointer(unit.UpdatedAt()),
+	}
+}
+
+func MakeKeyValueDataList(list List[entity.KeyValue]) List[KeyValueData] {
+
+	result := List[KeyValueData]{Items: make([]KeyValueData, 0, len(list.Items)), Next: list.Next, More: list.More}
+
+	for _, unit := range list.Items {
+		result.Items = append(result.Items, MakeKeyValueData(unit))
+	}
+	return result
+}
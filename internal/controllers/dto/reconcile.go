@@ -0,0 +1,18 @@
+package dto
+
+// ReconcileReport отчёт сверки записей etcd и PostgreSQL.
+type ReconcileReport struct {
+	DryRun            bool     `json:"dry_run"`
+	SourceOfTruth     string   `json:"source_of_truth"`
+	Checked           int      `json:"checked"`
+	MissingInEtcd     []string `json:"missing_in_etcd"`
+	MissingInPostgres []string `json:"missing_in_postgres"`
+	ValueMismatch     []string `json:"value_mismatch"`
+	Repaired          int      `json:"repaired"`
+	Failed            []string `json:"failed,omitempty"`
+}
+
+// Drift количество расхождений найденных сверкой.
+func (r ReconcileReport) Drift() int {
+	return len(r.MissingInEtcd) + len(r.MissingInPostgres) + len(r.ValueMismatch)
+}
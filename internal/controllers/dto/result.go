@@ -1,10 +1,27 @@
 package dto
 
 type KeyValue struct {
-	Key   string `json:"key" validate:"required"`
-	Value string `json:"value" validate:"required"`
+	Key        string `json:"key" validate:"required"`
+	Value      string `json:"value" validate:"required"`
+	Version    int64  `json:"version,omitempty"`
+	TTLSeconds int64  `json:"ttl_seconds,omitempty" validate:"gte=0"`
+	Lease      int64  `json:"lease,omitempty" validate:"gte=0"`
+}
+
+type Lease struct {
+	ID         int64 `json:"id"`
+	TTLSeconds int64 `json:"ttl_seconds" validate:"gte=0"`
+}
+
+type List[T any] struct {
+	Items []T    `json:"items"`
+	Next  string `json:"next,omitempty"`
+	More  bool   `json:"more"`
 }
 
 type Result struct {
-	Value string `json:"value" validate:"required"`
+	Value      string `json:"value" validate:"required"`
+	Version    int64  `json:"version,omitempty"`
+	TTLSeconds int64  `json:"ttl_seconds,omitempty" validate:"gte=0"`
+	Lease      int64  `json:"lease,omitempty" validate:"gte=0"`
 }
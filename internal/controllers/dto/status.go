@@ -26,6 +26,13 @@ type StatusMessageStringRequestID struct {
 	RequestID string
 }
 
+type StatusMessageVersionRequestID struct {
+	Status    string
+	Message   string
+	RequestID uuid.UUID
+	Version   int64
+}
+
 type StatusRequestID struct {
 	Status    string
 	RequestID uuid.UUID
@@ -37,6 +44,13 @@ type StatusResultRequestID struct {
 	Result    any
 }
 
+type StatusResultSourceRequestID struct {
+	Status    string
+	RequestID uuid.UUID
+	Result    any
+	Source    string
+}
+
 func StatusMessagePathDoesNotExists(path string) StatusMessage {
 	return StatusMessage{
 		Status:  "fail",
@@ -0,0 +1,25 @@
+package dto
+
+type TxnCompare struct {
+	Key     string `json:"key" validate:"required"`
+	Target  string `json:"target" validate:"omitempty,oneof=version value"`
+	Result  string `json:"result" validate:"omitempty,oneof=equal not_equal greater less"`
+	Version int64  `json:"version,omitempty"`
+	Value   string `json:"value,omitempty"`
+}
+
+type TxnOp struct {
+	Type  string `json:"type" validate:"oneof=PUT DELETE put delete"`
+	Key   string `json:"key" validate:"required"`
+	Value string `json:"value,omitempty"`
+}
+
+type TxnRequest struct {
+	Compare []TxnCompare `json:"compare" validate:"dive"`
+	Success []TxnOp      `json:"success" validate:"dive"`
+	Failure []TxnOp      `json:"failure" validate:"dive"`
+}
+
+type TxnResult struct {
+	Succeeded bool `json:"succeeded"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+const (
+	EventDelete = "DELETE"
+	EventPut    = "PUT"
+)
+
+type WatchRequest struct {
+	Prefix        string
+	StartRevision int64
+	EventTypes    []string `validate:"dive,oneof=PUT DELETE put delete"`
+}
+
+type WatchEvent struct {
+	Type     string `json:"type"`
+	Key      string `json:"key"`
+	Value    string `json:"value,omitempty"`
+	Version  int64  `json:"version,omitempty"`
+	Revision int64  `json:"revision"`
+}
@@ -1,14 +1,25 @@
 package controllers
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"fmt"
+	"github.com/goccy/go-json"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/auth"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/rbac"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/services"
+	"go.opentelemetry.io/otel/trace"
 	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	clientV3 "go.etcd.io/etcd/client/v3"
 )
@@ -16,7 +27,13 @@ import (
 type EtcdProxy interface {
 	Delete(*fiber.Ctx) error
 	Get(*fiber.Ctx) error
+	LeaseGrant(*fiber.Ctx) error
+	LeaseKeepAlive(*fiber.Ctx) error
+	LeaseRevoke(*fiber.Ctx) error
+	List(*fiber.Ctx) error
 	Put(*fiber.Ctx) error
+	Txn(*fiber.Ctx) error
+	Watch(*fiber.Ctx) error
 }
 
 type etcdProxy struct {
@@ -25,6 +42,8 @@ type etcdProxy struct {
 	sLog             *slog.Logger
 }
 
+const watchHeartbeat = 15 * time.Second
+
 type tContext struct {
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -65,15 +84,13 @@ func (f *etcdProxy) Delete(fCtx *fiber.Ctx) error {
 	}
 	defer ctxCancel.cancel()
 	key := fCtx.Params("name", "default")
+	expectedVersion, err := ifMatchVersion(fCtx)
 
-	if err = f.etcdProxyService.ApiDelete(ctxCancel.ctx, key); err != nil {
-		return fCtx.
-			Status(fiber.StatusBadRequest).
-			JSON(dto.StatusMessageRequestID{
-				Status:    "fail",
-				Message:   err.Error(),
-				RequestID: identity.RequestID,
-			})
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	if err = f.etcdProxyService.ApiDelete(ctxCancel.ctx, key, expectedVersion); err != nil {
+		return failResponse(fCtx, err, identity)
 	} else {
 		return fCtx.
 			Status(fiber.StatusOK).
@@ -93,19 +110,114 @@ func (f *etcdProxy) Get(fCtx *fiber.Ctx) error {
 	defer ctxCancel.cancel()
 	key := fCtx.Params("name", "default")
 
+	if err = asOfUnsupported(fCtx); err != nil {
+		return failResponse(fCtx, err, identity)
+	}
 	if result, err := f.etcdProxyService.ApiGet(ctxCancel.ctx, key); err != nil {
-		code := fiber.StatusBadRequest
+		return failResponse(fCtx, err, identity)
+	} else {
+		setETag(fCtx, result.Version)
+		return fCtx.
+			Status(fiber.StatusOK).
+			JSON(dto.StatusResultRequestID{Status: "success", Result: result, RequestID: identity.RequestID})
+	}
+}
 
-		if err == services.ErrNotFound {
-			code = fiber.StatusNotFound
-		}
+func (f *etcdProxy) LeaseGrant(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := f.contextWithRequestIdentity(fCtx)
+
+	if err != nil {
 		return fCtx.
-			Status(code).
-			JSON(dto.StatusMessageRequestID{
-				Status:    "fail",
-				Message:   err.Error(),
-				RequestID: identity.RequestID,
-			})
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	var payload dto.Lease
+
+	if err = fCtx.BodyParser(&payload); err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	if errors := dto.ValidateStruct(payload); errors != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(errors)
+	}
+	result, err := f.etcdProxyService.ApiLeaseGrant(ctxCancel.ctx, payload.TTLSeconds)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultRequestID{Status: "success", Result: result, RequestID: identity.RequestID})
+}
+
+func (f *etcdProxy) LeaseKeepAlive(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := f.contextWithRequestIdentity(fCtx)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	id, err := leaseID(fCtx)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	result, err := f.etcdProxyService.ApiLeaseKeepAlive(ctxCancel.ctx, id)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultRequestID{Status: "success", Result: result, RequestID: identity.RequestID})
+}
+
+func (f *etcdProxy) LeaseRevoke(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := f.contextWithRequestIdentity(fCtx)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	id, err := leaseID(fCtx)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	if err = f.etcdProxyService.ApiLeaseRevoke(ctxCancel.ctx, id); err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusRequestID{Status: "success", RequestID: identity.RequestID})
+}
+
+func (f *etcdProxy) List(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := f.contextWithRequestIdentity(fCtx)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	prefix, from, limit, keysOnly := listQuery(fCtx)
+
+	if err = asOfUnsupported(fCtx); err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	if result, err := f.etcdProxyService.ApiList(ctxCancel.ctx, prefix, from, limit, keysOnly); err != nil {
+		return failResponse(fCtx, err, identity)
 	} else {
 		return fCtx.
 			Status(fiber.StatusOK).
@@ -151,23 +263,136 @@ func (f *etcdProxy) Put(fCtx *fiber.Ctx) error {
 			})
 	}
 	key := fCtx.Params("name", "default")
+	expectedVersion, err := ifMatchVersion(fCtx)
 
-	if err = f.etcdProxyService.ApiPut(ctxCancel.ctx, dto.KeyValue{Key: key, Value: payload.Value}); err != nil {
-		return fCtx.
-			Status(fiber.StatusBadRequest).
-			JSON(dto.StatusMessageRequestID{
-				Status:    "fail",
-				Message:   err.Error(),
-				RequestID: identity.RequestID,
-			})
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	if result, err := f.etcdProxyService.ApiPut(ctxCancel.ctx, dto.KeyValue{
+		Key:        key,
+		Value:      payload.Value,
+		TTLSeconds: payload.TTLSeconds,
+		Lease:      payload.Lease,
+	}, expectedVersion); err != nil {
+		return failResponse(fCtx, err, identity)
 	} else {
+		setETag(fCtx, result.Version)
 		return fCtx.
 			Status(fiber.StatusOK).
-			JSON(dto.StatusRequestID{Status: "success", RequestID: identity.RequestID})
+			JSON(dto.StatusResultRequestID{Status: "success", Result: result, RequestID: identity.RequestID})
+	}
+}
+
+func (f *etcdProxy) Txn(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := f.contextWithRequestIdentity(fCtx)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	var payload dto.TxnRequest
+
+	if err = fCtx.BodyParser(&payload); err != nil {
+		return failResponse(fCtx, err, identity)
 	}
+	if errors := dto.ValidateStruct(payload); errors != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(errors)
+	}
+	result, err := f.etcdProxyService.ApiTxn(ctxCancel.ctx, payload)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultRequestID{Status: "success", Result: result, RequestID: identity.RequestID})
+}
+
+// Watch — подписка на изменения ключей по префиксу в формате Server-Sent Events.
+func (f *etcdProxy) Watch(fCtx *fiber.Ctx) error {
+
+	var requestId uuid.UUID
+
+	if id, ok := fCtx.Locals("requestid").(string); ok {
+		var err error
+		if requestId, err = uuid.Parse(id); err != nil {
+			return fCtx.
+				Status(fiber.StatusBadRequest).
+				JSON(dto.StatusMessageInvalidRequestID(id))
+		}
+	}
+	request := watchQuery(fCtx)
+
+	if errors := dto.ValidateStruct(request); errors != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(errors)
+	}
+	ctx := context.WithValue(context.Background(), "request-id", requestId.String())
+
+	if principal, ok := auth.FromContext(fCtx.Context()); ok {
+		ctx = auth.NewContext(ctx, principal)
+	}
+	if err := f.etcdProxyService.ApiAuthorizeWatch(ctx, request.Prefix); err != nil {
+		return failResponse(fCtx, err, tIdentity{RequestID: requestId})
+	}
+	fCtx.Set(fiber.HeaderContentType, "text/event-stream")
+	fCtx.Set(fiber.HeaderCacheControl, "no-cache")
+	fCtx.Set(fiber.HeaderConnection, "keep-alive")
+
+	ctx, cancel := context.WithCancel(ctx)
+	events := make(chan dto.WatchEvent)
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- f.etcdProxyService.ApiWatch(ctx, request, func(event dto.WatchEvent) error {
+			select {
+			case events <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	fCtx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+
+		defer cancel()
+		ticker := time.NewTicker(watchHeartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event := <-events:
+				data, _ := json.Marshal(event)
+				_, _ = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Revision, strings.ToLower(event.Type), data)
+			case <-ticker.C:
+				_, _ = fmt.Fprint(w, ": heartbeat\n\n")
+			case err := <-errc:
+				if err != nil && ctx.Err() == nil {
+					_, _ = fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
+					_ = w.Flush()
+				}
+				return
+			}
+			if err := w.Flush(); err != nil {
+				f.sLog.DebugContext(ctx, env.MSG+"etcdProxy.Watch", "msg", "client disconnected", "err", err)
+				return
+			}
+		}
+	})
+	return nil
 }
 
 func (f *etcdProxy) contextWithRequestIdentity(fCtx *fiber.Ctx) (tContext, tIdentity, error) {
+	return contextWithRequestIdentity(fCtx, f.clientConfig.DialTimeout)
+}
+
+func contextWithRequestIdentity(fCtx *fiber.Ctx, timeout time.Duration) (tContext, tIdentity, error) {
 
 	var err error
 	var requestId uuid.UUID
@@ -177,9 +402,152 @@ func (f *etcdProxy) contextWithRequestIdentity(fCtx *fiber.Ctx) (tContext, tIden
 			return tContext{}, tIdentity{ID: id}, err
 		}
 	}
+	// Спан запроса из промежуточного обработчика трассировки
+	// переносится в контекст вызова сервисов.
 	ctx, cancel := context.WithTimeout(
-		context.WithValue(fCtx.Context(), "request-id", requestId.String()),
-		f.clientConfig.DialTimeout,
+		context.WithValue(
+			trace.ContextWithSpan(fCtx.Context(), trace.SpanFromContext(fCtx.UserContext())),
+			"request-id", requestId.String(),
+		),
+		timeout,
 	)
 	return tContext{ctx: ctx, cancel: cancel}, tIdentity{RequestID: requestId}, nil
 }
+
+func listQuery(fCtx *fiber.Ctx) (prefix, from string, limit int64, keysOnly bool) {
+	return fCtx.Query("prefix"),
+		fCtx.Query("from"),
+		int64(fCtx.QueryInt("limit", 0)),
+		fCtx.QueryBool("keys_only", false)
+}
+
+// watchQuery параметры подписки: префикс передаётся параметром запроса prefix,
+// как в List, так как префиксы ключей etcd обычно содержат "/".
+func watchQuery(fCtx *fiber.Ctx) dto.WatchRequest {
+
+	request := dto.WatchRequest{
+		Prefix:        watchPrefix(fCtx),
+		StartRevision: int64(fCtx.QueryInt("rev", 0)),
+	}
+	if request.StartRevision < 1 {
+		// Возобновление потока после переподключения клиента.
+		if lastEventID, err := strconv.ParseInt(fCtx.Get("Last-Event-ID"), 10, 64); err == nil {
+			request.StartRevision = lastEventID + 1
+		}
+	}
+	if types := fCtx.Query("types"); types != "" {
+		request.EventTypes = strings.Split(types, ",")
+	}
+	return request
+}
+
+// watchPrefix префикс подписки из пути /watch/<prefix>, префикс может
+// содержать «/»; по маршруту /watch — из параметра prefix. Префикс
+// берётся из исходного пути: параметр маршрута теряет завершающий «/».
+func watchPrefix(fCtx *fiber.Ctx) string {
+
+	if fCtx.Params("*") == "" {
+		return fCtx.Query("prefix")
+	}
+	prefix := strings.TrimPrefix(fCtx.Path(), strings.TrimSuffix(fCtx.Route().Path, "*"))
+
+	if unescaped, err := url.PathUnescape(prefix); err == nil {
+		return unescaped
+	}
+	return prefix
+}
+
+// asOfQuery момент чтения из параметров revision и at (RFC3339).
+func asOfQuery(fCtx *fiber.Ctx) (dto.AsOf, error) {
+
+	var result dto.AsOf
+
+	if revision := fCtx.Query("revision"); revision != "" {
+		value, err := strconv.ParseInt(revision, 10, 64)
+
+		if err != nil {
+			return result, fmt.Errorf("bad revision parameter: %q", revision)
+		}
+		result.Revision = value
+	}
+	if at := fCtx.Query("at"); at != "" {
+		value, err := time.Parse(time.RFC3339, at)
+
+		if err != nil {
+			return result, fmt.Errorf("bad at parameter: %q", at)
+		}
+		result.At = value
+	}
+	return result, nil
+}
+
+// asOfUnsupported etcd-proxy читает только текущее состояние: параметры
+// revision и at отклоняются, а не пропускаются молча.
+func asOfUnsupported(fCtx *fiber.Ctx) error {
+
+	if fCtx.Query("revision") != "" || fCtx.Query("at") != "" {
+		return services.ErrAsOfUnsupported
+	}
+	return nil
+}
+
+// failResponse ответ с ошибкой: 403 при отсутствии прав на ключ, 404 для
+// отсутствующего ключа, 409 с текущей версией при конфликте версий, иначе 400.
+func failResponse(fCtx *fiber.Ctx, err error, identity tIdentity) error {
+
+	var conflict services.VersionConflictError
+
+	if errors.As(err, &conflict) {
+		return fCtx.
+			Status(fiber.StatusConflict).
+			JSON(dto.StatusMessageVersionRequestID{
+				Status:    "fail",
+				Message:   err.Error(),
+				RequestID: identity.RequestID,
+				Version:   conflict.Current,
+			})
+	}
+	code := fiber.StatusBadRequest
+
+	if errors.Is(err, rbac.ErrPermissionDenied) {
+		code = fiber.StatusForbidden
+	} else if errors.Is(err, services.ErrNotFound) {
+		code = fiber.StatusNotFound
+	} else if errors.Is(err, services.ErrReconcileInProgress) {
+		code = fiber.StatusConflict
+	}
+	return fCtx.
+		Status(code).
+		JSON(dto.StatusMessageRequestID{
+			Status:    "fail",
+			Message:   err.Error(),
+			RequestID: identity.RequestID,
+		})
+}
+
+// ifMatchVersion ожидаемая версия записи из заголовка If-Match,
+// nil — если заголовок не задан.
+func ifMatchVersion(fCtx *fiber.Ctx) (*int64, error) {
+
+	header := strings.TrimSpace(fCtx.Get(fiber.HeaderIfMatch))
+
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(header, "W/"), `"`), 10, 64)
+
+	if err != nil || version < 0 {
+		return nil, fmt.Errorf("bad %s header: %q", fiber.HeaderIfMatch, header)
+	}
+	return &version, nil
+}
+
+func leaseID(fCtx *fiber.Ctx) (int64, error) {
+	return strconv.ParseInt(fCtx.Params("id"), 10, 64)
+}
+
+func setETag(fCtx *fiber.Ctx, version int64) {
+	if version > 0 {
+		fCtx.Set(fiber.HeaderETag, strconv.Quote(strconv.FormatInt(version, 10)))
+	}
+}
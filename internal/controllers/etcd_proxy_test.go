@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEtcdProxy(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for function watchQuery(*fiber.Ctx) nested prefix",
+			positiveWatchQueryNested,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, dto.WatchRequest{
+					Prefix:        "/services/api/",
+					StartRevision: 5,
+					EventTypes:    []string{"PUT"},
+				}, i)
+			},
+		},
+		{
+			"test #1 positive for function watchQuery(*fiber.Ctx) Last-Event-ID",
+			positiveWatchQueryLastEventID,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, dto.WatchRequest{Prefix: "/services/", StartRevision: 8}, i)
+			},
+		},
+		{
+			"test #2 positive for function asOfUnsupported(*fiber.Ctx) revision and at rejected",
+			positiveAsOfUnsupported,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []int{fiber.StatusBadRequest, fiber.StatusBadRequest, fiber.StatusOK}, i)
+			},
+		},
+		{
+			"test #3 positive for function watchQuery(*fiber.Ctx) prefix in path",
+			positiveWatchQueryPath,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, dto.WatchRequest{Prefix: "services/api/", StartRevision: 5}, i)
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveWatchQueryNested(_ *testing.T) (interface{}, error) {
+	return testWatchQuery(httptest.NewRequest(fiber.MethodGet, "/watch?prefix=%2Fservices%2Fapi%2F&rev=5&types=PUT", nil))
+}
+
+func positiveWatchQueryLastEventID(_ *testing.T) (interface{}, error) {
+	request := httptest.NewRequest(fiber.MethodGet, "/watch?prefix=/services/", nil)
+	request.Header.Set("Last-Event-ID", "7")
+	return testWatchQuery(request)
+}
+
+func positiveAsOfUnsupported(_ *testing.T) (interface{}, error) {
+
+	var result []int
+	app := fiber.New()
+	app.Get("/api/get/:name", func(fCtx *fiber.Ctx) error {
+		if err := asOfUnsupported(fCtx); err != nil {
+			return fCtx.SendStatus(fiber.StatusBadRequest)
+		}
+		return fCtx.SendStatus(fiber.StatusOK)
+	})
+	for _, target := range []string{"/api/get/key1?revision=5", "/api/get/key1?at=2026-10-18T00:00:00Z", "/api/get/key1"} {
+		response, err := app.Test(httptest.NewRequest(fiber.MethodGet, target, nil))
+
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, response.StatusCode)
+	}
+	return result, nil
+}
+
+func positiveWatchQueryPath(_ *testing.T) (interface{}, error) {
+	return testWatchQuery(httptest.NewRequest(fiber.MethodGet, "/watch/services/api/?rev=5", nil))
+}
+
+// testWatchQuery параметры подписки, разобранные из запроса по маршрутам
+// /watch и /watch/*.
+func testWatchQuery(request *http.Request) (interface{}, error) {
+
+	var got dto.WatchRequest
+	app := fiber.New()
+	handler := func(fCtx *fiber.Ctx) error {
+		got = watchQuery(fCtx)
+		return fCtx.SendStatus(fiber.StatusOK)
+	}
+	app.Get("/watch", handler)
+	app.Get("/watch/*", handler)
+	if _, err := app.Test(request); err != nil {
+		return nil, err
+	}
+	return got, nil
+}
@@ -0,0 +1,372 @@
+package controllers
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/services"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type KeyValueData interface {
+	Delete(*fiber.Ctx) error
+	Deleted(*fiber.Ctx) error
+	Get(*fiber.Ctx) error
+	History(*fiber.Ctx) error
+	List(*fiber.Ctx) error
+	Put(*fiber.Ctx) error
+	Rollback(*fiber.Ctx) error
+	Txn(*fiber.Ctx) error
+	Undelete(*fiber.Ctx) error
+}
+
+type keyValueData struct {
+	keyValueDataService services.KeyValueDataService
+	sLog                *slog.Logger
+	timeout             time.Duration
+}
+
+var _ KeyValueData = (*keyValueData)(nil)
+var (
+	onceKeyValueData = new(sync.Once)
+	keyValueDataCont *keyValueData
+)
+
+// GetKeyValueDataController — потокобезопасное (thread-safe) создание
+// REST веб-сервиса записи одновременно в etcd и PostgreSQL.
+func GetKeyValueDataController(ctx context.Context, cfg env.Config) KeyValueData {
+
+	onceKeyValueData.Do(func() {
+		keyValueDataCont = new(keyValueData)
+		keyValueDataCont.keyValueDataService = services.GetKeyValueDataService(ctx, cfg)
+		keyValueDataCont.sLog = cfg.Logger()
+		keyValueDataCont.timeout = cfg.EtcdClientConfig().DialTimeout
+	})
+	return keyValueDataCont
+}
+
+func (k *keyValueData) Delete(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := contextWithRequestIdentity(fCtx, k.timeout)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	key := fCtx.Params("key", "default")
+	expectedVersion, err := ifMatchVersion(fCtx)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	if err = k.keyValueDataService.ApiDelete(ctxCancel.ctx, key, expectedVersion); err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusRequestID{Status: "success", RequestID: identity.RequestID})
+}
+
+// Deleted страница записей помеченных удалёнными по префиксу prefix.
+func (k *keyValueData) Deleted(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := contextWithRequestIdentity(fCtx, k.timeout)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	prefix, from, limit, _ := listQuery(fCtx)
+	result, err := k.keyValueDataService.ApiDeleted(ctxCancel.ctx, prefix, from, limit)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultRequestID{
+			Status:    "success",
+			Result:    dto.MakeKeyValueDataList(result),
+			RequestID: identity.RequestID,
+		})
+}
+
+func (k *keyValueData) Get(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := contextWithRequestIdentity(fCtx, k.timeout)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	key := fCtx.Params("key", "default")
+	asOf, err := asOfQuery(fCtx)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	if !asOf.IsZero() {
+		return k.getAsOf(ctxCancel.ctx, fCtx, identity, key, asOf)
+	}
+	result, err := k.keyValueDataService.ApiGet(ctxCancel.ctx, key)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	setETag(fCtx, result.Version())
+
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultRequestID{
+			Status:    "success",
+			Result:    dto.MakeKeyValueData(result),
+			RequestID: identity.RequestID,
+		})
+}
+
+// History ревизии записи начиная с последней, не более limit.
+func (k *keyValueData) History(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := contextWithRequestIdentity(fCtx, k.timeout)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	key := fCtx.Params("key", "default")
+	result, err := k.keyValueDataService.ApiHistory(ctxCancel.ctx, key, int64(fCtx.QueryInt("limit", 0)))
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	revisions := make([]dto.KeyValueData, 0, len(result))
+
+	for _, unit := range result {
+		revisions = append(revisions, dto.MakeKeyValueData(unit))
+	}
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultRequestID{Status: "success", Result: revisions, RequestID: identity.RequestID})
+}
+
+func (k *keyValueData) List(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := contextWithRequestIdentity(fCtx, k.timeout)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	prefix, from, limit, keysOnly := listQuery(fCtx)
+	asOf, err := asOfQuery(fCtx)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	if !asOf.IsZero() {
+		return k.listAsOf(ctxCancel.ctx, fCtx, identity, prefix, from, limit, keysOnly, asOf)
+	}
+	result, err := k.keyValueDataService.ApiList(ctxCancel.ctx, prefix, from, limit, keysOnly)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultRequestID{
+			Status:    "success",
+			Result:    dto.MakeKeyValueDataList(result),
+			RequestID: identity.RequestID,
+		})
+}
+
+func (k *keyValueData) Put(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := contextWithRequestIdentity(fCtx, k.timeout)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	var payload dto.Result
+
+	if err = fCtx.BodyParser(&payload); err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageRequestID{
+				Status:    "fail",
+				Message:   err.Error(),
+				RequestID: identity.RequestID,
+			})
+	}
+	if errors := dto.ValidateStruct(payload); errors != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(errors)
+	}
+	key := fCtx.Params("key", "default")
+	expectedVersion, err := ifMatchVersion(fCtx)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	result, err := k.keyValueDataService.ApiPut(ctxCancel.ctx, services.MakeKeyValueNow(key, payload.Value), expectedVersion)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	setETag(fCtx, result.Version())
+
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultRequestID{
+			Status:    "success",
+			Result:    dto.MakeKeyValueData(result),
+			RequestID: identity.RequestID,
+		})
+}
+
+// Rollback восстановление значения ревизии version как новой версии записи.
+func (k *keyValueData) Rollback(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := contextWithRequestIdentity(fCtx, k.timeout)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	key := fCtx.Params("key", "default")
+	result, err := k.keyValueDataService.ApiRollback(ctxCancel.ctx, key, int64(fCtx.QueryInt("version", 0)))
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	setETag(fCtx, result.Version())
+
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultRequestID{
+			Status:    "success",
+			Result:    dto.MakeKeyValueData(result),
+			RequestID: identity.RequestID,
+		})
+}
+
+// getAsOf значение записи на момент asOf; ETag не выставляется,
+// так как версия прошлой ревизии не годится для условной записи.
+func (k *keyValueData) getAsOf(ctx context.Context, fCtx *fiber.Ctx, identity tIdentity, key string, asOf dto.AsOf) error {
+
+	result, source, err := k.keyValueDataService.ApiGetAsOf(ctx, key, asOf)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultSourceRequestID{
+			Status:    "success",
+			Result:    dto.MakeKeyValueData(result),
+			RequestID: identity.RequestID,
+			Source:    source,
+		})
+}
+
+func (k *keyValueData) listAsOf(
+	ctx context.Context,
+	fCtx *fiber.Ctx,
+	identity tIdentity,
+	prefix, from string,
+	limit int64,
+	keysOnly bool,
+	asOf dto.AsOf,
+) error {
+
+	result, source, err := k.keyValueDataService.ApiListAsOf(ctx, prefix, from, limit, keysOnly, asOf)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultSourceRequestID{
+			Status:    "success",
+			Result:    dto.MakeKeyValueDataList(result),
+			RequestID: identity.RequestID,
+			Source:    source,
+		})
+}
+
+func (k *keyValueData) Txn(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := contextWithRequestIdentity(fCtx, k.timeout)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	var payload dto.TxnRequest
+
+	if err = fCtx.BodyParser(&payload); err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	if errors := dto.ValidateStruct(payload); errors != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(errors)
+	}
+	result, err := k.keyValueDataService.ApiTxn(ctxCancel.ctx, payload)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultRequestID{Status: "success", Result: result, RequestID: identity.RequestID})
+}
+
+// Undelete восстановление записи помеченной удалённой в etcd и PostgreSQL.
+func (k *keyValueData) Undelete(fCtx *fiber.Ctx) error {
+
+	ctxCancel, identity, err := contextWithRequestIdentity(fCtx, k.timeout)
+
+	if err != nil {
+		return fCtx.
+			Status(fiber.StatusBadRequest).
+			JSON(dto.StatusMessageInvalidRequestID(identity.ID))
+	}
+	defer ctxCancel.cancel()
+	key := fCtx.Params("key", "default")
+	result, err := k.keyValueDataService.ApiUndelete(ctxCancel.ctx, key)
+
+	if err != nil {
+		return failResponse(fCtx, err, identity)
+	}
+	setETag(fCtx, result.Version())
+
+	return fCtx.
+		Status(fiber.StatusOK).
+		JSON(dto.StatusResultRequestID{
+			Status:    "success",
+			Result:    dto.MakeKeyValueData(result),
+			RequestID: identity.RequestID,
+		})
+}
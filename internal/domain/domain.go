@@ -10,11 +10,40 @@
 // Package domain TODO.
 package domain
 
+import "fmt"
+
+const (
+	CompareAndDeleteAction = "compare_and_delete"
+	CompareAndSwapAction   = "compare_and_swap"
+	DeleteAction           = "delete"
+	DeletedAction          = "deleted"
+	GetAllAction           = "getall"
+	HistoryAction          = "history"
+	ListAction             = "list"
+	PurgeAction            = "purge"
+	RevisionAction         = "revision"
+	RevisionMarkAction     = "revision_mark"
+	RewriteAction          = "rewrite"
+	RowAction              = "row"
+	SelectAction           = "select"
+	StoredAction           = "stored"
+	TransactionAction      = "transaction"
+	UndeleteAction         = "undelete"
+	UpsertAction           = "upsert"
+)
+
 const (
-	DeleteAction = "delete"
-	GetAllAction = "getall"
-	SelectAction = "select"
-	UpsertAction = "upsert"
+	CompareEqual         = "equal"
+	CompareGreater       = "greater"
+	CompareLess          = "less"
+	CompareNotEqual      = "not_equal"
+	CompareTargetValue   = "value"
+	CompareTargetVersion = "version"
+)
+
+var (
+	ErrNotFound        = fmt.Errorf("not found")
+	ErrVersionMismatch = fmt.Errorf("version mismatch")
 )
 
 // Actioner the first type param will match pointer types and infer U
@@ -30,15 +59,52 @@ type Cloner[T Ptr[U], U Entity] interface {
 	Copy(T) T
 }
 
+// Comparer ожидаемая версия записи для условной (compare-and-swap) операции,
+// версия 0 означает, что запись не должна существовать.
+type Comparer interface {
+	ExpectedVersion() int64
+}
+
 type Entity interface {
 	Key() string
 }
 
+// Leaser аренда etcd, к которой привязывается записываемый ключ,
+// 0 — без аренды.
+type Leaser interface {
+	Lease() int64
+}
+
+// Outboxer действие, которое в одной транзакции PostgreSQL с изменением
+// записей добавляет в outbox события для последующей доставки в etcd.
+type Outboxer interface {
+	OutboxTxArgs(...any) TxArgs
+}
+
+// Pager параметры постраничной выборки по префиксу ключа.
+type Pager interface {
+	From() string
+	KeysOnly() bool
+	Limit() int64
+}
+
 // Ptr constraining a type to its pointer type
 type Ptr[T Entity] interface {
 	*T
 }
 
+// Requirer действие требует наличия подходящей записи: если запрос
+// не вернул строку, возвращается ErrNotFound.
+type Requirer interface {
+	Required() bool
+}
+
+// Revisioner ревизия etcd: для действия чтения — ревизия, на момент
+// которой читаются записи (0 — текущая), для Scanner — ревизия записи.
+type Revisioner interface {
+	Revision() int64
+}
+
 type Scanner interface {
 	Scan(dest ...any) error
 }
@@ -56,6 +122,7 @@ type SQLEntity[T Ptr[U], U Entity] interface {
 }
 
 type TransactionalAction interface {
+	CompareTxArgs(...any) TxArgs
 	DeleteTxArgs(...any) TxArgs
 	Name() string
 	UpsertTxArgs(...any) TxArgs
@@ -66,5 +133,32 @@ type TxArgs struct {
 	SQLs []string
 }
 
+// TxCompare условие транзакции: сравнение версии или значения ключа.
+type TxCompare struct {
+	Key     string
+	Result  string
+	Target  string
+	Value   string
+	Version int64
+}
+
+// TxOp операция транзакции: DeleteAction или UpsertAction над записью.
+type TxOp[U Entity] struct {
+	Name string
+	Unit U
+}
+
+// Txn транзакция: при выполнении всех условий Compare выполняются операции
+// Success, иначе — Failure.
+type Txn[U Entity] struct {
+	Compare []TxCompare
+	Failure []TxOp[U]
+	Success []TxOp[U]
+}
+
+func (t TxArgs) Append(o TxArgs) TxArgs {
+	return TxArgs{Args: append(t.Args, o.Args...), SQLs: append(t.SQLs, o.SQLs...)}
+}
+
 //!-
 /* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
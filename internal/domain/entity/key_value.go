@@ -19,24 +19,43 @@ import (
 )
 
 var (
+	_ domain.Actioner[*KeyValue, KeyValue]  = (*keyValueCompareAndDelete)(nil)
+	_ domain.Actioner[*KeyValue, KeyValue]  = (*keyValueCompareAndSwap)(nil)
 	_ domain.Actioner[*KeyValue, KeyValue]  = (*keyValueDelete)(nil)
 	_ domain.Actioner[*KeyValue, KeyValue]  = (*keyValueGetAll)(nil)
+	_ domain.Actioner[*KeyValue, KeyValue]  = (*keyValueList)(nil)
 	_ domain.Actioner[*KeyValue, KeyValue]  = (*keyValueSelect)(nil)
 	_ domain.Actioner[*KeyValue, KeyValue]  = (*keyValueUpsert)(nil)
 	_ domain.Cloner[*KeyValue, KeyValue]    = (*keyValueCloner)(nil)
+	_ domain.Comparer                       = (*keyValueCompareAndDelete)(nil)
+	_ domain.Comparer                       = (*keyValueCompareAndSwap)(nil)
 	_ domain.Entity                         = (*KeyValue)(nil)
+	_ domain.Leaser                         = (*keyValueCompareAndSwap)(nil)
+	_ domain.Pager                          = (*keyValueList)(nil)
 	_ domain.Serializable                   = (*KeyValue)(nil)
 	_ domain.SQLEntity[*KeyValue, KeyValue] = (*KeyValue)(nil)
+	_ domain.TransactionalAction            = (*keyValueTransaction)(nil)
 	_ fmt.Stringer                          = (*KeyValue)(nil)
 )
 
 var (
-	ErrKeyValueNil = fmt.Errorf("bad pointer, KeyValue is nil")
-	KeyValueCloner keyValueCloner
-	KeyValueDelete keyValueDelete
-	KeyValueGetAll keyValueGetAll
-	KeyValueSelect keyValueSelect
-	KeyValueUpsert keyValueUpsert
+	ErrKeyValueNil           = fmt.Errorf("bad pointer, KeyValue is nil")
+	KeyValueCloner           keyValueCloner
+	KeyValueCompareAndDelete keyValueCompareAndDelete
+	KeyValueCompareAndSwap   keyValueCompareAndSwap
+	KeyValueDelete           keyValueDelete
+	KeyValueGetAll           keyValueGetAll
+	KeyValueList             keyValueList
+	KeyValueSelect           keyValueSelect
+	KeyValueTransaction      keyValueTransaction
+	KeyValueUpsert           keyValueUpsert
+	likeReplacer             = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	compareOperators         = map[string]string{
+		domain.CompareEqual:    "=",
+		domain.CompareGreater:  ">",
+		domain.CompareLess:     "<",
+		domain.CompareNotEqual: "<>",
+	}
 )
 
 type KeyValue struct {
@@ -71,6 +90,40 @@ func GetAllKeyValue(
 	return result, err
 }
 
+// ListKeyValue постраничная выборка записей, ключи которых начинаются с prefix,
+// упорядоченная по ключу начиная с from включительно.
+func ListKeyValue(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	prefix, from string,
+	limit int64,
+	keysOnly bool,
+) ([]KeyValue, error) {
+
+	var err error
+
+	result, er0 := repo.Get(ctx, MakeKeyValueList(from, limit, keysOnly), KeyValue{key: prefix}, func(s domain.Scanner) KeyValue {
+		var r KeyValue
+		if er1 := s.Scan(&r.key, &r.value, &r.version, &r.deleted, &r.createdAt, &r.updatedAt); er1 != nil {
+			err = er1
+		}
+		return r
+	})
+	if er0 != nil {
+		return result, er0
+	}
+	return result, err
+}
+
+// TransactionKeyValue атомарное выполнение транзакции txn над записями.
+func TransactionKeyValue(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	txn domain.Txn[KeyValue],
+) (bool, error) {
+	return repo.Transaction(ctx, KeyValueTransaction, txn)
+}
+
 func MakeKeyValue(key, value string, version int64, t TAttributes) KeyValue {
 	return KeyValue{
 		TAttributes: MakeTAttributes(t.deleted, t.createdAt, t.updatedAt),
@@ -102,10 +155,16 @@ func GetKeyValue(
 
 func (f *KeyValue) Action(name string) domain.Actioner[*KeyValue, KeyValue] {
 	switch strings.ToLower(name) {
+	case domain.CompareAndDeleteAction:
+		return KeyValueCompareAndDelete
+	case domain.CompareAndSwapAction:
+		return KeyValueCompareAndSwap
 	case domain.DeleteAction:
 		return KeyValueDelete
 	case domain.GetAllAction:
 		return KeyValueGetAll
+	case domain.ListAction:
+		return KeyValueList
 	case domain.SelectAction:
 		return KeyValueSelect
 	case domain.UpsertAction:
@@ -144,6 +203,12 @@ func (f *KeyValue) Value() string {
 	return f.value
 }
 
+// WithValue копия записи со значением value.
+func (f KeyValue) WithValue(value string) KeyValue {
+	f.value = value
+	return f
+}
+
 func (f *KeyValue) Version() int64 {
 
 	if f == nil {
@@ -164,6 +229,32 @@ func (f *KeyValue) String() string {
 		f.key, f.value, f.version.Int64, f.TAttributes.String())
 }
 
+// CompareAndDelete удаление записи при условии, что её текущая версия равна expected.
+func (f *KeyValue) CompareAndDelete(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	expected int64,
+) error {
+
+	if f == nil {
+		return ErrKeyValueNil
+	}
+	return f.do(ctx, MakeKeyValueCompareAndDelete(expected), repo)
+}
+
+// CompareAndSwap запись значения при условии, что текущая версия записи равна expected.
+func (f *KeyValue) CompareAndSwap(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	expected int64,
+) error {
+
+	if f == nil {
+		return ErrKeyValueNil
+	}
+	return f.do(ctx, MakeKeyValueCompareAndSwap(expected), repo)
+}
+
 func (f *KeyValue) Delete(
 	ctx context.Context,
 	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
@@ -241,6 +332,88 @@ func (k *keyValueCloner) Copy(t *KeyValue) *KeyValue {
 	return NewKeyValue(t.key, t.value, FromNullInt64ToVersion(t.version), t.TAttributes)
 }
 
+type keyValueCompareAndDelete struct {
+	expected int64
+}
+
+func MakeKeyValueCompareAndDelete(expected int64) keyValueCompareAndDelete {
+	return keyValueCompareAndDelete{expected: expected}
+}
+
+func (k keyValueCompareAndDelete) Args(e KeyValue) []any {
+	return []any{e.key, e.updatedAt, k.expected}
+}
+
+func (k keyValueCompareAndDelete) ExpectedVersion() int64 {
+	return k.expected
+}
+
+func (k keyValueCompareAndDelete) Name() string {
+	return domain.CompareAndDeleteAction
+}
+
+func (k keyValueCompareAndDelete) SQL() string {
+	return `UPDATE key_value
+	SET deleted = true, updated_at = $2
+	WHERE key = $1 AND version = $3 AND NOT deleted
+	RETURNING key, value, version, deleted, created_at, updated_at`
+}
+
+type keyValueCompareAndSwap struct {
+	expected int64
+	lease    int64
+}
+
+func MakeKeyValueCompareAndSwap(expected int64) keyValueCompareAndSwap {
+	return keyValueCompareAndSwap{expected: expected}
+}
+
+func (k keyValueCompareAndSwap) Args(e KeyValue) []any {
+
+	if k.expected == 0 {
+		return []any{e.key, e.value, e.deleted, e.createdAt, e.updatedAt}
+	}
+	return []any{e.key, e.value, e.deleted, e.updatedAt, k.expected}
+}
+
+func (k keyValueCompareAndSwap) ExpectedVersion() int64 {
+	return k.expected
+}
+
+// Lease аренда учитывается только при записи в etcd.
+func (k keyValueCompareAndSwap) Lease() int64 {
+	return k.lease
+}
+
+func (k keyValueCompareAndSwap) Name() string {
+	return domain.CompareAndSwapAction
+}
+
+// SQL при expected = 0 запись создаётся, только если ключ отсутствует или
+// помечен удалённым, иначе обновляется только запись с версией expected.
+func (k keyValueCompareAndSwap) SQL() string {
+
+	if k.expected == 0 {
+		return `INSERT INTO key_value
+	(key, value, version, deleted, created_at)
+	VALUES ($1, $2, 1, $3, $4)
+	ON CONFLICT (key)
+	DO UPDATE SET value = $2, deleted = $3, updated_at = $5, version = 1
+	WHERE key_value.deleted
+	RETURNING key, value, version, deleted, created_at, updated_at`
+	}
+	return `UPDATE key_value
+	SET value = $2, deleted = $3, updated_at = $4, version = version + 1
+	WHERE key = $1 AND version = $5 AND NOT deleted
+	RETURNING key, value, version, deleted, created_at, updated_at`
+}
+
+// WithLease условная запись с привязкой ключа к аренде etcd.
+func (k keyValueCompareAndSwap) WithLease(lease int64) keyValueCompareAndSwap {
+	k.lease = lease
+	return k
+}
+
 type keyValueDelete struct{}
 
 func (k keyValueDelete) Args(e KeyValue) []any {
@@ -255,7 +428,7 @@ func (k keyValueDelete) SQL() string {
 	return `UPDATE key_value
 	SET deleted = true, updated_at = $2
 	WHERE key = $1
-	RETURNING key, value, deleted, created_at, updated_at`
+	RETURNING key, value, version, deleted, created_at, updated_at`
 }
 
 type keyValueGetAll struct{}
@@ -269,7 +442,53 @@ func (k keyValueGetAll) Name() string {
 }
 
 func (k keyValueGetAll) SQL() string {
-	return `SELECT key, value, deleted, created_at, updated_at FROM key_value`
+	return `SELECT key, value, version, deleted, created_at, updated_at FROM key_value`
+}
+
+type keyValueList struct {
+	from     string
+	keysOnly bool
+	limit    int64
+}
+
+// MakeKeyValueList выборка не более limit записей (limit < 1 без ограничения)
+// начиная с ключа from включительно.
+func MakeKeyValueList(from string, limit int64, keysOnly bool) keyValueList {
+	return keyValueList{from: from, keysOnly: keysOnly, limit: limit}
+}
+
+func (k keyValueList) Args(e KeyValue) []any {
+
+	var limit any
+
+	if k.limit > 0 {
+		limit = k.limit
+	}
+	return []any{escapeLike(e.key), k.from, limit}
+}
+
+func (k keyValueList) From() string {
+	return k.from
+}
+
+func (k keyValueList) KeysOnly() bool {
+	return k.keysOnly
+}
+
+func (k keyValueList) Limit() int64 {
+	return k.limit
+}
+
+func (k keyValueList) Name() string {
+	return domain.ListAction
+}
+
+func (k keyValueList) SQL() string {
+	return `SELECT key, value, version, deleted, created_at, updated_at
+	FROM key_value
+	WHERE key LIKE $1 || '%' AND key >= $2 AND NOT deleted
+	ORDER BY key
+	LIMIT $3`
 }
 
 type keyValueSelect struct{}
@@ -282,10 +501,56 @@ func (k keyValueSelect) Name() string {
 	return domain.SelectAction
 }
 
+// SQL запись помеченная удалённой (tombstone) не выбирается,
+// как и ключ удалённый из etcd.
 func (k keyValueSelect) SQL() string {
-	return `SELECT key, value, deleted, created_at, updated_at
+	return `SELECT key, value, version, deleted, created_at, updated_at
 	FROM key_value
-	WHERE key = $1`
+	WHERE key = $1 AND NOT deleted`
+}
+
+type keyValueTransaction struct{}
+
+// CompareTxArgs условия domain.TxCompare в виде запросов, возвращающих bool;
+// удалённая запись сравнивается как отсутствующая.
+func (k keyValueTransaction) CompareTxArgs(compares ...any) domain.TxArgs {
+
+	var result domain.TxArgs
+
+	for _, c := range compares {
+		compare, ok := c.(domain.TxCompare)
+
+		if !ok {
+			continue
+		}
+		operator, ok := compareOperators[compare.Result]
+
+		if !ok {
+			operator = compareOperators[domain.CompareEqual]
+		}
+		if compare.Target == domain.CompareTargetValue {
+			result.SQLs = append(result.SQLs, fmt.Sprintf(`SELECT COALESCE(
+	(SELECT value FROM key_value WHERE key = $1 AND NOT deleted FOR UPDATE), '') %s $2`, operator))
+			result.Args = append(result.Args, []any{compare.Key, compare.Value})
+		} else {
+			result.SQLs = append(result.SQLs, fmt.Sprintf(`SELECT COALESCE(
+	(SELECT version FROM key_value WHERE key = $1 AND NOT deleted FOR UPDATE), 0) %s $2`, operator))
+			result.Args = append(result.Args, []any{compare.Key, compare.Version})
+		}
+	}
+	return result
+}
+
+func (k keyValueTransaction) DeleteTxArgs(units ...any) domain.TxArgs {
+	return txArgs(KeyValueDelete, units...)
+}
+
+func (k keyValueTransaction) Name() string {
+	return domain.TransactionAction
+}
+
+func (k keyValueTransaction) UpsertTxArgs(units ...any) domain.TxArgs {
+	return txArgs(KeyValueUpsert, units...)
 }
 
 type keyValueUpsert struct{}
@@ -300,11 +565,29 @@ func (k keyValueUpsert) Name() string {
 
 func (k keyValueUpsert) SQL() string {
 	return `INSERT INTO key_value
-	(key, value, deleted, created_at)
-	VALUES ($1, $2, $3, $4)
+	(key, value, version, deleted, created_at)
+	VALUES ($1, $2, 1, $3, $4)
 	ON CONFLICT (key)
-	DO UPDATE SET value = $2, deleted = $3, updated_at = $5
-	RETURNING key, value, deleted, created_at, updated_at`
+	DO UPDATE SET value = $2, deleted = $3, updated_at = $5,
+	version = CASE WHEN key_value.deleted THEN 1 ELSE key_value.version + 1 END
+	RETURNING key, value, version, deleted, created_at, updated_at`
+}
+
+func txArgs(action domain.Actioner[*KeyValue, KeyValue], units ...any) domain.TxArgs {
+
+	var result domain.TxArgs
+
+	for _, u := range units {
+		if unit, ok := u.(KeyValue); ok {
+			result.Args = append(result.Args, action.Args(unit))
+			result.SQLs = append(result.SQLs, action.SQL())
+		}
+	}
+	return result
+}
+
+func escapeLike(s string) string {
+	return likeReplacer.Replace(s)
 }
 
 //!-
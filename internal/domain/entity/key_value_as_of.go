@@ -0,0 +1,168 @@
+/*
+ * This file was last modified at 2026-10-18 20:30 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * key_value_as_of.go
+ * $Id$
+ */
+//!+
+
+package entity
+
+import (
+	"context"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"time"
+)
+
+var (
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueListAsOf)(nil)
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueRevisionMark)(nil)
+	_ domain.Pager                         = (*keyValueListAsOf)(nil)
+	_ domain.Revisioner                    = (*keyValueListAsOf)(nil)
+)
+
+// ListKeyValueAsOf постраничная выборка записей по префиксу на момент
+// ревизии etcd revision или, если at задано, на момент времени at.
+// В etcd читается ревизия revision, в PostgreSQL — key_value_history.
+func ListKeyValueAsOf(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	prefix, from string,
+	limit int64,
+	keysOnly bool,
+	revision int64,
+	at time.Time,
+) ([]KeyValue, error) {
+
+	var err error
+
+	action := MakeKeyValueListAsOf(from, limit, keysOnly, revision, at)
+	result, er0 := repo.Get(ctx, action, KeyValue{key: prefix}, func(s domain.Scanner) KeyValue {
+		var r KeyValue
+		if er1 := s.Scan(&r.key, &r.value, &r.version, &r.deleted, &r.createdAt, &r.updatedAt); er1 != nil {
+			err = er1
+		}
+		return r
+	})
+	if er0 != nil {
+		return result, er0
+	}
+	return result, err
+}
+
+// MarkKeyValueRevision сопоставление ревизии etcd revision ревизии записи
+// unit в key_value_history: строке с той же версией и признаком удаления,
+// которую записала запись unit и которой ревизия ещё не назначена.
+func MarkKeyValueRevision(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	unit KeyValue,
+	revision int64,
+) (bool, error) {
+
+	var err error
+
+	result, er0 := repo.Get(ctx, MakeKeyValueRevisionMark(revision), unit, func(s domain.Scanner) KeyValue {
+		var r KeyValue
+		if er1 := s.Scan(&r.key, &r.value, &r.version, &r.deleted, &r.createdAt, &r.updatedAt); er1 != nil {
+			err = er1
+		}
+		return r
+	})
+	if er0 != nil {
+		return false, er0
+	}
+	return len(result) > 0, err
+}
+
+type keyValueListAsOf struct {
+	keyValueList
+	at       time.Time
+	revision int64
+}
+
+// MakeKeyValueListAsOf выборка по префиксу на момент ревизии revision,
+// при ненулевом at — на момент времени at.
+func MakeKeyValueListAsOf(from string, limit int64, keysOnly bool, revision int64, at time.Time) keyValueListAsOf {
+	return keyValueListAsOf{
+		keyValueList: MakeKeyValueList(from, limit, keysOnly),
+		at:           at,
+		revision:     revision,
+	}
+}
+
+func (k keyValueListAsOf) Args(e KeyValue) []any {
+
+	var point any = k.revision
+
+	if !k.at.IsZero() {
+		point = k.at
+	}
+	return append(k.keyValueList.Args(e), point)
+}
+
+// Revision при чтении на момент времени ревизия etcd не используется.
+func (k keyValueListAsOf) Revision() int64 {
+
+	if !k.at.IsZero() {
+		return 0
+	}
+	return k.revision
+}
+
+// SQL последняя ревизия каждого ключа не позже указанного момента,
+// ключи удалённые к этому моменту пропускаются. Строке истории без
+// ревизии etcd (запись не попала в etcd или ревизия не сопоставлена)
+// порядок задаёт changed_at: она входит в момент revision, если записана
+// не позже последней строки с ревизией не больше revision.
+func (k keyValueListAsOf) SQL() string {
+
+	point := `(revision <= $4 OR revision IS NULL AND changed_at <= (
+			SELECT max(changed_at) FROM key_value_history WHERE revision <= $4
+		))`
+
+	if !k.at.IsZero() {
+		point = "changed_at <= $4"
+	}
+	return `SELECT key, value, version, deleted, created_at, changed_at
+	FROM (
+		SELECT DISTINCT ON (key) key, value, version, deleted, created_at, changed_at
+		FROM key_value_history
+		WHERE key LIKE $1 || '%' AND key >= $2 AND ` + point + `
+		ORDER BY key, id DESC
+	) AS h
+	WHERE NOT deleted
+	ORDER BY key
+	LIMIT $3`
+}
+
+type keyValueRevisionMark struct {
+	revision int64
+}
+
+func MakeKeyValueRevisionMark(revision int64) keyValueRevisionMark {
+	return keyValueRevisionMark{revision: revision}
+}
+
+func (k keyValueRevisionMark) Args(e KeyValue) []any {
+	return []any{e.key, k.revision, e.version, e.Deleted()}
+}
+
+func (k keyValueRevisionMark) Name() string {
+	return domain.RevisionMarkAction
+}
+
+func (k keyValueRevisionMark) SQL() string {
+	return `UPDATE key_value_history SET revision = $2
+	WHERE id = (
+		SELECT id FROM key_value_history
+		WHERE key = $1 AND version = $3 AND deleted = $4 AND revision IS NULL
+		ORDER BY id DESC
+		LIMIT 1
+	)
+	RETURNING key, value, version, deleted, created_at, changed_at`
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,74 @@
+/*
+ * This file was last modified at 2026-10-18 20:30 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * key_value_as_of_test.go
+ * $Id$
+ */
+//!+
+
+package entity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestKeyValueAsOf(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for function MakeKeyValueListAsOf(string, int64, bool, int64, time.Time) by revision",
+			func(_ *testing.T) (interface{}, error) {
+				return MakeKeyValueListAsOf("key1", 10, false, 5, time.Time{}), nil
+			},
+			func(t *testing.T, i interface{}) bool {
+				action := i.(keyValueListAsOf)
+				return assert.Equal(t, int64(5), action.Revision()) &&
+					assert.Equal(t, domain.ListAction, action.Name()) &&
+					assert.Equal(t, []any{"key", "key1", int64(10), int64(5)}, action.Args(KeyValue{key: "key"})) &&
+					assert.Contains(t, action.SQL(), "revision <= $4") &&
+					assert.Contains(t, action.SQL(), "revision IS NULL AND changed_at")
+			},
+		},
+		{
+			"test #1 positive for function MakeKeyValueListAsOf(string, int64, bool, int64, time.Time) by time",
+			func(_ *testing.T) (interface{}, error) {
+				return MakeKeyValueListAsOf("", 0, true, 0, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)), nil
+			},
+			func(t *testing.T, i interface{}) bool {
+				action := i.(keyValueListAsOf)
+				return assert.Equal(t, int64(0), action.Revision()) &&
+					assert.True(t, action.KeysOnly()) &&
+					assert.Equal(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), action.Args(KeyValue{key: "key"})[3]) &&
+					assert.Contains(t, action.SQL(), "changed_at <= $4")
+			},
+		},
+		{
+			"test #2 positive for function MakeKeyValueRevisionMark(int64)",
+			func(_ *testing.T) (interface{}, error) { return MakeKeyValueRevisionMark(5), nil },
+			func(t *testing.T, i interface{}) bool {
+				action := i.(keyValueRevisionMark)
+				unit := MakeKeyValue("key1", "value1", 3, DefaultTAttributes())
+				return assert.Equal(t, []any{"key1", int64(5), unit.version, false}, action.Args(unit)) &&
+					assert.Equal(t, domain.RevisionMarkAction, action.Name()) &&
+					assert.Contains(t, action.SQL(), "version = $3 AND deleted = $4")
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,129 @@
+/*
+ * This file was last modified at 2026-10-18 19:50 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * key_value_history.go
+ * $Id$
+ */
+//!+
+
+package entity
+
+import (
+	"context"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+)
+
+var (
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueHistory)(nil)
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueRevision)(nil)
+)
+
+// HistoryKeyValue не более limit ревизий записи key, начиная с последней.
+// Время изменения ревизии возвращается как UpdatedAt.
+func HistoryKeyValue(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	key string,
+	limit int64,
+) ([]KeyValue, error) {
+
+	var err error
+
+	result, er0 := repo.Get(ctx, MakeKeyValueHistory(limit), KeyValue{key: key}, func(s domain.Scanner) KeyValue {
+		var r KeyValue
+		if er1 := s.Scan(&r.key, &r.value, &r.version, &r.deleted, &r.createdAt, &r.updatedAt); er1 != nil {
+			err = er1
+		}
+		return r
+	})
+	if er0 != nil {
+		return result, er0
+	}
+	return result, err
+}
+
+// RevisionKeyValue последняя неудалённая ревизия записи key с версией version,
+// found = false — такой ревизии нет.
+func RevisionKeyValue(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	key string,
+	version int64,
+) (unit KeyValue, found bool, err error) {
+
+	result, er0 := repo.Get(ctx, MakeKeyValueRevision(version), KeyValue{key: key}, func(s domain.Scanner) KeyValue {
+		var r KeyValue
+		if er1 := s.Scan(&r.key, &r.value, &r.version, &r.deleted, &r.createdAt, &r.updatedAt); er1 != nil {
+			err = er1
+		}
+		return r
+	})
+	if er0 != nil {
+		return unit, false, er0
+	}
+	if err != nil || len(result) == 0 {
+		return unit, false, err
+	}
+	return result[0], true, nil
+}
+
+type keyValueHistory struct {
+	limit int64
+}
+
+// MakeKeyValueHistory выборка не более limit ревизий (limit < 1 без ограничения).
+func MakeKeyValueHistory(limit int64) keyValueHistory {
+	return keyValueHistory{limit: limit}
+}
+
+func (k keyValueHistory) Args(e KeyValue) []any {
+
+	var limit any
+
+	if k.limit > 0 {
+		limit = k.limit
+	}
+	return []any{e.key, limit}
+}
+
+func (k keyValueHistory) Name() string {
+	return domain.HistoryAction
+}
+
+func (k keyValueHistory) SQL() string {
+	return `SELECT key, value, version, deleted, created_at, changed_at
+	FROM key_value_history
+	WHERE key = $1
+	ORDER BY id DESC
+	LIMIT $2`
+}
+
+type keyValueRevision struct {
+	version int64
+}
+
+func MakeKeyValueRevision(version int64) keyValueRevision {
+	return keyValueRevision{version: version}
+}
+
+func (k keyValueRevision) Args(e KeyValue) []any {
+	return []any{e.key, k.version}
+}
+
+func (k keyValueRevision) Name() string {
+	return domain.RevisionAction
+}
+
+// SQL после удаления и повторного создания записи версии начинаются
+// с 1, поэтому выбирается последняя ревизия с указанной версией.
+func (k keyValueRevision) SQL() string {
+	return `SELECT key, value, version, deleted, created_at, changed_at
+	FROM key_value_history
+	WHERE key = $1 AND version = $2 AND NOT deleted
+	ORDER BY id DESC
+	LIMIT 1`
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
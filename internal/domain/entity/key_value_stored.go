@@ -0,0 +1,142 @@
+/*
+ * This file was last modified at 2026-10-18 23:55 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * key_value_stored.go
+ * $Id$
+ */
+//!+
+
+package entity
+
+import (
+	"context"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+)
+
+var (
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueRewrite)(nil)
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueStored)(nil)
+)
+
+// StoredKeyValue постраничная выборка различных пар (key, value) из истории
+// и удалённых записей с ключами, начинающимися с prefix, после пары
+// (fromKey, fromValue). Значения возвращаются в том виде, в каком хранятся.
+func StoredKeyValue(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	prefix, fromKey, fromValue string,
+	limit int64,
+) ([]KeyValue, error) {
+
+	var err error
+
+	result, er0 := repo.Get(ctx, MakeKeyValueStored(fromKey, fromValue, limit), KeyValue{key: prefix}, func(s domain.Scanner) KeyValue {
+		var r KeyValue
+		if er1 := s.Scan(&r.key, &r.value); er1 != nil {
+			err = er1
+		}
+		return r
+	})
+	if er0 != nil {
+		return result, er0
+	}
+	return result, err
+}
+
+// RewriteKeyValue замена хранимого значения stored записи unit.Key() на
+// unit.Value() в записи, в том числе удалённой, и в истории, версии и
+// время изменения не меняются. Возвращает изменённые строки.
+func RewriteKeyValue(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	unit KeyValue,
+	stored string,
+) ([]KeyValue, error) {
+
+	var err error
+
+	result, er0 := repo.Get(ctx, MakeKeyValueRewrite(stored), unit, func(s domain.Scanner) KeyValue {
+		var r KeyValue
+		if er1 := s.Scan(&r.key, &r.value, &r.version, &r.deleted, &r.createdAt, &r.updatedAt); er1 != nil {
+			err = er1
+		}
+		return r
+	})
+	if er0 != nil {
+		return result, er0
+	}
+	return result, err
+}
+
+type keyValueRewrite struct {
+	stored string
+}
+
+func MakeKeyValueRewrite(stored string) keyValueRewrite {
+	return keyValueRewrite{stored: stored}
+}
+
+func (k keyValueRewrite) Args(e KeyValue) []any {
+	return []any{e.key, k.stored, e.value}
+}
+
+func (k keyValueRewrite) Name() string {
+	return domain.RewriteAction
+}
+
+// SQL запись меняется только вместе со значением, поэтому триггер истории
+// (key_value_history_update_trigger) новую ревизию не добавляет, а версия,
+// известная клиентам для условной записи, остаётся прежней. Запись,
+// изменённая параллельно, по значению $2 не находится.
+func (k keyValueRewrite) SQL() string {
+	return `WITH current AS (
+		UPDATE key_value SET value = $3
+		WHERE key = $1 AND value = $2
+		RETURNING key, value, version, deleted, created_at, updated_at
+	), history AS (
+		UPDATE key_value_history SET value = $3
+		WHERE key = $1 AND value = $2
+		RETURNING key, value, version, deleted, created_at, changed_at
+	)
+	SELECT * FROM current UNION ALL SELECT * FROM history`
+}
+
+type keyValueStored struct {
+	fromKey   string
+	fromValue string
+	limit     int64
+}
+
+// MakeKeyValueStored выборка не более limit пар (key, value) после
+// пары (fromKey, fromValue), limit < 1 без ограничения.
+func MakeKeyValueStored(fromKey, fromValue string, limit int64) keyValueStored {
+	return keyValueStored{fromKey: fromKey, fromValue: fromValue, limit: limit}
+}
+
+func (k keyValueStored) Args(e KeyValue) []any {
+
+	var limit any
+
+	if k.limit > 0 {
+		limit = k.limit
+	}
+	return []any{e.key, k.fromKey, k.fromValue, limit}
+}
+
+func (k keyValueStored) Name() string {
+	return domain.StoredAction
+}
+
+func (k keyValueStored) SQL() string {
+	return `SELECT key, value FROM key_value_history
+	WHERE key LIKE $1 || '%' AND (key, value) > ($2, $3)
+	UNION
+	SELECT key, value FROM key_value
+	WHERE key LIKE $1 || '%' AND (key, value) > ($2, $3) AND deleted
+	ORDER BY key, value
+	LIMIT $4`
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
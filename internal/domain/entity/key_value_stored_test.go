@@ -0,0 +1,53 @@
+/*
+ * This file was last modified at 2026-10-18 23:55 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * key_value_stored_test.go
+ * $Id$
+ */
+//!+
+
+package entity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestKeyValueStored(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for function MakeKeyValueStored(string, string, int64)",
+			func(_ *testing.T) (interface{}, error) { return MakeKeyValueStored("/secrets/a", "enc", 100), nil },
+			func(t *testing.T, i interface{}) bool {
+				action := i.(keyValueStored)
+				return assert.Equal(t, []any{"/secrets/", "/secrets/a", "enc", int64(100)}, action.Args(KeyValue{key: "/secrets/"})) &&
+					assert.Contains(t, action.SQL(), "key_value_history")
+			},
+		},
+		{
+			"test #1 positive for function MakeKeyValueRewrite(string)",
+			func(_ *testing.T) (interface{}, error) { return MakeKeyValueRewrite("old"), nil },
+			func(t *testing.T, i interface{}) bool {
+				action := i.(keyValueRewrite)
+				return assert.Equal(t, []any{"/secrets/a", "old", "new"}, action.Args(KeyValue{key: "/secrets/a", value: "new"})) &&
+					assert.Contains(t, action.SQL(), "UPDATE key_value SET") &&
+					assert.NotContains(t, action.SQL(), "AND deleted")
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -17,6 +17,7 @@ import (
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
 	"golang.org/x/net/context"
 	"testing"
+	"time"
 )
 
 func TestKeyValue(t *testing.T) {
@@ -166,6 +167,16 @@ func TestKeyValue(t *testing.T) {
 			negativeScannerErrGetAllKeyValue,
 			negativeScannerErrGetAllKeyValueCheck,
 		},
+		{
+			"test #29 positive for struct KeyValue methods CreatedAt(), Deleted() and UpdatedAt()",
+			positiveKeyValueTAttributes,
+			positiveKeyValueTAttributesCheck,
+		},
+		{
+			"test #30 negative for struct KeyValue methods CreatedAt(), Deleted() and UpdatedAt()",
+			negativeKeyValueTAttributes,
+			negativeKeyValueTAttributesCheck,
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			got, err := test.fRun(t)
@@ -274,7 +285,7 @@ func positiveKeyValueJSON2Check(_ *testing.T, i interface{}) bool {
 }
 
 func positiveKeyValueJSON3(t *testing.T) (interface{}, error) {
-	expected := MakeKeyValue("key1", "", 0, TAttributes{deleted: sql.NullBool{true, true}})
+	expected := MakeKeyValue("key1", "", 0, TAttributes{deleted: sql.NullBool{Bool: true, Valid: true}})
 	j, err := expected.ToJSON()
 	assert.Nil(t, err)
 	assert.NotNil(t, j)
@@ -339,6 +350,33 @@ func positiveKeyValueValueCheck(_ *testing.T, i interface{}) bool {
 	return i == "value1"
 }
 
+func positiveKeyValueTAttributes(_ *testing.T) (interface{}, error) {
+	now := time.Now()
+	kv := MakeKeyValue("key1", "value1", 1, MakeTAttributes(
+		sql.NullBool{Bool: true, Valid: true}, now, sql.NullTime{Time: now, Valid: true},
+	))
+	return kv, nil
+}
+
+func positiveKeyValueTAttributesCheck(_ *testing.T, i interface{}) bool {
+	if kv, ok := i.(KeyValue); ok {
+		return kv.Deleted() && !kv.CreatedAt().IsZero() && kv.UpdatedAt().Valid
+	}
+	return false
+}
+
+func negativeKeyValueTAttributes(_ *testing.T) (interface{}, error) {
+	var t *TAttributes
+	return []any{t.Deleted(), t.CreatedAt(), t.UpdatedAt()}, nil
+}
+
+func negativeKeyValueTAttributesCheck(_ *testing.T, i interface{}) bool {
+	if a, ok := i.([]any); ok && len(a) == 3 {
+		return a[0] == false && a[1] == time.Time{} && a[2] == sql.NullTime{}
+	}
+	return false
+}
+
 func negativeKeyValueValue(_ *testing.T) (interface{}, error) {
 	var kv *KeyValue
 	return kv.Value(), nil
@@ -538,6 +576,41 @@ func TestKeyValueDeleteAction(t *testing.T) {
 			positiveKeyValueUpsertAction,
 			positiveKeyValueUpsertActionCheck,
 		},
+		{
+			"test #4 positive for struct KeyValueList",
+			positiveKeyValueListAction,
+			positiveKeyValueListActionCheck,
+		},
+		{
+			"test #5 positive for struct KeyValueList method Args escapes LIKE pattern",
+			positiveKeyValueListArgs,
+			positiveKeyValueListArgsCheck,
+		},
+		{
+			"test #6 positive for struct KeyValueCompareAndDelete",
+			positiveKeyValueCompareAndDeleteAction,
+			positiveKeyValueCompareAndDeleteActionCheck,
+		},
+		{
+			"test #7 positive #1 for struct KeyValueCompareAndSwap create only",
+			positiveKeyValueCompareAndSwapAction1,
+			positiveKeyValueCompareAndSwapActionCheck,
+		},
+		{
+			"test #8 positive #2 for struct KeyValueCompareAndSwap with version",
+			positiveKeyValueCompareAndSwapAction2,
+			positiveKeyValueCompareAndSwapActionCheck,
+		},
+		{
+			"test #9 positive for struct KeyValueTransaction",
+			positiveKeyValueTransactionTxArgs,
+			positiveKeyValueTransactionTxArgsCheck,
+		},
+		{
+			"test #10 positive for function TransactionKeyValue(context.Context, domain.Repo, domain.Txn)",
+			positiveTransactionKeyValue,
+			positiveTransactionKeyValueCheck,
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			got, err := test.fRun(t)
@@ -580,5 +653,88 @@ func positiveKeyValueUpsertActionCheck(t *testing.T, i interface{}) bool {
 	return checkTrue(i)
 }
 
+func positiveKeyValueListAction(t *testing.T) (interface{}, error) {
+	return actionCheckSQLArgs[*KeyValue, KeyValue](t, MakeKeyValueList("", 10, false), KeyValue{}), nil
+}
+
+func positiveKeyValueListActionCheck(t *testing.T, i interface{}) bool {
+	return checkTrue(i)
+}
+
+func positiveKeyValueListArgs(t *testing.T) (interface{}, error) {
+	return MakeKeyValueList("a_%b", 0, true).Args(KeyValue{key: `a_%\`}), nil
+}
+
+func positiveKeyValueListArgsCheck(t *testing.T, i interface{}) bool {
+	return assert.Equal(t, []any{`a\_\%\\`, "a_%b", nil}, i)
+}
+
+func positiveKeyValueCompareAndDeleteAction(t *testing.T) (interface{}, error) {
+	return actionCheckSQLArgs[*KeyValue, KeyValue](t, MakeKeyValueCompareAndDelete(3), KeyValue{}), nil
+}
+
+func positiveKeyValueCompareAndDeleteActionCheck(t *testing.T, i interface{}) bool {
+	return checkTrue(i)
+}
+
+func positiveKeyValueCompareAndSwapAction1(t *testing.T) (interface{}, error) {
+	return actionCheckSQLArgs[*KeyValue, KeyValue](t, MakeKeyValueCompareAndSwap(0), KeyValue{}), nil
+}
+
+func positiveKeyValueCompareAndSwapAction2(t *testing.T) (interface{}, error) {
+	action := MakeKeyValueCompareAndSwap(3)
+	assert.Equal(t, int64(3), action.Args(KeyValue{})[4])
+	assert.Equal(t, int64(7), action.WithLease(7).Lease())
+	assert.Equal(t, int64(3), action.WithLease(7).ExpectedVersion())
+	return actionCheckSQLArgs[*KeyValue, KeyValue](t, action, KeyValue{}), nil
+}
+
+func positiveKeyValueCompareAndSwapActionCheck(t *testing.T, i interface{}) bool {
+	return checkTrue(i)
+}
+
+func positiveKeyValueTransactionTxArgs(t *testing.T) (interface{}, error) {
+
+	unit := MakeKeyValue("key1", "value1", 1, DefaultTAttributes())
+	compare := KeyValueTransaction.CompareTxArgs(
+		domain.TxCompare{Key: "key1", Target: domain.CompareTargetVersion, Result: domain.CompareGreater, Version: 1},
+		domain.TxCompare{Key: "key2", Target: domain.CompareTargetValue, Result: domain.CompareNotEqual, Value: "value2"},
+	)
+	assert.Len(t, compare.SQLs, 2)
+	assert.Contains(t, compare.SQLs[0], "version FROM key_value")
+	assert.Contains(t, compare.SQLs[0], ") > $2")
+	assert.Contains(t, compare.SQLs[1], ") <> $2")
+	assert.Equal(t, []any{"key2", "value2"}, compare.Args[1])
+
+	return compare.
+		Append(KeyValueTransaction.DeleteTxArgs(unit)).
+		Append(KeyValueTransaction.UpsertTxArgs(unit)), nil
+}
+
+func positiveKeyValueTransactionTxArgsCheck(t *testing.T, i interface{}) bool {
+	if txArgs, ok := i.(domain.TxArgs); ok {
+		return assert.Len(t, txArgs.SQLs, 4) &&
+			assert.Len(t, txArgs.Args, 4) &&
+			assert.Equal(t, KeyValueDelete.SQL(), txArgs.SQLs[2]) &&
+			assert.Equal(t, KeyValueUpsert.SQL(), txArgs.SQLs[3])
+	}
+	return false
+}
+
+func positiveTransactionKeyValue(_ *testing.T) (interface{}, error) {
+	return TransactionKeyValue(
+		context.Background(),
+		stubRepoOk[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue]{},
+		domain.Txn[KeyValue]{
+			Compare: []domain.TxCompare{{Key: "key1"}},
+			Success: []domain.TxOp[KeyValue]{{Name: domain.UpsertAction, Unit: KeyValue{key: "key1"}}},
+		},
+	)
+}
+
+func positiveTransactionKeyValueCheck(_ *testing.T, i interface{}) bool {
+	return checkTrue(i)
+}
+
 //!-
 /* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
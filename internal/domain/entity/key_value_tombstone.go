@@ -0,0 +1,209 @@
+/*
+ * This file was last modified at 2026-10-18 21:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * key_value_tombstone.go
+ * $Id$
+ */
+//!+
+
+package entity
+
+import (
+	"context"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"time"
+)
+
+var (
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueDeleted)(nil)
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValuePurge)(nil)
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueRow)(nil)
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueUndelete)(nil)
+	_ domain.Pager                         = (*keyValueDeleted)(nil)
+	_ domain.Requirer                      = (*keyValueUndelete)(nil)
+)
+
+var (
+	KeyValueRow      keyValueRow
+	KeyValueUndelete keyValueUndelete
+)
+
+// ListDeletedKeyValue постраничная выборка записей помеченных удалёнными,
+// ключи которых начинаются с prefix, начиная с from включительно.
+func ListDeletedKeyValue(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	prefix, from string,
+	limit int64,
+) ([]KeyValue, error) {
+
+	var err error
+
+	result, er0 := repo.Get(ctx, MakeKeyValueDeleted(from, limit), KeyValue{key: prefix}, func(s domain.Scanner) KeyValue {
+		var r KeyValue
+		if er1 := s.Scan(&r.key, &r.value, &r.version, &r.deleted, &r.createdAt, &r.updatedAt); er1 != nil {
+			err = er1
+		}
+		return r
+	})
+	if er0 != nil {
+		return result, er0
+	}
+	return result, err
+}
+
+// PurgeKeyValue окончательное удаление не более limit записей, помеченных
+// удалёнными ранее before, возвращает удалённые записи.
+func PurgeKeyValue(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	before time.Time,
+	limit int64,
+) ([]KeyValue, error) {
+
+	var err error
+
+	result, er0 := repo.Get(ctx, MakeKeyValuePurge(before, limit), KeyValue{}, func(s domain.Scanner) KeyValue {
+		var r KeyValue
+		if er1 := s.Scan(&r.key, &r.value, &r.version, &r.deleted, &r.createdAt, &r.updatedAt); er1 != nil {
+			err = er1
+		}
+		return r
+	})
+	if er0 != nil {
+		return result, er0
+	}
+	return result, err
+}
+
+// RowKeyValue запись ключа key, в том числе помеченная удалённой,
+// found = false — записи нет.
+func RowKeyValue(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	key string,
+) (unit KeyValue, found bool, err error) {
+
+	result, er0 := repo.Get(ctx, KeyValueRow, KeyValue{key: key}, func(s domain.Scanner) KeyValue {
+		var r KeyValue
+		if er1 := s.Scan(&r.key, &r.value, &r.version, &r.deleted, &r.createdAt, &r.updatedAt); er1 != nil {
+			err = er1
+		}
+		return r
+	})
+	if er0 != nil {
+		return unit, false, er0
+	}
+	if err != nil || len(result) == 0 {
+		return unit, false, err
+	}
+	return result[0], true, nil
+}
+
+// Undelete восстановление записи, помеченной удалённой, с прежним значением
+// и следующей версией; domain.ErrNotFound — удалённой записи нет.
+func (f *KeyValue) Undelete(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+) error {
+
+	if f == nil {
+		return ErrKeyValueNil
+	}
+	return f.do(ctx, KeyValueUndelete, repo)
+}
+
+type keyValueDeleted struct {
+	keyValueList
+}
+
+// MakeKeyValueDeleted выборка не более limit записей помеченных удалёнными
+// (limit < 1 без ограничения) начиная с ключа from включительно.
+func MakeKeyValueDeleted(from string, limit int64) keyValueDeleted {
+	return keyValueDeleted{keyValueList: MakeKeyValueList(from, limit, false)}
+}
+
+func (k keyValueDeleted) Name() string {
+	return domain.DeletedAction
+}
+
+func (k keyValueDeleted) SQL() string {
+	return `SELECT key, value, version, deleted, created_at, updated_at
+	FROM key_value
+	WHERE key LIKE $1 || '%' AND key >= $2 AND deleted
+	ORDER BY key
+	LIMIT $3`
+}
+
+type keyValuePurge struct {
+	before time.Time
+	limit  int64
+}
+
+func MakeKeyValuePurge(before time.Time, limit int64) keyValuePurge {
+	return keyValuePurge{before: before, limit: limit}
+}
+
+func (k keyValuePurge) Args(_ KeyValue) []any {
+	return []any{k.before, k.limit}
+}
+
+func (k keyValuePurge) Name() string {
+	return domain.PurgeAction
+}
+
+// SQL выбранные записи блокируются, поэтому одновременная запись
+// ключа ждёт окончания удаления, а другой экземпляр их пропускает.
+func (k keyValuePurge) SQL() string {
+	return `DELETE FROM key_value
+	WHERE key IN (
+		SELECT key FROM key_value
+		WHERE deleted AND COALESCE(updated_at, created_at) < $1
+		ORDER BY key
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING key, value, version, deleted, created_at, updated_at`
+}
+
+type keyValueRow struct{}
+
+func (k keyValueRow) Args(e KeyValue) []any {
+	return []any{e.key}
+}
+
+func (k keyValueRow) Name() string {
+	return domain.RowAction
+}
+
+// SQL в отличие от keyValueSelect выбирается и запись, помеченная удалённой.
+func (k keyValueRow) SQL() string {
+	return `SELECT key, value, version, deleted, created_at, updated_at
+	FROM key_value
+	WHERE key = $1`
+}
+
+type keyValueUndelete struct{}
+
+func (k keyValueUndelete) Args(e KeyValue) []any {
+	return []any{e.key, e.updatedAt}
+}
+
+func (k keyValueUndelete) Name() string {
+	return domain.UndeleteAction
+}
+
+func (k keyValueUndelete) Required() bool {
+	return true
+}
+
+func (k keyValueUndelete) SQL() string {
+	return `UPDATE key_value
+	SET deleted = false, updated_at = $2, version = version + 1
+	WHERE key = $1 AND deleted
+	RETURNING key, value, version, deleted, created_at, updated_at`
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,65 @@
+/*
+ * This file was last modified at 2026-10-18 21:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * key_value_tombstone_test.go
+ * $Id$
+ */
+//!+
+
+package entity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestKeyValueTombstone(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for struct keyValueUndelete method Required()",
+			func(_ *testing.T) (interface{}, error) { return KeyValueUndeleteOutbox, nil },
+			func(t *testing.T, i interface{}) bool {
+				requirer, ok := i.(domain.Requirer)
+				_, outbox := i.(domain.Outboxer)
+				return assert.True(t, ok) && assert.True(t, requirer.Required()) && assert.True(t, outbox)
+			},
+		},
+		{
+			"test #1 positive for function MakeKeyValuePurge(time.Time, int64)",
+			func(_ *testing.T) (interface{}, error) {
+				return MakeKeyValuePurge(time.Date(2026, 9, 18, 0, 0, 0, 0, time.UTC), 500), nil
+			},
+			func(t *testing.T, i interface{}) bool {
+				action := i.(keyValuePurge)
+				return assert.Equal(t, []any{time.Date(2026, 9, 18, 0, 0, 0, 0, time.UTC), int64(500)}, action.Args(KeyValue{})) &&
+					assert.Contains(t, action.SQL(), "FOR UPDATE SKIP LOCKED")
+			},
+		},
+		{
+			"test #2 positive for struct keyValueRow tombstone selected",
+			func(_ *testing.T) (interface{}, error) { return KeyValueRow, nil },
+			func(t *testing.T, i interface{}) bool {
+				action := i.(keyValueRow)
+				return assert.Equal(t, []any{"key1"}, action.Args(KeyValue{key: "key1"})) &&
+					assert.NotContains(t, action.SQL(), "NOT deleted")
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,411 @@
+/*
+ * This file was last modified at 2026-10-18 19:20 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * outbox.go
+ * $Id$
+ */
+//!+
+
+package entity
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"time"
+)
+
+const (
+	OutboxClaimAction   = "outbox_claim"
+	OutboxDoneAction    = "outbox_done"
+	OutboxEnqueueAction = "outbox_enqueue"
+	OutboxFailAction    = "outbox_fail"
+)
+
+var (
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueCompareAndDeleteOutbox)(nil)
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueCompareAndSwapOutbox)(nil)
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueDeleteOutbox)(nil)
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueUndeleteOutbox)(nil)
+	_ domain.Actioner[*KeyValue, KeyValue] = (*keyValueUpsertOutbox)(nil)
+	_ domain.Actioner[*Outbox, Outbox]     = (*outboxClaim)(nil)
+	_ domain.Actioner[*Outbox, Outbox]     = (*outboxDone)(nil)
+	_ domain.Actioner[*Outbox, Outbox]     = (*outboxEnqueue)(nil)
+	_ domain.Actioner[*Outbox, Outbox]     = (*outboxFail)(nil)
+	_ domain.Comparer                      = (*keyValueCompareAndDeleteOutbox)(nil)
+	_ domain.Comparer                      = (*keyValueCompareAndSwapOutbox)(nil)
+	_ domain.Entity                        = (*Outbox)(nil)
+	_ domain.Outboxer                      = (*keyValueCompareAndDeleteOutbox)(nil)
+	_ domain.Outboxer                      = (*keyValueCompareAndSwapOutbox)(nil)
+	_ domain.Outboxer                      = (*keyValueDeleteOutbox)(nil)
+	_ domain.Outboxer                      = (*keyValueTransactionOutbox)(nil)
+	_ domain.Outboxer                      = (*keyValueUndeleteOutbox)(nil)
+	_ domain.Outboxer                      = (*keyValueUpsertOutbox)(nil)
+	_ domain.TransactionalAction           = (*keyValueTransactionOutbox)(nil)
+)
+
+var (
+	ErrOutboxNil              = fmt.Errorf("bad pointer, Outbox is nil")
+	KeyValueDeleteOutbox      keyValueDeleteOutbox
+	KeyValueTransactionOutbox keyValueTransactionOutbox
+	KeyValueUndeleteOutbox    keyValueUndeleteOutbox
+	KeyValueUpsertOutbox      keyValueUpsertOutbox
+	OutboxDone                outboxDone
+	OutboxEnqueue             outboxEnqueue
+)
+
+// Outbox событие об изменении записи key_value, которое должно быть
+// доставлено в etcd. Событие несёт только ключ: при доставке в etcd
+// записывается текущее состояние записи в PostgreSQL, поэтому повторная
+// доставка и доставка не по порядку безопасны.
+type Outbox struct {
+	attempts  int64
+	createdAt time.Time
+	id        int64
+	key       string
+	lastError sql.NullString
+}
+
+func MakeOutbox(id int64, key string) Outbox {
+	return Outbox{id: id, key: key}
+}
+
+// ClaimOutbox выборка не более limit недоставленных событий с закреплением
+// их на время lease за текущим экземпляром прокси.
+func ClaimOutbox(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*Outbox, Outbox], *Outbox, Outbox],
+	limit int64,
+	lease time.Duration,
+) ([]Outbox, error) {
+
+	var err error
+
+	result, er0 := repo.Get(ctx, MakeOutboxClaim(limit, lease), Outbox{}, func(s domain.Scanner) Outbox {
+		var r Outbox
+		if er1 := s.Scan(&r.id, &r.key, &r.attempts, &r.lastError, &r.createdAt); er1 != nil {
+			err = er1
+		}
+		return r
+	})
+	if er0 != nil {
+		return result, er0
+	}
+	return result, err
+}
+
+// TransactionKeyValueOutbox атомарное выполнение транзакции txn над записями
+// с событиями outbox для выполненных операций в той же транзакции.
+func TransactionKeyValueOutbox(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	txn domain.Txn[KeyValue],
+) (bool, error) {
+	return repo.Transaction(ctx, KeyValueTransactionOutbox, txn)
+}
+
+// CompareAndDeleteOutbox условное удаление записи с событием outbox в одной транзакции.
+func (f *KeyValue) CompareAndDeleteOutbox(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	expected int64,
+) error {
+
+	if f == nil {
+		return ErrKeyValueNil
+	}
+	return f.do(ctx, MakeKeyValueCompareAndDeleteOutbox(expected), repo)
+}
+
+// CompareAndSwapOutbox условная запись значения с событием outbox в одной транзакции.
+func (f *KeyValue) CompareAndSwapOutbox(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+	expected int64,
+) error {
+
+	if f == nil {
+		return ErrKeyValueNil
+	}
+	return f.do(ctx, MakeKeyValueCompareAndSwapOutbox(expected), repo)
+}
+
+// UndeleteOutbox восстановление записи, помеченной удалённой, вместе
+// с событием outbox в одной транзакции.
+func (f *KeyValue) UndeleteOutbox(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+) error {
+
+	if f == nil {
+		return ErrKeyValueNil
+	}
+	return f.do(ctx, KeyValueUndeleteOutbox, repo)
+}
+
+// UpsertOutbox запись значения с событием outbox в одной транзакции.
+func (f *KeyValue) UpsertOutbox(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*KeyValue, KeyValue], *KeyValue, KeyValue],
+) error {
+
+	if f == nil {
+		return ErrKeyValueNil
+	}
+	return f.do(ctx, KeyValueUpsertOutbox, repo)
+}
+
+// Attempts количество неудачных попыток доставки события.
+func (o *Outbox) Attempts() int64 {
+
+	if o == nil {
+		return 0
+	}
+	return o.attempts
+}
+
+// CreatedAt время создания события.
+func (o *Outbox) CreatedAt() time.Time {
+
+	if o == nil {
+		return time.Time{}
+	}
+	return o.createdAt
+}
+
+// ID порядковый номер события.
+func (o *Outbox) ID() int64 {
+
+	if o == nil {
+		return 0
+	}
+	return o.id
+}
+
+func (o Outbox) Key() string {
+	return o.key
+}
+
+// LastError ошибка последней неудачной попытки доставки события.
+func (o *Outbox) LastError() string {
+
+	if o == nil || !o.lastError.Valid {
+		return ""
+	}
+	return o.lastError.String
+}
+
+// Done отметка о доставке события.
+func (o *Outbox) Done(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*Outbox, Outbox], *Outbox, Outbox],
+) error {
+
+	if o == nil {
+		return ErrOutboxNil
+	}
+	return o.do(ctx, OutboxDone, repo)
+}
+
+// Enqueue добавление события для ключа вне транзакции изменения записи:
+// запись уже изменена в PostgreSQL, но не доставлена в etcd.
+func (o *Outbox) Enqueue(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*Outbox, Outbox], *Outbox, Outbox],
+) error {
+
+	if o == nil {
+		return ErrOutboxNil
+	}
+	return o.do(ctx, OutboxEnqueue, repo)
+}
+
+// Fail учёт неудачной попытки доставки события, событие снимается с
+// закрепления и будет доставлено повторно.
+func (o *Outbox) Fail(
+	ctx context.Context,
+	repo domain.Repo[domain.Actioner[*Outbox, Outbox], *Outbox, Outbox],
+	cause error,
+) error {
+
+	if o == nil {
+		return ErrOutboxNil
+	}
+	return o.do(ctx, MakeOutboxFail(cause), repo)
+}
+
+func (o *Outbox) do(
+	ctx context.Context,
+	action domain.Actioner[*Outbox, Outbox],
+	repo domain.Repo[domain.Actioner[*Outbox, Outbox], *Outbox, Outbox],
+) (err error) {
+
+	_, er0 := repo.Do(ctx, action, *o, func(s domain.Scanner) Outbox {
+
+		t := *o
+		err = s.Scan(&t.id, &t.key, &t.attempts, &t.lastError, &t.createdAt)
+
+		if err == nil {
+			*o = t
+		}
+		return t
+	})
+	if er0 != nil {
+		return er0
+	}
+	return err
+}
+
+type outboxClaim struct {
+	lease time.Duration
+	limit int64
+}
+
+// MakeOutboxClaim закрепление не более limit событий на время lease.
+func MakeOutboxClaim(limit int64, lease time.Duration) outboxClaim {
+	return outboxClaim{lease: lease, limit: limit}
+}
+
+func (o outboxClaim) Args(_ Outbox) []any {
+	return []any{o.limit, o.lease.Seconds()}
+}
+
+func (o outboxClaim) Name() string {
+	return OutboxClaimAction
+}
+
+// SQL события закреплённые другим экземпляром прокси пропускаются
+// до истечения срока закрепления.
+func (o outboxClaim) SQL() string {
+	return `WITH batch AS (
+	SELECT id FROM key_value_outbox
+	WHERE processed_at IS NULL AND (locked_until IS NULL OR locked_until < now())
+	ORDER BY id
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+	)
+	UPDATE key_value_outbox o
+	SET locked_until = now() + make_interval(secs => $2::double precision)
+	FROM batch
+	WHERE o.id = batch.id
+	RETURNING o.id, o.key, o.attempts, o.last_error, o.created_at`
+}
+
+type outboxDone struct{}
+
+func (o outboxDone) Args(e Outbox) []any {
+	return []any{e.id}
+}
+
+func (o outboxDone) Name() string {
+	return OutboxDoneAction
+}
+
+func (o outboxDone) SQL() string {
+	return `UPDATE key_value_outbox
+	SET processed_at = now(), locked_until = NULL
+	WHERE id = $1
+	RETURNING id, key, attempts, last_error, created_at`
+}
+
+type outboxEnqueue struct{}
+
+func (o outboxEnqueue) Args(e Outbox) []any {
+	return []any{e.key}
+}
+
+func (o outboxEnqueue) Name() string {
+	return OutboxEnqueueAction
+}
+
+func (o outboxEnqueue) SQL() string {
+	return `INSERT INTO key_value_outbox (key)
+	VALUES ($1)
+	RETURNING id, key, attempts, last_error, created_at`
+}
+
+type outboxFail struct {
+	cause string
+}
+
+func MakeOutboxFail(cause error) outboxFail {
+
+	if cause == nil {
+		return outboxFail{}
+	}
+	return outboxFail{cause: cause.Error()}
+}
+
+func (o outboxFail) Args(e Outbox) []any {
+	return []any{e.id, o.cause}
+}
+
+func (o outboxFail) Name() string {
+	return OutboxFailAction
+}
+
+func (o outboxFail) SQL() string {
+	return `UPDATE key_value_outbox
+	SET attempts = attempts + 1, last_error = $2, locked_until = NULL
+	WHERE id = $1
+	RETURNING id, key, attempts, last_error, created_at`
+}
+
+// keyValueOutbox событие outbox для каждой изменяемой записи KeyValue.
+type keyValueOutbox struct{}
+
+func (k keyValueOutbox) OutboxTxArgs(units ...any) domain.TxArgs {
+
+	var result domain.TxArgs
+
+	for _, u := range units {
+		if unit, ok := u.(KeyValue); ok {
+			result.Args = append(result.Args, []any{unit.key})
+			result.SQLs = append(result.SQLs, `INSERT INTO key_value_outbox (key) VALUES ($1)`)
+		}
+	}
+	return result
+}
+
+type keyValueCompareAndDeleteOutbox struct {
+	keyValueCompareAndDelete
+	keyValueOutbox
+}
+
+func MakeKeyValueCompareAndDeleteOutbox(expected int64) keyValueCompareAndDeleteOutbox {
+	return keyValueCompareAndDeleteOutbox{keyValueCompareAndDelete: MakeKeyValueCompareAndDelete(expected)}
+}
+
+type keyValueCompareAndSwapOutbox struct {
+	keyValueCompareAndSwap
+	keyValueOutbox
+}
+
+func MakeKeyValueCompareAndSwapOutbox(expected int64) keyValueCompareAndSwapOutbox {
+	return keyValueCompareAndSwapOutbox{keyValueCompareAndSwap: MakeKeyValueCompareAndSwap(expected)}
+}
+
+type keyValueDeleteOutbox struct {
+	keyValueDelete
+	keyValueOutbox
+}
+
+type keyValueTransactionOutbox struct {
+	keyValueTransaction
+	keyValueOutbox
+}
+
+type keyValueUndeleteOutbox struct {
+	keyValueUndelete
+	keyValueOutbox
+}
+
+type keyValueUpsertOutbox struct {
+	keyValueUpsert
+	keyValueOutbox
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,98 @@
+/*
+ * This file was last modified at 2026-10-18 19:20 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * outbox_test.go
+ * $Id$
+ */
+//!+
+
+package entity
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestOutbox(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for struct keyValueOutbox method OutboxTxArgs(...any)",
+			func(_ *testing.T) (interface{}, error) {
+				return KeyValueUpsertOutbox.OutboxTxArgs(
+					MakeKeyValue("key1", "value1", 0, DefaultTAttributes()),
+					"not a KeyValue",
+					MakeKeyValue("key2", "", 0, DefaultTAttributes()),
+				), nil
+			},
+			positiveOutboxTxArgsCheck,
+		},
+		{
+			"test #1 positive for function MakeKeyValueCompareAndSwapOutbox(int64)",
+			func(_ *testing.T) (interface{}, error) { return MakeKeyValueCompareAndSwapOutbox(3), nil },
+			positiveCompareAndSwapOutboxCheck,
+		},
+		{
+			"test #2 positive for function MakeOutboxClaim(int64, time.Duration)",
+			func(_ *testing.T) (interface{}, error) { return MakeOutboxClaim(10, 30*time.Second), nil },
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []any{int64(10), float64(30)}, i.(outboxClaim).Args(Outbox{}))
+			},
+		},
+		{
+			"test #3 positive for function MakeOutboxFail(error)",
+			func(_ *testing.T) (interface{}, error) { return MakeOutboxFail(fmt.Errorf("etcd unavailable")), nil },
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []any{int64(1), "etcd unavailable"}, i.(outboxFail).Args(MakeOutbox(1, "key1")))
+			},
+		},
+		{
+			"test #4 positive for struct outboxEnqueue",
+			func(_ *testing.T) (interface{}, error) { return OutboxEnqueue, nil },
+			func(t *testing.T, i interface{}) bool {
+				action := i.(outboxEnqueue)
+				return assert.Equal(t, []any{"key1"}, action.Args(MakeOutbox(0, "key1"))) &&
+					assert.Equal(t, OutboxEnqueueAction, action.Name()) &&
+					assert.Contains(t, action.SQL(), "INSERT INTO key_value_outbox")
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveOutboxTxArgsCheck(t *testing.T, i interface{}) bool {
+
+	txArgs := i.(domain.TxArgs)
+
+	return assert.Equal(t, [][]any{{"key1"}, {"key2"}}, txArgs.Args) &&
+		assert.Len(t, txArgs.SQLs, 2) &&
+		assert.Contains(t, txArgs.SQLs[0], "INSERT INTO key_value_outbox")
+}
+
+// positiveCompareAndSwapOutboxCheck действие с outbox остаётся условным.
+func positiveCompareAndSwapOutboxCheck(t *testing.T, i interface{}) bool {
+
+	action := i.(keyValueCompareAndSwapOutbox)
+	comparer, ok := any(action).(domain.Comparer)
+
+	return assert.True(t, ok) &&
+		assert.Equal(t, int64(3), comparer.ExpectedVersion()) &&
+		assert.Equal(t, domain.CompareAndSwapAction, action.Name()) &&
+		assert.Equal(t, MakeKeyValueCompareAndSwap(3).SQL(), action.SQL())
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -41,6 +41,20 @@ func (s stubRepoOk[A, T, U]) Get(_ context.Context, action A, _ U, scan func(dom
 	return make([]U, 0), nil
 }
 
+func (s stubRepoOk[A, T, U]) Transaction(_ context.Context, action domain.TransactionalAction, txn domain.Txn[U]) (bool, error) {
+	if action.Name() == "" {
+		panic(`action.Name() == ""`)
+	}
+	compares := make([]any, 0, len(txn.Compare))
+	for _, compare := range txn.Compare {
+		compares = append(compares, compare)
+	}
+	if len(action.CompareTxArgs(compares...).SQLs) != len(txn.Compare) {
+		panic(`len(action.CompareTxArgs(compares...).SQLs) != len(txn.Compare)`)
+	}
+	return true, nil
+}
+
 type stubScannerOk struct {
 }
 
@@ -62,6 +76,10 @@ func (s stubRepoErr[A, T, U]) Get(_ context.Context, _ A, _ U, scan func(domain.
 	return make([]U, 0), ErrStub
 }
 
+func (s stubRepoErr[A, T, U]) Transaction(_ context.Context, _ domain.TransactionalAction, _ domain.Txn[U]) (bool, error) {
+	return false, ErrStub
+}
+
 type stubRepoScannerErr[A domain.Actioner[T, U], T domain.Ptr[U], U domain.Entity] struct {
 }
 
@@ -75,6 +93,10 @@ func (s stubRepoScannerErr[A, T, U]) Get(_ context.Context, _ A, _ U, scan func(
 	return make([]U, 0), nil
 }
 
+func (s stubRepoScannerErr[A, T, U]) Transaction(_ context.Context, _ domain.TransactionalAction, _ domain.Txn[U]) (bool, error) {
+	return true, nil
+}
+
 type stubScannerErr struct {
 }
 
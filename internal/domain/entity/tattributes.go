@@ -28,6 +28,30 @@ func MakeTAttributes(deleted sql.NullBool, createdAt time.Time, updatedAt sql.Nu
 	return TAttributes{deleted: deleted, createdAt: createdAt, updatedAt: updatedAt}
 }
 
+// CreatedAt время создания записи.
+func (t *TAttributes) CreatedAt() time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.createdAt
+}
+
+// Deleted признак логического удаления записи.
+func (t *TAttributes) Deleted() bool {
+	if t == nil {
+		return false
+	}
+	return t.deleted.Valid && t.deleted.Bool
+}
+
+// UpdatedAt время последнего изменения записи.
+func (t *TAttributes) UpdatedAt() sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return t.updatedAt
+}
+
 func (t *TAttributes) String() string {
 	if t == nil {
 		return ""
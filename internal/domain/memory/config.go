@@ -12,14 +12,30 @@ import "time"
 
 // Config defines the config for storage.
 type Config struct {
+	// Source of the monotonic time in nanoseconds for expiry deadlines
+	//
+	// Default is MonotonicClock
+	Clock func() int64
+
 	// Time before deleting expired keys
 	//
 	// Default is 10 * time.Second
 	GCInterval time.Duration
+
+	// Upper bound of the summary size of keys and values, used by LRU
+	//
+	// Zero means no bound
+	MaxBytes int64
+
+	// Upper bound of the number of keys, used by LRU
+	//
+	// Zero means no bound
+	MaxEntries int
 }
 
 // ConfigDefault is the default config
 var ConfigDefault = Config{
+	Clock:      MonotonicClock,
 	GCInterval: 10 * time.Second,
 }
 
@@ -34,8 +50,20 @@ func configDefault(config ...Config) Config {
 	cfg := config[0]
 
 	// Set default values
+	if cfg.Clock == nil {
+		cfg.Clock = ConfigDefault.Clock
+	}
 	if int(cfg.GCInterval.Seconds()) < int(time.Second) {
 		cfg.GCInterval = ConfigDefault.GCInterval
 	}
 	return cfg
 }
+
+// clockStart is the reference point of MonotonicClock
+var clockStart = time.Now()
+
+// MonotonicClock returns nanoseconds elapsed since the package initialization,
+// it is measured by the monotonic clock and is not affected by wall clock changes
+func MonotonicClock() int64 {
+	return int64(time.Since(clockStart))
+}
@@ -0,0 +1,196 @@
+/*
+ * This file was last modified at 2026-10-19 00:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * lru.go
+ * $Id$
+ */
+
+package memory
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LRU storage bounded by the number of keys and the summary size of keys
+// and values: the least recently used keys are evicted when a bound is exceeded
+type LRU struct {
+	mux               sync.Mutex
+	items             map[string]*list.Element
+	order             *list.List
+	bytes             int64
+	maxBytes          int64
+	maxEntries        int
+	clock             func() int64
+	gcInterval        time.Duration
+	done              chan struct{}
+	capacityEvictions atomic.Uint64
+	evictions         atomic.Uint64
+	hits              atomic.Uint64
+	misses            atomic.Uint64
+}
+
+type lruEntry struct {
+	key    string
+	data   []byte
+	expiry int64
+	size   int64
+}
+
+// NewLRU creates a new bounded memory storage
+func NewLRU(config ...Config) *LRU {
+	// Set default config
+	cfg := configDefault(config...)
+
+	// Create storage
+	store := &LRU{
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		maxBytes:   cfg.MaxBytes,
+		maxEntries: cfg.MaxEntries,
+		clock:      cfg.Clock,
+		gcInterval: cfg.GCInterval,
+		done:       make(chan struct{}),
+	}
+
+	// Start garbage collector
+	go store.gc()
+
+	return store
+}
+
+// Get value by key and mark the key as recently used
+func (s *LRU) Get(key string) ([]byte, error) {
+	if len(key) <= 0 {
+		return nil, nil
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	element, ok := s.items[key]
+	if !ok {
+		s.misses.Add(1)
+		return nil, nil
+	}
+	e := element.Value.(*lruEntry)
+	if expired(e.expiry, s.clock()) {
+		s.remove(element)
+		s.evictions.Add(1)
+		s.misses.Add(1)
+		return nil, nil
+	}
+	s.order.MoveToFront(element)
+	s.hits.Add(1)
+	return e.data, nil
+}
+
+// Set key with value, a value larger than MaxBytes is not stored
+func (s *LRU) Set(key string, val []byte, exp time.Duration) error {
+	// Ain't Nobody Got Time For That
+	if len(key) <= 0 || len(val) <= 0 {
+		return nil
+	}
+	e := &lruEntry{key: key, data: val, expiry: deadline(s.clock(), exp), size: entrySize(key, val)}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if element, ok := s.items[key]; ok {
+		s.remove(element)
+	}
+	if s.maxBytes > 0 && e.size > s.maxBytes {
+		s.capacityEvictions.Add(1)
+		return nil
+	}
+	s.items[key] = s.order.PushFront(e)
+	s.bytes += e.size
+
+	for s.overflow() {
+		s.remove(s.order.Back())
+		s.capacityEvictions.Add(1)
+	}
+	return nil
+}
+
+// Delete key by key
+func (s *LRU) Delete(key string) error {
+	// Ain't Nobody Got Time For That
+	if len(key) <= 0 {
+		return nil
+	}
+	s.mux.Lock()
+	if element, ok := s.items[key]; ok {
+		s.remove(element)
+	}
+	s.mux.Unlock()
+	return nil
+}
+
+// Invalidate all keys
+func (s *LRU) Invalidate() error {
+	s.mux.Lock()
+	s.items = make(map[string]*list.Element)
+	s.order.Init()
+	s.bytes = 0
+	s.mux.Unlock()
+	return nil
+}
+
+// Close the memory storage
+func (s *LRU) Close() error {
+	s.done <- struct{}{}
+	return nil
+}
+
+// Stats returns usage counters, the current number and size of keys
+func (s *LRU) Stats() Stats {
+	s.mux.Lock()
+	size, bytes := len(s.items), s.bytes
+	s.mux.Unlock()
+	return Stats{
+		Bytes:             bytes,
+		CapacityEvictions: s.capacityEvictions.Load(),
+		Evictions:         s.evictions.Load(),
+		Hits:              s.hits.Load(),
+		Misses:            s.misses.Load(),
+		Size:              size,
+	}
+}
+
+func (s *LRU) gc() {
+	ticker := time.NewTicker(s.gcInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			ts := s.clock()
+			s.mux.Lock()
+			for element := s.order.Back(); element != nil; {
+				prev := element.Prev()
+				if e := element.Value.(*lruEntry); expired(e.expiry, ts) {
+					s.remove(element)
+					s.evictions.Add(1)
+				}
+				element = prev
+			}
+			s.mux.Unlock()
+		}
+	}
+}
+
+func (s *LRU) overflow() bool {
+	return s.maxEntries > 0 && s.order.Len() > s.maxEntries ||
+		s.maxBytes > 0 && s.bytes > s.maxBytes
+}
+
+func (s *LRU) remove(element *list.Element) {
+	e := s.order.Remove(element).(*lruEntry)
+	delete(s.items, e.key)
+	s.bytes -= e.size
+}
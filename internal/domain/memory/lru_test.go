@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func Test_LRU_Get(t *testing.T) {
+	t.Parallel()
+	store := NewLRU(Config{MaxEntries: 2})
+	defer store.Close()
+
+	err := store.Set("john", []byte("doe"), 0)
+	utils.AssertEqual(t, nil, err)
+
+	result, err := store.Get("john")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []byte("doe"), result)
+}
+
+func Test_LRU_MaxEntries(t *testing.T) {
+	t.Parallel()
+	store := NewLRU(Config{MaxEntries: 2})
+	defer store.Close()
+
+	utils.AssertEqual(t, nil, store.Set("john1", []byte("doe"), 0))
+	utils.AssertEqual(t, nil, store.Set("john2", []byte("doe"), 0))
+
+	// john1 becomes recently used, so john2 is evicted
+	_, _ = store.Get("john1")
+	utils.AssertEqual(t, nil, store.Set("john3", []byte("doe"), 0))
+
+	result, _ := store.Get("john2")
+	utils.AssertEqual(t, true, len(result) == 0)
+	result, _ = store.Get("john1")
+	utils.AssertEqual(t, []byte("doe"), result)
+
+	stats := store.Stats()
+	utils.AssertEqual(t, uint64(1), stats.CapacityEvictions)
+	utils.AssertEqual(t, 2, stats.Size)
+	utils.AssertEqual(t, int64(16), stats.Bytes)
+}
+
+func Test_LRU_MaxBytes(t *testing.T) {
+	t.Parallel()
+	store := NewLRU(Config{MaxBytes: 10})
+	defer store.Close()
+
+	utils.AssertEqual(t, nil, store.Set("k1", []byte("12345"), 0))
+	utils.AssertEqual(t, nil, store.Set("k2", []byte("123"), 0))
+
+	result, _ := store.Get("k1")
+	utils.AssertEqual(t, true, len(result) == 0)
+
+	// The value larger than the bound is not stored at all
+	utils.AssertEqual(t, nil, store.Set("k3", []byte("1234567890"), 0))
+	result, _ = store.Get("k3")
+	utils.AssertEqual(t, true, len(result) == 0)
+
+	stats := store.Stats()
+	utils.AssertEqual(t, uint64(2), stats.CapacityEvictions)
+	utils.AssertEqual(t, 1, stats.Size)
+	utils.AssertEqual(t, int64(5), stats.Bytes)
+}
+
+func Test_LRU_Set_Override(t *testing.T) {
+	t.Parallel()
+	store := NewLRU(Config{MaxBytes: 100})
+	defer store.Close()
+
+	utils.AssertEqual(t, nil, store.Set("john", []byte("doe"), 0))
+	utils.AssertEqual(t, nil, store.Set("john", []byte("smith"), 0))
+
+	result, _ := store.Get("john")
+	utils.AssertEqual(t, []byte("smith"), result)
+	utils.AssertEqual(t, int64(9), store.Stats().Bytes)
+}
+
+func Test_LRU_Get_Expired(t *testing.T) {
+	t.Parallel()
+	clock := &testClock{}
+	store := NewLRU(Config{Clock: clock.Now, MaxEntries: 2})
+	defer store.Close()
+
+	utils.AssertEqual(t, nil, store.Set("john", []byte("doe"), 250*time.Millisecond))
+
+	clock.Add(249 * time.Millisecond)
+	result, _ := store.Get("john")
+	utils.AssertEqual(t, []byte("doe"), result)
+
+	clock.Add(time.Millisecond)
+	result, err := store.Get("john")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, len(result) == 0)
+	utils.AssertEqual(t, uint64(1), store.Stats().Evictions)
+}
+
+func Test_LRU_Delete_Invalidate(t *testing.T) {
+	t.Parallel()
+	store := NewLRU(Config{MaxEntries: 10})
+	defer store.Close()
+
+	utils.AssertEqual(t, nil, store.Set("john1", []byte("doe"), 0))
+	utils.AssertEqual(t, nil, store.Set("john2", []byte("doe"), 0))
+	utils.AssertEqual(t, nil, store.Delete("john1"))
+
+	result, _ := store.Get("john1")
+	utils.AssertEqual(t, true, len(result) == 0)
+
+	utils.AssertEqual(t, nil, store.Invalidate())
+	result, _ = store.Get("john2")
+	utils.AssertEqual(t, true, len(result) == 0)
+
+	stats := store.Stats()
+	utils.AssertEqual(t, 0, stats.Size)
+	utils.AssertEqual(t, int64(0), stats.Bytes)
+}
@@ -14,22 +14,50 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+)
+
+// Cache interface that is implemented by Storage and LRU
+type Cache interface {
+	Close() error
+	Delete(key string) error
+	Get(key string) ([]byte, error)
+	Invalidate() error
+	Set(key string, val []byte, exp time.Duration) error
+	Stats() Stats
+}
 
-	"github.com/gofiber/fiber/v2/utils"
+var (
+	_ Cache = (*LRU)(nil)
+	_ Cache = (*Storage)(nil)
 )
 
 // Storage interface that is implemented by storage providers
 type Storage struct {
 	mux        sync.RWMutex
 	db         map[string]entry
+	clock      func() int64
 	gcInterval time.Duration
 	done       chan struct{}
+	evictions  atomic.Uint64
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+}
+
+// Stats counters of storage usage: Evictions counts expired keys,
+// CapacityEvictions counts keys evicted to keep the storage within bounds
+type Stats struct {
+	Bytes             int64
+	CapacityEvictions uint64
+	Evictions         uint64
+	Hits              uint64
+	Misses            uint64
+	Size              int
 }
 
 type entry struct {
 	data []byte
-	// max value is 4294967295 -> Sun Feb 07 2106 06:28:15 GMT+0000
-	expiry uint32
+	// deadline in nanoseconds of the storage clock, zero means no expiry
+	expiry int64
 }
 
 // New creates a new memory storage
@@ -40,12 +68,12 @@ func New(config ...Config) *Storage {
 	// Create storage
 	store := &Storage{
 		db:         make(map[string]entry),
+		clock:      cfg.Clock,
 		gcInterval: cfg.GCInterval,
 		done:       make(chan struct{}),
 	}
 
 	// Start garbage collector
-	utils.StartTimeStampUpdater()
 	go store.gc()
 
 	return store
@@ -59,9 +87,11 @@ func (s *Storage) Get(key string) ([]byte, error) {
 	s.mux.RLock()
 	v, ok := s.db[key]
 	s.mux.RUnlock()
-	if !ok || v.expiry != 0 && v.expiry <= atomic.LoadUint32(&utils.Timestamp) {
+	if !ok || expired(v.expiry, s.clock()) {
+		s.misses.Add(1)
 		return nil, nil
 	}
+	s.hits.Add(1)
 
 	return v.data, nil
 }
@@ -73,12 +103,7 @@ func (s *Storage) Set(key string, val []byte, exp time.Duration) error {
 		return nil
 	}
 
-	var expire uint32
-	if exp != 0 {
-		expire = uint32(exp.Seconds()) + atomic.LoadUint32(&utils.Timestamp)
-	}
-
-	e := entry{val, expire}
+	e := entry{val, deadline(s.clock(), exp)}
 	s.mux.Lock()
 	s.db[key] = e
 	s.mux.Unlock()
@@ -115,29 +140,30 @@ func (s *Storage) Close() error {
 func (s *Storage) gc() {
 	ticker := time.NewTicker(s.gcInterval)
 	defer ticker.Stop()
-	var expired []string
+	var expiredKeys []string
 
 	for {
 		select {
 		case <-s.done:
 			return
 		case <-ticker.C:
-			ts := atomic.LoadUint32(&utils.Timestamp)
-			expired = expired[:0]
+			ts := s.clock()
+			expiredKeys = expiredKeys[:0]
 			s.mux.RLock()
 			for id, v := range s.db {
-				if v.expiry != 0 && v.expiry <= ts {
-					expired = append(expired, id)
+				if expired(v.expiry, ts) {
+					expiredKeys = append(expiredKeys, id)
 				}
 			}
 			s.mux.RUnlock()
 			s.mux.Lock()
 			// Double-checked locking.
 			// We might have replaced the item in the meantime.
-			for i := range expired {
-				v := s.db[expired[i]]
-				if v.expiry != 0 && v.expiry <= ts {
-					delete(s.db, expired[i])
+			for i := range expiredKeys {
+				v := s.db[expiredKeys[i]]
+				if expired(v.expiry, ts) {
+					delete(s.db, expiredKeys[i])
+					s.evictions.Add(1)
 				}
 			}
 			s.mux.Unlock()
@@ -145,9 +171,45 @@ func (s *Storage) gc() {
 	}
 }
 
+// Stats returns usage counters, the current number and size of keys
+func (s *Storage) Stats() Stats {
+	var bytes int64
+	s.mux.RLock()
+	size := len(s.db)
+	for key, v := range s.db {
+		bytes += entrySize(key, v.data)
+	}
+	s.mux.RUnlock()
+	return Stats{
+		Bytes:     bytes,
+		Evictions: s.evictions.Load(),
+		Hits:      s.hits.Load(),
+		Misses:    s.misses.Load(),
+		Size:      size,
+	}
+}
+
 // Return database client
 func (s *Storage) Conn() map[string]entry {
 	s.mux.RLock()
 	defer s.mux.RUnlock()
 	return s.db
 }
+
+// entrySize accounted size of the key with value
+func entrySize(key string, val []byte) int64 {
+	return int64(len(key) + len(val))
+}
+
+// deadline of the key expiring in exp from now, zero exp means no expiry
+func deadline(now int64, exp time.Duration) int64 {
+	if exp == 0 {
+		return 0
+	}
+	return now + int64(exp)
+}
+
+// expired reports whether the deadline is reached by now
+func expired(deadline, now int64) bool {
+	return deadline != 0 && deadline <= now
+}
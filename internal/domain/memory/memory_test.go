@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -129,6 +130,69 @@ func Test_Storage_Memory_Conn(t *testing.T) {
 	utils.AssertEqual(t, true, testStore.Conn() != nil)
 }
 
+func Test_Storage_Memory_Stats(t *testing.T) {
+	t.Parallel()
+	store := New()
+	defer store.Close()
+
+	err := store.Set("john", []byte("doe"), 0)
+	utils.AssertEqual(t, nil, err)
+
+	_, _ = store.Get("john")
+	_, _ = store.Get("jane")
+	stats := store.Stats()
+	utils.AssertEqual(t, uint64(1), stats.Hits)
+	utils.AssertEqual(t, uint64(1), stats.Misses)
+	utils.AssertEqual(t, 1, stats.Size)
+}
+
+func Test_Storage_Memory_Expiration_Clock(t *testing.T) {
+	t.Parallel()
+	clock := &testClock{}
+	store := New(Config{Clock: clock.Now})
+	defer store.Close()
+
+	utils.AssertEqual(t, nil, store.Set("john", []byte("doe"), 1500*time.Millisecond))
+
+	clock.Add(1499 * time.Millisecond)
+	result, _ := store.Get("john")
+	utils.AssertEqual(t, []byte("doe"), result)
+
+	clock.Add(time.Millisecond)
+	result, _ = store.Get("john")
+	utils.AssertEqual(t, true, len(result) == 0)
+}
+
+func Test_Storage_Memory_Expiration_SubSecond(t *testing.T) {
+	t.Parallel()
+	clock := &testClock{}
+	store := New(Config{Clock: clock.Now})
+	defer store.Close()
+
+	utils.AssertEqual(t, nil, store.Set("john", []byte("doe"), 10*time.Millisecond))
+
+	clock.Add(9 * time.Millisecond)
+	result, _ := store.Get("john")
+	utils.AssertEqual(t, []byte("doe"), result)
+
+	clock.Add(time.Millisecond)
+	result, _ = store.Get("john")
+	utils.AssertEqual(t, true, len(result) == 0)
+}
+
+// testClock is the clock advanced manually by tests
+type testClock struct {
+	now atomic.Int64
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.now.Add(int64(d))
+}
+
+func (c *testClock) Now() int64 {
+	return c.now.Load()
+}
+
 // go test -v -run=^$ -bench=Benchmark_Storage_Memory -benchmem -count=4
 func Benchmark_Storage_Memory(b *testing.B) {
 	keyLength := 1000
@@ -15,4 +15,5 @@ import (
 type Repo[A Actioner[T, U], T Ptr[U], U Entity] interface {
 	Do(ctx context.Context, action A, unit U, scan func(Scanner) U) (U, error)
 	Get(ctx context.Context, action A, unit U, scan func(Scanner) U) ([]U, error)
+	Transaction(ctx context.Context, action TransactionalAction, txn Txn[U]) (bool, error)
 }
@@ -0,0 +1,190 @@
+/*
+ * This file was last modified at 2026-10-18 23:20 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * encrypted.go
+ * $Id$
+ */
+//!+
+
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/envelope"
+)
+
+var _ domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue] = (*Encrypted)(nil)
+
+var ErrEncryptedCompare = fmt.Errorf("can't compare encrypted value")
+
+// Encrypted репозиторий записей, шифрующий значения ключей из настроенных
+// префиксов перед записью и расшифровывающий их при чтении.
+type Encrypted struct {
+	envelope *envelope.Envelope
+	repo     domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
+}
+
+// NewEncrypted при выключенном шифровании возвращает repo без изменений.
+func NewEncrypted(
+	repo domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue],
+	e *envelope.Envelope,
+) domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue] {
+
+	if !e.Enabled() {
+		return repo
+	}
+	return &Encrypted{envelope: e, repo: repo}
+}
+
+// Unencrypted репозиторий, в котором значения читаются и пишутся в том
+// виде, в каком хранятся, — для перешифрования при смене мастер-ключа.
+func Unencrypted(
+	repo domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue],
+) domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue] {
+
+	if encrypted, ok := repo.(*Encrypted); ok {
+		return encrypted.repo
+	}
+	return repo
+}
+
+func (e *Encrypted) Do(
+	ctx context.Context,
+	action domain.Actioner[*entity.KeyValue, entity.KeyValue],
+	unit entity.KeyValue,
+	scan func(domain.Scanner) entity.KeyValue,
+) (entity.KeyValue, error) {
+
+	unit, err := e.encrypt(action.Name(), unit)
+
+	if err != nil {
+		return unit, err
+	}
+	return e.repo.Do(ctx, action, unit, e.decrypt(action.Name(), scan))
+}
+
+func (e *Encrypted) Get(
+	ctx context.Context,
+	action domain.Actioner[*entity.KeyValue, entity.KeyValue],
+	unit entity.KeyValue,
+	scan func(domain.Scanner) entity.KeyValue,
+) ([]entity.KeyValue, error) {
+	return e.repo.Get(ctx, action, unit, e.decrypt(action.Name(), scan))
+}
+
+// Transaction условия на значения зашифрованных ключей не поддерживаются:
+// одно и то же значение каждый раз шифруется по-разному.
+func (e *Encrypted) Transaction(
+	ctx context.Context,
+	action domain.TransactionalAction,
+	txn domain.Txn[entity.KeyValue],
+) (bool, error) {
+
+	for _, compare := range txn.Compare {
+		if compare.Target == domain.CompareTargetValue && e.envelope.Match(compare.Key) {
+			return false, fmt.Errorf("%w: %s", ErrEncryptedCompare, compare.Key)
+		}
+	}
+	success, err := e.encryptOps(txn.Success)
+
+	if err != nil {
+		return false, err
+	}
+	failure, err := e.encryptOps(txn.Failure)
+
+	if err != nil {
+		return false, err
+	}
+	return e.repo.Transaction(ctx, action, domain.Txn[entity.KeyValue]{
+		Compare: txn.Compare,
+		Failure: failure,
+		Success: success,
+	})
+}
+
+func (e *Encrypted) decrypt(name string, scan func(domain.Scanner) entity.KeyValue) func(domain.Scanner) entity.KeyValue {
+
+	if name == domain.PurgeAction {
+		// Значения удаляемых записей не нужны.
+		return scan
+	}
+	return func(s domain.Scanner) entity.KeyValue {
+		return scan(decryptScanner{envelope: e.envelope, scanner: s})
+	}
+}
+
+func (e *Encrypted) encrypt(name string, unit entity.KeyValue) (entity.KeyValue, error) {
+
+	switch name {
+	case domain.CompareAndSwapAction, domain.UpsertAction:
+		value, err := e.envelope.Encrypt(unit.Key(), unit.Value())
+
+		if err != nil {
+			return unit, err
+		}
+		return unit.WithValue(value), nil
+	}
+	return unit, nil
+}
+
+func (e *Encrypted) encryptOps(ops []domain.TxOp[entity.KeyValue]) ([]domain.TxOp[entity.KeyValue], error) {
+
+	result := make([]domain.TxOp[entity.KeyValue], 0, len(ops))
+
+	for _, op := range ops {
+		unit, err := e.encrypt(op.Name, op.Unit)
+
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, domain.TxOp[entity.KeyValue]{Name: op.Name, Unit: unit})
+	}
+	return result, nil
+}
+
+// decryptScanner расшифровка значения записи, сканируемой
+// как (key, value, ...).
+type decryptScanner struct {
+	envelope *envelope.Envelope
+	scanner  domain.Scanner
+}
+
+func (d decryptScanner) Revision() int64 {
+
+	if revisioner, ok := d.scanner.(domain.Revisioner); ok {
+		return revisioner.Revision()
+	}
+	return 0
+}
+
+func (d decryptScanner) Scan(dest ...any) error {
+
+	if err := d.scanner.Scan(dest...); err != nil || len(dest) < 2 {
+		return err
+	}
+	pKey, ok := dest[0].(*string)
+
+	if !ok {
+		return nil
+	}
+	pValue, ok := dest[1].(*string)
+
+	if !ok {
+		return nil
+	}
+	value, err := d.envelope.Decrypt(*pKey, *pValue)
+
+	if err != nil {
+		return err
+	}
+	*pValue = value
+
+	return nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,143 @@
+/*
+ * This file was last modified at 2026-10-18 23:20 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * encrypted_test.go
+ * $Id$
+ */
+//!+
+
+package repo
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/envelope"
+	"github.com/victor-skurikhin/etcd-client/v1/tool"
+	"testing"
+)
+
+func TestEncrypted(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for methods Encrypted.Do(...) upsert and select",
+			positiveEncryptedDo,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []interface{}{true, "secret"}, i)
+			},
+		},
+		{
+			"test #1 negative for method Encrypted.Transaction(...) value compare",
+			negativeEncryptedTransaction,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(bool)) },
+		},
+		{
+			"test #2 positive for function NewEncrypted(...) disabled",
+			positiveNewEncryptedDisabled,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(bool)) },
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveEncryptedDo(_ *testing.T) (interface{}, error) {
+
+	stub := &keyValueRepoStub{}
+	encrypted := NewEncrypted(stub, newTestEnvelope())
+	unit := entity.MakeKeyValue("/secrets/key", "secret", 0, entity.TAttributes{})
+
+	if err := unit.Upsert(context.Background(), encrypted); err != nil {
+		return nil, err
+	}
+	got, err := entity.GetKeyValue(context.Background(), encrypted, "/secrets/key")
+
+	return []interface{}{envelope.IsEncrypted(stub.value), got.Value()}, err
+}
+
+func negativeEncryptedTransaction(_ *testing.T) (interface{}, error) {
+
+	encrypted := NewEncrypted(&keyValueRepoStub{}, newTestEnvelope())
+	_, err := entity.TransactionKeyValue(context.Background(), encrypted, domain.Txn[entity.KeyValue]{
+		Compare: []domain.TxCompare{{
+			Key:    "/secrets/key",
+			Result: domain.CompareEqual,
+			Target: domain.CompareTargetValue,
+			Value:  "secret",
+		}},
+	})
+	return errors.Is(err, ErrEncryptedCompare), nil
+}
+
+func positiveNewEncryptedDisabled(_ *testing.T) (interface{}, error) {
+
+	stub := &keyValueRepoStub{}
+	kv := NewEncrypted(stub, envelope.New(env.EncryptionConfig{}))
+
+	return kv == domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](stub), nil
+}
+
+func newTestEnvelope() *envelope.Envelope {
+
+	key := tool.LoadPrivateKey("../../../tool/test_private-key.pem")
+
+	return envelope.New(env.EncryptionConfig{
+		Enabled:     true,
+		Prefixes:    []string{"/secrets/"},
+		PrivateKeys: []*rsa.PrivateKey{key},
+		PublicKey:   &key.PublicKey,
+	})
+}
+
+// keyValueRepoStub хранилище одного значения, возвращаемого при любом чтении.
+type keyValueRepoStub struct {
+	key   string
+	value string
+}
+
+func (s *keyValueRepoStub) Do(
+	_ context.Context,
+	action domain.Actioner[*entity.KeyValue, entity.KeyValue],
+	unit entity.KeyValue,
+	scan func(domain.Scanner) entity.KeyValue,
+) (entity.KeyValue, error) {
+
+	if action.Name() == domain.UpsertAction {
+		s.key, s.value = unit.Key(), unit.Value()
+	}
+	return scan(keyValueScanner{key: s.key, value: s.value, version: 1}), nil
+}
+
+func (s *keyValueRepoStub) Get(
+	_ context.Context,
+	_ domain.Actioner[*entity.KeyValue, entity.KeyValue],
+	_ entity.KeyValue,
+	scan func(domain.Scanner) entity.KeyValue,
+) ([]entity.KeyValue, error) {
+	return []entity.KeyValue{scan(keyValueScanner{key: s.key, value: s.value, version: 1})}, nil
+}
+
+func (s *keyValueRepoStub) Transaction(
+	_ context.Context,
+	_ domain.TransactionalAction,
+	_ domain.Txn[entity.KeyValue],
+) (bool, error) {
+	return true, nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
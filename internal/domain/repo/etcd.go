@@ -17,8 +17,11 @@ import (
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/envelope"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/tracing"
 	"github.com/victor-skurikhin/etcd-client/v1/pool"
 	"github.com/victor-skurikhin/etcd-client/v1/pool/etcd_pool"
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientV3 "go.etcd.io/etcd/client/v3"
 	"log/slog"
 	"sync"
@@ -29,6 +32,7 @@ var _ domain.Repo[domain.Actioner[*domain.Entity, domain.Entity], *domain.Entity
 var (
 	onceKeyValueEtcd = new(sync.Once)
 	etcdKeyValueInst *Etcd[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
+	etcdKeyValueRepo domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
 )
 
 type Etcd[A domain.Actioner[T, U], T domain.Ptr[U], U domain.Entity] struct {
@@ -50,15 +54,18 @@ func GetKeyValueEtcdRepo(
 ) domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue] {
 	onceKeyValueEtcd.Do(func() {
 		etcdKeyValueInst = new(Etcd[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue])
-		etcdKeyValueInst.pool = etcd_pool.GetEtcdPool(cfg)
+		etcdKeyValueInst.pool = etcd_pool.GetPool(cfg)
 		etcdKeyValueInst.sLog = cfg.Logger()
+		etcdKeyValueRepo = NewEncrypted(etcdKeyValueInst, envelope.New(cfg.EncryptionConfig()))
 	})
-	return etcdKeyValueInst
+	return etcdKeyValueRepo
 }
 
-func (e Etcd[A, T, U]) Do(ctx context.Context, action A, unit U, scan func(domain.Scanner) U) (U, error) {
+func (e Etcd[A, T, U]) Do(ctx context.Context, action A, unit U, scan func(domain.Scanner) U) (result U, err error) {
 
-	client, err := e.pool.AcquireClient()
+	ctx, span := tracing.StartDB(ctx, tracing.DBSystemEtcd, action.Name(), tracing.KeyAttribute.String(unit.Key()))
+	defer func() { tracing.End(span, err) }()
+	client, err := e.pool.AcquireClient(ctx)
 
 	if err != nil {
 		return unit, EtcdError{err: err}
@@ -66,19 +73,25 @@ func (e Etcd[A, T, U]) Do(ctx context.Context, action A, unit U, scan func(domai
 	defer func() { _ = e.pool.ReleaseClient(client) }()
 
 	switch action.Name() {
+	case domain.CompareAndDeleteAction:
+		return e.compareAndDelete(ctx, client, action, unit, scan)
+	case domain.CompareAndSwapAction:
+		return e.compareAndSwap(ctx, client, action, unit, scan)
 	case domain.DeleteAction:
-		return e.delete(ctx, client, unit)
+		return e.delete(ctx, client, unit, scan)
 	case domain.SelectAction:
 		return e.get(ctx, client, unit, scan)
 	case domain.UpsertAction:
-		return e.put(ctx, client, unit, action.Args(unit)...)
+		return e.put(ctx, client, unit, scan, action.Args(unit)...)
 	}
 	return unit, EtcdError{err: fmt.Errorf("unknown action, name: %s", action.Name())}
 }
 
-func (e Etcd[A, T, U]) Get(ctx context.Context, action A, unit U, scan func(domain.Scanner) U) ([]U, error) {
+func (e Etcd[A, T, U]) Get(ctx context.Context, action A, unit U, scan func(domain.Scanner) U) (result []U, err error) {
 
-	client, err := e.pool.AcquireClient()
+	ctx, span := tracing.StartDB(ctx, tracing.DBSystemEtcd, action.Name(), tracing.KeyAttribute.String(unit.Key()))
+	defer func() { tracing.End(span, err) }()
+	client, err := e.pool.AcquireClient(ctx)
 
 	if err != nil {
 		return nil, EtcdError{err: err}
@@ -88,17 +101,134 @@ func (e Etcd[A, T, U]) Get(ctx context.Context, action A, unit U, scan func(doma
 	switch action.Name() {
 	case domain.GetAllAction:
 		return e.getAll(ctx, client, "\x00", scan)
+	case domain.ListAction:
+		return e.list(ctx, client, action, unit, scan)
 	case domain.SelectAction:
 		return e.getAll(ctx, client, unit.Key(), scan)
 	}
 	return nil, EtcdError{err: fmt.Errorf("unknown action, name: %s", action.Name())}
 }
 
-func (e Etcd[A, T, U]) delete(ctx context.Context, client clientV3.KV, unit U) (U, error) {
+// Transaction выполняет txn как одну транзакцию etcd (clientV3.Txn).
+func (e Etcd[A, T, U]) Transaction(ctx context.Context, action domain.TransactionalAction, txn domain.Txn[U]) (succeeded bool, err error) {
 
-	if resp, err := client.Delete(ctx, unit.Key()); err != nil {
+	ctx, span := tracing.StartDB(ctx, tracing.DBSystemEtcd, action.Name())
+	defer func() { tracing.End(span, err) }()
+	client, err := e.pool.AcquireClient(ctx)
+
+	if err != nil {
+		return false, EtcdError{err: err}
+	}
+	defer func() { _ = e.pool.ReleaseClient(client) }()
+	compares := make([]clientV3.Cmp, 0, len(txn.Compare))
+
+	for _, compare := range txn.Compare {
+		compares = append(compares, etcdCompare(compare))
+	}
+	success, err := etcdTxnOps(action, txn.Success)
+
+	if err != nil {
+		return false, err
+	}
+	failure, err := etcdTxnOps(action, txn.Failure)
+
+	if err != nil {
+		return false, err
+	}
+	resp, err := client.Txn(ctx).If(compares...).Then(success...).Else(failure...).Commit()
+
+	if err != nil {
+		return false, EtcdError{err: err, info: resp}
+	}
+	return resp.Succeeded, nil
+}
+
+func (e Etcd[A, T, U]) compareAndDelete(ctx context.Context, client clientV3.KV, action A, unit U, scan func(domain.Scanner) U) (U, error) {
+
+	key := unit.Key()
+	resp, err := client.Txn(ctx).
+		If(clientV3.Compare(clientV3.Version(key), "=", expectedVersion(action))).
+		Then(clientV3.OpGet(key), clientV3.OpDelete(key)).
+		Else(clientV3.OpGet(key)).
+		Commit()
+
+	if err != nil {
 		return unit, EtcdError{err: err, info: resp}
 	}
+	return e.txnResult(resp, unit, scan)
+}
+
+func (e Etcd[A, T, U]) compareAndSwap(ctx context.Context, client clientV3.KV, action A, unit U, scan func(domain.Scanner) U) (U, error) {
+
+	args := action.Args(unit)
+
+	if len(args) < 2 {
+		return unit, EtcdError{err: fmt.Errorf("no required parameters, length: %d", len(args))}
+	}
+	value, ok := args[1].(string)
+
+	if !ok {
+		return unit, EtcdError{err: fmt.Errorf(
+			"second argument for scanner is not pointer to string, type: %T", args[1],
+		)}
+	}
+	var opts []clientV3.OpOption
+
+	if leaser, ok := any(action).(domain.Leaser); ok && leaser.Lease() != 0 {
+		opts = append(opts, clientV3.WithLease(clientV3.LeaseID(leaser.Lease())))
+	}
+	key := unit.Key()
+	resp, err := client.Txn(ctx).
+		If(clientV3.Compare(clientV3.Version(key), "=", expectedVersion(action))).
+		Then(clientV3.OpPut(key, value, opts...), clientV3.OpGet(key)).
+		Else(clientV3.OpGet(key)).
+		Commit()
+
+	if err != nil {
+		return unit, EtcdError{err: err, info: resp}
+	}
+	return e.txnResult(resp, unit, scan)
+}
+
+// txnResult результат условной транзакции: при несовпадении версии
+// возвращается ErrVersionMismatch, в info — текущая версия ключа.
+func (e Etcd[A, T, U]) txnResult(resp *clientV3.TxnResponse, unit U, scan func(domain.Scanner) U) (U, error) {
+
+	var kvs []*mvccpb.KeyValue
+
+	for _, op := range resp.Responses {
+		if r := op.GetResponseRange(); r != nil {
+			kvs = r.Kvs
+		}
+	}
+	if !resp.Succeeded {
+		var current int64
+		if len(kvs) > 0 {
+			current = kvs[0].Version
+		}
+		return unit, EtcdError{err: domain.ErrVersionMismatch, info: current}
+	}
+	if len(kvs) < 1 {
+		return unit, nil
+	}
+	return scan(keyValueScanner{
+		key:      string(kvs[0].Key),
+		revision: resp.Header.GetRevision(),
+		value:    string(kvs[0].Value),
+		version:  kvs[0].Version,
+	}), nil
+}
+
+// delete ревизия удаления передаётся в scan как domain.Revisioner.
+func (e Etcd[A, T, U]) delete(ctx context.Context, client clientV3.KV, unit U, scan func(domain.Scanner) U) (U, error) {
+
+	resp, err := client.Delete(ctx, unit.Key())
+
+	if err != nil {
+		return unit, EtcdError{err: err, info: resp}
+	}
+	scan(keyValueScanner{key: unit.Key(), revision: resp.Header.GetRevision()})
+
 	return unit, nil
 }
 
@@ -110,7 +240,7 @@ func (e Etcd[A, T, U]) get(ctx context.Context, client clientV3.KV, unit U, scan
 		return unit, EtcdError{err: err, info: got}
 	}
 	if len(got.Kvs) < 1 {
-		return unit, EtcdError{err: fmt.Errorf("no Kvs, length: %d", len(got.Kvs))}
+		return unit, EtcdError{err: fmt.Errorf("no Kvs, length: %d: %w", len(got.Kvs), domain.ErrNotFound)}
 	}
 
 	return scan(keyValueScanner{
@@ -136,18 +266,65 @@ func (e Etcd[A, T, U]) getAll(ctx context.Context, client clientV3.KV, key strin
 	return result, nil
 }
 
-func (e Etcd[A, T, U]) put(ctx context.Context, client clientV3.KV, unit U, args ...any) (U, error) {
+func (e Etcd[A, T, U]) list(ctx context.Context, client clientV3.KV, action A, unit U, scan func(domain.Scanner) U) ([]U, error) {
+
+	key := unit.Key()
+	opts := make([]clientV3.OpOption, 0, 4)
+
+	if revisioner, ok := any(action).(domain.Revisioner); ok && revisioner.Revision() > 0 {
+		opts = append(opts, clientV3.WithRev(revisioner.Revision()))
+	}
+	if pager, ok := any(action).(domain.Pager); ok {
+		if from := pager.From(); from > key {
+			// Курсор внутри диапазона префикса: [from, конец префикса).
+			opts = append(opts, clientV3.WithRange(clientV3.GetPrefixRangeEnd(key)))
+			key = from
+		} else {
+			opts = append(opts, clientV3.WithPrefix())
+		}
+		if pager.Limit() > 0 {
+			opts = append(opts, clientV3.WithLimit(pager.Limit()))
+		}
+		if pager.KeysOnly() {
+			opts = append(opts, clientV3.WithKeysOnly())
+		}
+	} else {
+		opts = append(opts, clientV3.WithPrefix())
+	}
+	got, err := client.Get(ctx, key, opts...)
+
+	if err != nil {
+		return nil, EtcdError{err: err, info: got}
+	}
+	result := make([]U, 0, len(got.Kvs))
+
+	for _, kv := range got.Kvs {
+		u := scan(keyValueScanner{key: string(kv.Key), value: string(kv.Value), version: kv.Version})
+		result = append(result, u)
+	}
+	return result, nil
+}
+
+// put ревизия записи передаётся в scan как domain.Revisioner.
+func (e Etcd[A, T, U]) put(ctx context.Context, client clientV3.KV, unit U, scan func(domain.Scanner) U, args ...any) (U, error) {
 
 	if len(args) < 2 {
 		return unit, EtcdError{err: fmt.Errorf("no required parameters, length: %d", len(args))}
 	}
-	if s, ok := args[1].(string); !ok {
+	s, ok := args[1].(string)
+
+	if !ok {
 		return unit, EtcdError{err: fmt.Errorf(
 			"second argument for scanner is not pointer to string, type: %T", args[1],
 		)}
-	} else if resp, err := client.Put(ctx, unit.Key(), s); err != nil {
+	}
+	resp, err := client.Put(ctx, unit.Key(), s)
+
+	if err != nil {
 		return unit, EtcdError{err: err, info: resp}
 	}
+	scan(keyValueScanner{key: unit.Key(), value: s, revision: resp.Header.GetRevision()})
+
 	return unit, nil
 }
 
@@ -163,14 +340,80 @@ func (s EtcdError) Info() interface{} {
 	return s.info
 }
 
+func (s EtcdError) Unwrap() error {
+	return s.err
+}
+
 func (s ScannerError) Error() string {
 	return s.err.Error()
 }
 
+func etcdCompare(compare domain.TxCompare) clientV3.Cmp {
+
+	var result string
+
+	switch compare.Result {
+	case domain.CompareGreater:
+		result = ">"
+	case domain.CompareLess:
+		result = "<"
+	case domain.CompareNotEqual:
+		result = "!="
+	default:
+		result = "="
+	}
+	if compare.Target == domain.CompareTargetValue {
+		return clientV3.Compare(clientV3.Value(compare.Key), result, compare.Value)
+	}
+	return clientV3.Compare(clientV3.Version(compare.Key), result, compare.Version)
+}
+
+func etcdTxnOps[U domain.Entity](action domain.TransactionalAction, ops []domain.TxOp[U]) ([]clientV3.Op, error) {
+
+	result := make([]clientV3.Op, 0, len(ops))
+
+	for _, op := range ops {
+		switch op.Name {
+		case domain.DeleteAction:
+			result = append(result, clientV3.OpDelete(op.Unit.Key()))
+		case domain.UpsertAction:
+			txArgs := action.UpsertTxArgs(op.Unit)
+
+			if len(txArgs.Args) < 1 || len(txArgs.Args[0]) < 2 {
+				return nil, EtcdError{err: fmt.Errorf("no required parameters for key: %s", op.Unit.Key())}
+			}
+			value, ok := txArgs.Args[0][1].(string)
+
+			if !ok {
+				return nil, EtcdError{err: fmt.Errorf(
+					"second argument is not string, type: %T", txArgs.Args[0][1],
+				)}
+			}
+			result = append(result, clientV3.OpPut(op.Unit.Key(), value))
+		default:
+			return nil, EtcdError{err: fmt.Errorf("unknown transaction operation, name: %s", op.Name)}
+		}
+	}
+	return result, nil
+}
+
+func expectedVersion(action any) int64 {
+
+	if comparer, ok := action.(domain.Comparer); ok {
+		return comparer.ExpectedVersion()
+	}
+	return 0
+}
+
 type keyValueScanner struct {
-	key     string
-	value   string
-	version int64
+	key      string
+	revision int64
+	value    string
+	version  int64
+}
+
+func (v keyValueScanner) Revision() int64 {
+	return v.revision
 }
 
 func (v keyValueScanner) Scan(dest ...any) error {
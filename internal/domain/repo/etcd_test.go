@@ -105,6 +105,11 @@ func TestEtcd(t *testing.T) {
 			negativeEtcdGet2,
 			negativeEtcdGet2Check,
 		},
+		{
+			"test #13 positive #3 for struct Etcd method Get(context.Context, A, U, func(domain.Scanner))",
+			positiveEtcdGet3,
+			positiveEtcdGet2Check,
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			got, err := test.fRun(t)
@@ -139,7 +144,7 @@ func positiveEtcdDo1(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 
@@ -172,7 +177,7 @@ func positiveEtcdDo2(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 
@@ -209,7 +214,7 @@ func positiveEtcdDo3(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 
@@ -242,7 +247,7 @@ func negativeEtcdDo1(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(nil, fmt.Errorf("connectivity state: INVALID_STATE")).
 		AnyTimes()
 
@@ -278,7 +283,7 @@ func negativeEtcdDo2(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 
@@ -313,7 +318,7 @@ func negativeEtcdDoScanner3(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 
@@ -353,7 +358,7 @@ func negativeEtcdDoScanner4(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 
@@ -395,7 +400,7 @@ func negativeEtcdDoScanner5(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 
@@ -437,7 +442,7 @@ func negativeEtcdDoScanner6(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 
@@ -479,7 +484,7 @@ func positiveEtcdGet1(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 
@@ -515,7 +520,7 @@ func positiveEtcdGet2(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 
@@ -544,6 +549,36 @@ func positiveEtcdGet2Check(t *testing.T, i interface{}) bool {
 	return false
 }
 
+func positiveEtcdGet3(t *testing.T) (interface{}, error) {
+
+	ctrl := gomock.NewController(t)
+	etcdPoolMock := NewMockEtcdPool(ctrl)
+	etcdKeyValueInst = newTestKeyValueEtcdRepo(etcdPoolMock, slog.Default())
+
+	etcdPoolMock.
+		EXPECT().
+		AcquireClient(gomock.Any()).
+		Return(&kvTestStub{}, nil).
+		AnyTimes()
+
+	etcdPoolMock.
+		EXPECT().
+		ReleaseClient(gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	search := entity.MakeKeyValue("key", "", 0, entity.DefaultTAttributes())
+	expected := entity.MakeKeyValue("key1", "value1", 1, entity.DefaultTAttributes())
+	ctx := context.Background()
+	result, err := etcdKeyValueInst.Get(ctx, entity.MakeKeyValueList("key1", 10, false), search, func(domain.Scanner) entity.KeyValue {
+		return expected
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	return result, nil
+}
+
 func negativeEtcdGet1(t *testing.T) (interface{}, error) {
 
 	ctrl := gomock.NewController(t)
@@ -552,7 +587,7 @@ func negativeEtcdGet1(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(nil, fmt.Errorf("connectivity state: INVALID_STATE")).
 		AnyTimes()
 
@@ -588,7 +623,7 @@ func negativeEtcdGet2(t *testing.T) (interface{}, error) {
 
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 
@@ -113,7 +113,7 @@ func etcdDeleteSelect(t *testing.T, ctx context.Context) bool {
 	result, err = etcdRepo.Do(ctx, IDValueSelect, expected, etcdScan)
 	assert.Equal(t, expected, result)
 	_, ok := err.(EtcdError)
-	assert.Equal(t, EtcdError{err: fmt.Errorf("no Kvs, length: 0")}, err)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
 	return ok
 }
 
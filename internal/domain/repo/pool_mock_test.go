@@ -10,8 +10,10 @@
 package repo
 
 import (
+	context "context"
 	reflect "reflect"
 
+	pool "github.com/victor-skurikhin/etcd-client/v1/pool"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,18 +42,18 @@ func (m *MockEtcdPool) EXPECT() *MockEtcdPoolMockRecorder {
 }
 
 // AcquireClient mocks base method.
-func (m *MockEtcdPool) AcquireClient() (clientv3.KV, error) {
+func (m *MockEtcdPool) AcquireClient(ctx context.Context) (clientv3.KV, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AcquireClient")
+	ret := m.ctrl.Call(m, "AcquireClient", ctx)
 	ret0, _ := ret[0].(clientv3.KV)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AcquireClient indicates an expected call of AcquireClient.
-func (mr *MockEtcdPoolMockRecorder) AcquireClient() *gomock.Call {
+func (mr *MockEtcdPoolMockRecorder) AcquireClient(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireClient", reflect.TypeOf((*MockEtcdPool)(nil).AcquireClient))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireClient", reflect.TypeOf((*MockEtcdPool)(nil).AcquireClient), ctx)
 }
 
 // GracefulClose mocks base method.
@@ -81,3 +83,17 @@ func (mr *MockEtcdPoolMockRecorder) ReleaseClient(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseClient", reflect.TypeOf((*MockEtcdPool)(nil).ReleaseClient), arg0)
 }
+
+// Stats mocks base method.
+func (m *MockEtcdPool) Stats() pool.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(pool.Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockEtcdPoolMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockEtcdPool)(nil).Stats))
+}
@@ -12,15 +12,20 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/envelope"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/tracing"
 	"log/slog"
 	"sync"
 	"time"
+
+	semConv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 const (
@@ -33,7 +38,10 @@ var _ domain.Repo[domain.Actioner[*domain.Entity, domain.Entity], *domain.Entity
 var (
 	ErrBadPool       = fmt.Errorf("bad Database pool")
 	onceKeyValueRepo = new(sync.Once)
+	onceOutboxRepo   = new(sync.Once)
 	repoKeyValueInst *Postgres[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
+	repoKeyValue     domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
+	repoOutboxInst   *Postgres[domain.Actioner[*entity.Outbox, entity.Outbox], *entity.Outbox, entity.Outbox]
 )
 
 type Postgres[A domain.Actioner[T, U], T domain.Ptr[U], U domain.Entity] struct {
@@ -53,23 +61,56 @@ func GetKeyValuePostgresRepo(
 		repoKeyValueInst = new(Postgres[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue])
 		repoKeyValueInst.pool = cfg.DBPool()
 		repoKeyValueInst.sLog = cfg.Logger()
+		repoKeyValue = NewEncrypted(repoKeyValueInst, envelope.New(cfg.EncryptionConfig()))
+	})
+	return repoKeyValue
+}
+
+// GetOutboxPostgresRepo репозиторий событий outbox записей key_value.
+func GetOutboxPostgresRepo(
+	cfg env.Config,
+) domain.Repo[domain.Actioner[*entity.Outbox, entity.Outbox], *entity.Outbox, entity.Outbox] {
+	onceOutboxRepo.Do(func() {
+		repoOutboxInst = new(Postgres[domain.Actioner[*entity.Outbox, entity.Outbox], *entity.Outbox, entity.Outbox])
+		repoOutboxInst.pool = cfg.DBPool()
+		repoOutboxInst.sLog = cfg.Logger()
 	})
-	return repoKeyValueInst
+	return repoOutboxInst
 }
 
-func (p Postgres[A, T, U]) Do(ctx context.Context, action A, unit U, scan func(domain.Scanner) U) (U, error) {
+func (p Postgres[A, T, U]) Do(ctx context.Context, action A, unit U, scan func(domain.Scanner) U) (result U, err error) {
+
+	ctx, span := tracing.StartDB(ctx, tracing.DBSystemPostgres, action.Name(),
+		tracing.KeyAttribute.String(unit.Key()),
+		semConv.DBQueryText(action.SQL()),
+	)
+	defer func() { tracing.End(span, err) }()
 
+	if outboxer, ok := any(action).(domain.Outboxer); ok {
+		return p.doOutbox(ctx, action, outboxer, unit, scan)
+	}
 	row, err := rowPostgreSQL(ctx, p.sLog, p.pool, action.SQL(), action.Args(unit)...)
 
 	if err != nil {
 		return unit, PostgresError{err: err}
 	}
+	if _, ok := any(action).(domain.Comparer); ok {
+		return scan(comparerScanner{row: row}), nil
+	}
+	if required(action) {
+		return scan(requiredScanner{row: row}), nil
+	}
 	return scan(row), nil
 }
 
-func (p Postgres[A, T, U]) Get(ctx context.Context, action A, unit U, scan func(domain.Scanner) U) ([]U, error) {
+func (p Postgres[A, T, U]) Get(ctx context.Context, action A, unit U, scan func(domain.Scanner) U) (result []U, err error) {
 
-	result := make([]U, 0)
+	ctx, span := tracing.StartDB(ctx, tracing.DBSystemPostgres, action.Name(),
+		tracing.KeyAttribute.String(unit.Key()),
+		semConv.DBQueryText(action.SQL()),
+	)
+	defer func() { tracing.End(span, err) }()
+	result = make([]U, 0)
 	rows, err := rowsPostgreSQL(ctx, p.sLog, p.pool, action.SQL(), action.Args(unit)...)
 
 	if err != nil {
@@ -82,6 +123,107 @@ func (p Postgres[A, T, U]) Get(ctx context.Context, action A, unit U, scan func(
 	return result, nil
 }
 
+// Transaction выполняет txn в одной транзакции PostgreSQL (pgx.Tx):
+// условия проверяются с блокировкой строк, затем выполняются операции
+// ветви Success или Failure.
+func (p Postgres[A, T, U]) Transaction(ctx context.Context, action domain.TransactionalAction, txn domain.Txn[U]) (succeeded bool, err error) {
+
+	ctx, span := tracing.StartDB(ctx, tracing.DBSystemPostgres, action.Name())
+	defer func() { tracing.End(span, err) }()
+
+	if p.pool == nil {
+		return false, ErrBadPool
+	}
+	tx, err := p.pool.Begin(ctx)
+
+	if err != nil {
+		return false, PostgresError{err: err}
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	compares := make([]any, 0, len(txn.Compare))
+
+	for _, compare := range txn.Compare {
+		compares = append(compares, compare)
+	}
+	succeeded = true
+	compareTxArgs := action.CompareTxArgs(compares...)
+
+	for i, sql := range compareTxArgs.SQLs {
+		if err = tx.QueryRow(ctx, sql, compareTxArgs.Args[i]...).Scan(&succeeded); err != nil {
+			return false, PostgresError{err: err}
+		}
+		if !succeeded {
+			break
+		}
+	}
+	ops := txn.Success
+
+	if !succeeded {
+		ops = txn.Failure
+	}
+	opsTxArgs, err := postgresTxOps(action, ops)
+
+	if err != nil {
+		return false, err
+	}
+	if outboxer, ok := action.(domain.Outboxer); ok {
+		opsTxArgs = opsTxArgs.Append(outboxer.OutboxTxArgs(txOpsUnits(ops)...))
+	}
+	for i, sql := range opsTxArgs.SQLs {
+		if _, err = tx.Exec(ctx, sql, opsTxArgs.Args[i]...); err != nil {
+			return false, PostgresError{err: err}
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return false, PostgresError{err: err}
+	}
+	return succeeded, nil
+}
+
+// doOutbox выполняет действие и добавляет события outbox в одной
+// транзакции PostgreSQL (pgx.Tx): при ошибке действия транзакция
+// откатывается и события не сохраняются.
+func (p Postgres[A, T, U]) doOutbox(ctx context.Context, action A, outboxer domain.Outboxer, unit U, scan func(domain.Scanner) U) (U, error) {
+
+	if p.pool == nil {
+		return unit, ErrBadPool
+	}
+	tx, err := p.pool.Begin(ctx)
+
+	if err != nil {
+		return unit, PostgresError{err: err}
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var row pgx.Row = tx.QueryRow(ctx, action.SQL(), action.Args(unit)...)
+
+	if _, ok := any(action).(domain.Comparer); ok {
+		row = comparerScanner{row: row}
+	} else if required(action) {
+		row = requiredScanner{row: row}
+	}
+	scanner := &errScanner{row: row}
+	result := scan(scanner)
+
+	if errors.Is(scanner.err, pgx.ErrNoRows) {
+		// Действие не изменило ни одной записи, событие не нужно.
+		return result, nil
+	}
+	if scanner.err != nil {
+		return result, scanner.err
+	}
+	outboxTxArgs := outboxer.OutboxTxArgs(unit)
+
+	for i, sql := range outboxTxArgs.SQLs {
+		if _, err = tx.Exec(ctx, sql, outboxTxArgs.Args[i]...); err != nil {
+			return result, PostgresError{err: err}
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return result, PostgresError{err: err}
+	}
+	return result, nil
+}
+
 func (s PostgresError) Error() string {
 	return s.err.Error()
 }
@@ -94,6 +236,82 @@ func (s PostgresError) Info() interface{} {
 	return s.info
 }
 
+func postgresTxOps[U domain.Entity](action domain.TransactionalAction, ops []domain.TxOp[U]) (domain.TxArgs, error) {
+
+	var result domain.TxArgs
+
+	for _, op := range ops {
+		switch op.Name {
+		case domain.DeleteAction:
+			result = result.Append(action.DeleteTxArgs(op.Unit))
+		case domain.UpsertAction:
+			result = result.Append(action.UpsertTxArgs(op.Unit))
+		default:
+			return result, PostgresError{err: fmt.Errorf("unknown transaction operation, name: %s", op.Name)}
+		}
+	}
+	return result, nil
+}
+
+// comparerScanner условная операция не вернула строку: версия записи
+// не совпала с ожидаемой.
+type comparerScanner struct {
+	row pgx.Row
+}
+
+func (c comparerScanner) Scan(dest ...any) error {
+
+	if err := c.row.Scan(dest...); errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrVersionMismatch
+	} else {
+		return err
+	}
+}
+
+// requiredScanner действие domain.Requirer не нашло подходящей записи.
+type requiredScanner struct {
+	row pgx.Row
+}
+
+func (r requiredScanner) Scan(dest ...any) error {
+
+	if err := r.row.Scan(dest...); errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrNotFound
+	} else {
+		return err
+	}
+}
+
+func required(action any) bool {
+
+	if requirer, ok := action.(domain.Requirer); ok {
+		return requirer.Required()
+	}
+	return false
+}
+
+// errScanner запоминает ошибку сканирования строки, чтобы не
+// фиксировать транзакцию после неудачного действия.
+type errScanner struct {
+	err error
+	row pgx.Row
+}
+
+func (e *errScanner) Scan(dest ...any) error {
+	e.err = e.row.Scan(dest...)
+	return e.err
+}
+
+func txOpsUnits[U domain.Entity](ops []domain.TxOp[U]) []any {
+
+	result := make([]any, 0, len(ops))
+
+	for _, op := range ops {
+		result = append(result, op.Unit)
+	}
+	return result
+}
+
 func rowPostgreSQL(
 	ctx context.Context,
 	log *slog.Logger,
@@ -11,6 +11,7 @@
 package env
 
 import (
+	"crypto/rsa"
 	"crypto/tls"
 	"fmt"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -24,18 +25,27 @@ import (
 )
 
 const (
+	propertyAuthConfig               = "auth-config"
+	propertyCacheConfig              = "cache-config"
 	propertyCacheExpireMs            = "cache-expire"
 	propertyCacheGCIntervalSec       = "cache-gc-interval"
 	propertyDBPool                   = "db-pool"
 	propertyDebug                    = "debug"
+	propertyEncryptionConfig         = "encryption-config"
 	propertyEnvironments             = "environments"
 	propertyEtcdClientConfig         = "etcd-proxy-config"
+	propertyEtcdPoolConfig           = "etcd-pool-config"
 	propertyFlags                    = "flags"
 	propertyGRPCAddress              = "grpc-address"
 	propertyGRPCTransportCredentials = "grpc-transport-credentials"
 	propertyHTTPAddress              = "http-address"
 	propertyHTTPHTTPTLSConfig        = "http-tls-yamlConfig"
 	propertyLogger                   = "logger"
+	propertyOutboxConfig             = "outbox-config"
+	propertyPurgeConfig              = "purge-config"
+	propertyRBACConfig               = "rbac-config"
+	propertyReconcileConfig          = "reconcile-config"
+	propertyTracingConfig            = "tracing-config"
 	propertyYamlConfig               = "yamlConfig"
 	MSG                              = "etcd-proxy "
 )
@@ -43,22 +53,158 @@ const (
 // Config конфигурация собранная из Yaml-файла, переменных окружения и флагов командной строки.
 type Config interface {
 	fmt.Stringer
+	AuthConfig() AuthConfig
+	CacheConfig() CacheConfig
 	CacheExpire() time.Duration
 	CacheGCInterval() time.Duration
 	DBPool() *pgxpool.Pool
 	Debug() bool
+	EncryptionConfig() EncryptionConfig
 	Environments() environments
 	EtcdClientConfig() *clientv3.Config
+	EtcdPoolConfig() PoolConfig
 	Flags() map[string]interface{}
 	GRPCAddress() string
 	GRPCTransportCredentials() credentials.TransportCredentials
 	HTTPAddress() string
 	HTTPTLSConfig() *tls.Config
 	Logger() *slog.Logger
+	OutboxConfig() OutboxConfig
+	PurgeConfig() PurgeConfig
+	RBACConfig() RBACConfig
+	ReconcileConfig() ReconcileConfig
 	SlogJSON() bool
+	TracingConfig() TracingConfig
 	YamlConfig() YamlConfig
 }
 
+// AuthConfig настройки проверки подлинности клиентов HTTP и gRPC.
+// APIKeys — имена клиентов по SHA-256 (hex) их API-ключей,
+// JWTPublicKeys — публичные RSA-ключи для проверки подписи JWT.
+type AuthConfig struct {
+	APIKeys       map[string]string
+	Enabled       bool
+	JWTAudience   string
+	JWTIssuer     string
+	JWTPublicKeys []*rsa.PublicKey
+}
+
+// String без ключей, чтобы настройки можно было писать в журнал.
+func (a AuthConfig) String() string {
+	return fmt.Sprintf(
+		"{APIKeys:%d Enabled:%v JWTAudience:%s JWTIssuer:%s JWTPublicKeys:%d}",
+		len(a.APIKeys), a.Enabled, a.JWTAudience, a.JWTIssuer, len(a.JWTPublicKeys),
+	)
+}
+
+// Политики вытеснения записей из локального кэша: CachePolicyLRU —
+// ограниченный по количеству и объёму записей кэш с вытеснением давно
+// не читавшихся, CachePolicyTTL — неограниченный кэш с удалением только
+// по сроку действия.
+const (
+	CachePolicyLRU = "lru"
+	CachePolicyTTL = "ttl"
+)
+
+// CacheConfig настройки локального кэша: MaxBytes — предельный объём
+// ключей и значений в байтах, MaxEntries — предельное количество записей,
+// ноль — без ограничения. NegativeExpire — срок действия записей кэша
+// отсутствующих ключей, ноль — отсутствующие ключи не кэшируются.
+// NegativeWatch — подписка на запись всех ключей etcd для удаления из
+// этого кэша ключей, созданных в обход экземпляра: события подписки
+// несут значения всех записываемых ключей, поэтому без неё такой ключ
+// остаётся отсутствующим до истечения NegativeExpire.
+type CacheConfig struct {
+	MaxBytes       int64
+	MaxEntries     int
+	NegativeExpire time.Duration
+	NegativeWatch  bool
+	Policy         string
+}
+
+// EncryptionConfig настройки шифрования значений ключей, начинающихся
+// с Prefixes: значение шифруется ключом данных AES-256-GCM, который
+// шифруется открытым RSA-ключом PublicKey. PrivateKeys — закрытые ключи
+// для расшифровки: первый — пара к PublicKey, остальные — предыдущие,
+// нужные, пока ими зашифрованы значения в истории и удалённых записях.
+type EncryptionConfig struct {
+	Enabled     bool
+	Prefixes    []string
+	PrivateKeys []*rsa.PrivateKey
+	PublicKey   *rsa.PublicKey
+}
+
+// String без ключей, чтобы настройки можно было писать в журнал.
+func (e EncryptionConfig) String() string {
+	return fmt.Sprintf(
+		"{Enabled:%v Prefixes:%v PrivateKeys:%d PublicKey:%v}",
+		e.Enabled, e.Prefixes, len(e.PrivateKeys), e.PublicKey != nil,
+	)
+}
+
+// OutboxConfig настройки доставки в etcd записей через transactional outbox.
+type OutboxConfig struct {
+	BatchSize int
+	Enabled   bool
+	Interval  time.Duration
+	Lease     time.Duration
+}
+
+// PoolConfig настройки пула клиентов etcd.
+type PoolConfig struct {
+	Enabled        bool
+	HealthInterval time.Duration
+	MaxIdle        int
+	MaxOpen        int
+	MinIdle        int
+}
+
+// PurgeConfig настройки фонового удаления из PostgreSQL записей,
+// помеченных удалёнными (tombstone) ранее чем Retention назад.
+type PurgeConfig struct {
+	BatchSize int
+	Enabled   bool
+	Interval  time.Duration
+	Retention time.Duration
+}
+
+// RBACConfig настройки проверки прав доступа к ключам: Roles — правила
+// ролей по именам, Bindings — имена ролей по клиенту (Principal) вида метод:имя.
+// При Postgres роли и назначения дополняются из таблиц rbac_role_rule
+// и rbac_binding, перечитываемых каждые RefreshInterval.
+type RBACConfig struct {
+	Bindings        map[string][]string
+	Enabled         bool
+	Postgres        bool
+	RefreshInterval time.Duration
+	Roles           map[string][]RBACRule
+}
+
+// RBACRule правило роли: действия (read, write, delete, watch),
+// разрешённые для ключей, начинающихся с Prefix.
+type RBACRule struct {
+	Actions []string
+	Prefix  string
+}
+
+// ReconcileConfig настройки фоновой сверки записей etcd и PostgreSQL.
+type ReconcileConfig struct {
+	Enabled       bool
+	Interval      time.Duration
+	Prefix        string
+	Repair        bool
+	SourceOfTruth string
+}
+
+// TracingConfig настройки экспорта трассировки OpenTelemetry по OTLP/gRPC.
+type TracingConfig struct {
+	Enabled     bool
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
+	ServiceName string
+}
+
 type mapProperties struct {
 	mp sync.Map
 }
@@ -79,6 +225,11 @@ func GetConfig() Config {
 
 		p := preparer{env: env, flagMap: flm, yml: yml}
 
+		authConfig, err := p.getAuthConfig()
+		slog.Info(MSG+"GetConfig", "authConfig", authConfig, "err", err)
+
+		cacheConfig, err := p.getCacheConfig()
+		slog.Info(MSG+"GetConfig", "cacheConfig", cacheConfig, "err", err)
 		cacheExpire, err := p.getCacheExpire()
 		slog.Debug(MSG+"GetConfig", "cacheExpire", cacheExpire, "err", err)
 		cacheGCInterval, err := p.getCacheGCInterval()
@@ -91,6 +242,20 @@ func GetConfig() Config {
 		slog.Info(MSG+"GetConfig", "etcdAddresses", etcdAddresses, "err", err)
 		etcdDialTimeout, err := p.getEtcdDialTimeout()
 		slog.Info(MSG+"GetConfig", "etcdDialTimeout", etcdDialTimeout, "err", err)
+		etcdKeepAliveTime, err := p.getEtcdKeepAliveTime()
+		slog.Info(MSG+"GetConfig", "etcdKeepAliveTime", etcdKeepAliveTime, "err", err)
+		etcdKeepAliveTimeout, err := p.getEtcdKeepAliveTimeout()
+		slog.Info(MSG+"GetConfig", "etcdKeepAliveTimeout", etcdKeepAliveTimeout, "err", err)
+		etcdUsername, etcdPassword, err := p.getEtcdCredentials()
+		slog.Info(MSG+"GetConfig", "etcdUsername", etcdUsername, "err", err)
+		encryptionConfig, err := p.getEncryptionConfig()
+		slog.Info(MSG+"GetConfig", "encryptionConfig", encryptionConfig, "err", err)
+		etcdTLSConfig, err := p.getEtcdTLSConfig()
+		slog.Info(MSG+"GetConfig", "etcdTLSConfig", etcdTLSConfig != nil, "err", err)
+		// Без TLS конфигурации клиент подключился бы к etcd открытым текстом.
+		tool.IfErrorThenPanic(p.etcdTLSRequired(err))
+		etcdPoolConfig, err := p.getEtcdPoolConfig()
+		slog.Info(MSG+"GetConfig", "etcdPoolConfig", etcdPoolConfig, "err", err)
 
 		grpcAddress, err := p.getGRPCAddress()
 		slog.Debug(MSG+"GetConfig", "grpcAddress", grpcAddress, "err", err)
@@ -102,19 +267,45 @@ func GetConfig() Config {
 		tHTTPConfig, err := p.getHTTPTLSConfig()
 		slog.Debug(MSG+"GetConfig", "tHTTPConfig", tHTTPConfig, "err", err)
 
+		outboxConfig, err := p.getOutboxConfig()
+		slog.Info(MSG+"GetConfig", "outboxConfig", outboxConfig, "err", err)
+		purgeConfig, err := p.getPurgeConfig()
+		slog.Info(MSG+"GetConfig", "purgeConfig", purgeConfig, "err", err)
+		rbacConfig, err := p.getRBACConfig()
+		slog.Info(MSG+"GetConfig", "rbacConfig", rbacConfig, "err", err)
+		reconcileConfig, err := p.getReconcileConfig()
+		slog.Info(MSG+"GetConfig", "reconcileConfig", reconcileConfig, "err", err)
+		tracingConfig, err := p.getTracingConfig()
+		slog.Info(MSG+"GetConfig", "tracingConfig", tracingConfig, "err", err)
+
 		properties = getProperties(
+			WithAuthConfig(authConfig),
+			WithCacheConfig(cacheConfig),
 			WithCacheExpire(cacheExpire),
 			WithCacheGCInterval(cacheGCInterval),
 			withDBPool(dbPool),
 			WithDebug(*flm[propertyDebug].(*bool)),
+			WithEncryptionConfig(encryptionConfig),
 			WithEnvironments(*env),
-			WithEtcdClientConfig(etcdClientConfig(etcdAddresses, etcdDialTimeout)),
+			WithEtcdClientConfig(etcdClientConfig(
+				etcdAddresses,
+				etcdDialTimeout,
+				withEtcdCredentials(etcdUsername, etcdPassword),
+				withEtcdKeepAlive(etcdKeepAliveTime, etcdKeepAliveTimeout),
+				withEtcdTLS(etcdTLSConfig),
+			)),
+			WithEtcdPoolConfig(etcdPoolConfig),
 			WithFlags(flm),
 			WithGRPCAddress(grpcAddress),
 			WithGRPCTransportCredentials(gRPCCredentials),
 			WithHTTPAddress(httpAddress),
 			WithHTTPTLSConfig(tHTTPConfig),
 			WithLogger(setupLogger(debug(flm), slogJSON(flm))),
+			WithOutboxConfig(outboxConfig),
+			WithPurgeConfig(purgeConfig),
+			WithRBACConfig(rbacConfig),
+			WithReconcileConfig(reconcileConfig),
+			WithTracingConfig(tracingConfig),
 			WithYamlConfig(yml),
 		)
 	})
@@ -123,6 +314,40 @@ func GetConfig() Config {
 	return properties
 }
 
+// WithAuthConfig — настройки проверки подлинности клиентов HTTP и gRPC.
+func WithAuthConfig(config AuthConfig) func(*mapProperties) {
+	return func(p *mapProperties) {
+		p.mp.Store(propertyAuthConfig, config)
+	}
+}
+
+// AuthConfig геттер настроек проверки подлинности клиентов HTTP и gRPC.
+func (p *mapProperties) AuthConfig() AuthConfig {
+	if c, ok := p.mp.Load(propertyAuthConfig); ok {
+		if config, ok := c.(AuthConfig); ok {
+			return config
+		}
+	}
+	return AuthConfig{}
+}
+
+// WithCacheConfig — настройки локального кэша.
+func WithCacheConfig(config CacheConfig) func(*mapProperties) {
+	return func(p *mapProperties) {
+		p.mp.Store(propertyCacheConfig, config)
+	}
+}
+
+// CacheConfig геттер настроек локального кэша.
+func (p *mapProperties) CacheConfig() CacheConfig {
+	if c, ok := p.mp.Load(propertyCacheConfig); ok {
+		if config, ok := c.(CacheConfig); ok {
+			return config
+		}
+	}
+	return CacheConfig{}
+}
+
 // WithCacheExpire — срок действия записи в кэше.
 func WithCacheExpire(cacheExpire time.Duration) func(*mapProperties) {
 	return func(p *mapProperties) {
@@ -197,6 +422,23 @@ func (p *mapProperties) Debug() bool {
 	return false
 }
 
+// WithEncryptionConfig — настройки шифрования значений ключей.
+func WithEncryptionConfig(config EncryptionConfig) func(*mapProperties) {
+	return func(p *mapProperties) {
+		p.mp.Store(propertyEncryptionConfig, config)
+	}
+}
+
+// EncryptionConfig геттер настроек шифрования значений ключей.
+func (p *mapProperties) EncryptionConfig() EncryptionConfig {
+	if c, ok := p.mp.Load(propertyEncryptionConfig); ok {
+		if config, ok := c.(EncryptionConfig); ok {
+			return config
+		}
+	}
+	return EncryptionConfig{}
+}
+
 // WithEnvironments — Окружение.
 func WithEnvironments(env environments) func(*mapProperties) {
 	return func(p *mapProperties) {
@@ -231,6 +473,23 @@ func (p *mapProperties) EtcdClientConfig() *clientv3.Config {
 	return nil
 }
 
+// WithEtcdPoolConfig — настройки пула клиентов etcd.
+func WithEtcdPoolConfig(config PoolConfig) func(*mapProperties) {
+	return func(p *mapProperties) {
+		p.mp.Store(propertyEtcdPoolConfig, config)
+	}
+}
+
+// EtcdPoolConfig геттер настроек пула клиентов etcd.
+func (p *mapProperties) EtcdPoolConfig() PoolConfig {
+	if c, ok := p.mp.Load(propertyEtcdPoolConfig); ok {
+		if config, ok := c.(PoolConfig); ok {
+			return config
+		}
+	}
+	return PoolConfig{}
+}
+
 // WithFlags — Флаги.
 func WithFlags(flags map[string]interface{}) func(*mapProperties) {
 	return func(p *mapProperties) {
@@ -345,10 +604,95 @@ func (p *mapProperties) Logger() *slog.Logger {
 	return slog.Default()
 }
 
+// WithOutboxConfig — настройки доставки в etcd записей через transactional outbox.
+func WithOutboxConfig(config OutboxConfig) func(*mapProperties) {
+	return func(p *mapProperties) {
+		p.mp.Store(propertyOutboxConfig, config)
+	}
+}
+
+// OutboxConfig геттер настроек доставки в etcd записей через transactional outbox.
+func (p *mapProperties) OutboxConfig() OutboxConfig {
+	if c, ok := p.mp.Load(propertyOutboxConfig); ok {
+		if config, ok := c.(OutboxConfig); ok {
+			return config
+		}
+	}
+	return OutboxConfig{}
+}
+
+// WithPurgeConfig — настройки фонового удаления записей, помеченных удалёнными.
+func WithPurgeConfig(config PurgeConfig) func(*mapProperties) {
+	return func(p *mapProperties) {
+		p.mp.Store(propertyPurgeConfig, config)
+	}
+}
+
+// PurgeConfig геттер настроек фонового удаления записей, помеченных удалёнными.
+func (p *mapProperties) PurgeConfig() PurgeConfig {
+	if c, ok := p.mp.Load(propertyPurgeConfig); ok {
+		if config, ok := c.(PurgeConfig); ok {
+			return config
+		}
+	}
+	return PurgeConfig{}
+}
+
+// WithRBACConfig — настройки проверки прав доступа к ключам.
+func WithRBACConfig(config RBACConfig) func(*mapProperties) {
+	return func(p *mapProperties) {
+		p.mp.Store(propertyRBACConfig, config)
+	}
+}
+
+// RBACConfig геттер настроек проверки прав доступа к ключам.
+func (p *mapProperties) RBACConfig() RBACConfig {
+	if c, ok := p.mp.Load(propertyRBACConfig); ok {
+		if config, ok := c.(RBACConfig); ok {
+			return config
+		}
+	}
+	return RBACConfig{}
+}
+
+// WithReconcileConfig — настройки фоновой сверки записей etcd и PostgreSQL.
+func WithReconcileConfig(config ReconcileConfig) func(*mapProperties) {
+	return func(p *mapProperties) {
+		p.mp.Store(propertyReconcileConfig, config)
+	}
+}
+
+// ReconcileConfig геттер настроек фоновой сверки записей etcd и PostgreSQL.
+func (p *mapProperties) ReconcileConfig() ReconcileConfig {
+	if c, ok := p.mp.Load(propertyReconcileConfig); ok {
+		if config, ok := c.(ReconcileConfig); ok {
+			return config
+		}
+	}
+	return ReconcileConfig{}
+}
+
 func (p *mapProperties) SlogJSON() bool {
 	return slogJSON(p.Flags())
 }
 
+// WithTracingConfig — настройки трассировки OpenTelemetry.
+func WithTracingConfig(config TracingConfig) func(*mapProperties) {
+	return func(p *mapProperties) {
+		p.mp.Store(propertyTracingConfig, config)
+	}
+}
+
+// TracingConfig геттер настроек трассировки OpenTelemetry.
+func (p *mapProperties) TracingConfig() TracingConfig {
+	if c, ok := p.mp.Load(propertyTracingConfig); ok {
+		if config, ok := c.(TracingConfig); ok {
+			return config
+		}
+	}
+	return TracingConfig{}
+}
+
 // WithYamlConfig — Конфигурация.
 func WithYamlConfig(config YamlConfig) func(*mapProperties) {
 	return func(p *mapProperties) {
@@ -370,28 +714,46 @@ func (p *mapProperties) YamlConfig() YamlConfig {
 
 func (p *mapProperties) String() string {
 	format := `
+AuthConfig: %v
+CacheConfig: %v
 CacheExpire: %v
 CacheGCInterval: %v
 Debug: %v
+EncryptionConfig: %v
 Environments: %v
 EtcdClientConfig: %v
+EtcdPoolConfig: %v
 Flags: %v
 GRPCAddress: %s
 GRPCTransportCredentials: %v
 HTTPAddress: %s
 HTTPTransportCredentials: %v
+OutboxConfig: %v
+PurgeConfig: %v
+RBACConfig: %v
+ReconcileConfig: %v
+TracingConfig: %v
 %s`
 	return fmt.Sprintf(format,
+		p.AuthConfig(),
+		p.CacheConfig(),
 		p.CacheExpire(),
 		p.CacheGCInterval(),
 		p.Debug(),
+		p.EncryptionConfig(),
 		p.Environments(),
-		p.EtcdClientConfig(),
+		redactedEtcdClientConfig(p.EtcdClientConfig()),
+		p.EtcdPoolConfig(),
 		p.Flags(),
 		p.GRPCAddress(),
 		p.GRPCTransportCredentials(),
 		p.HTTPAddress(),
 		p.HTTPTLSConfig(),
+		p.OutboxConfig(),
+		p.PurgeConfig(),
+		p.RBACConfig(),
+		p.ReconcileConfig(),
+		p.TracingConfig(),
 		p.YamlConfig(),
 	)
 }
@@ -421,12 +783,48 @@ func debug(flags map[string]interface{}) bool {
 	return false
 }
 
-func etcdClientConfig(addresses []string, dialTimeout time.Duration) clientv3.Config {
+func etcdClientConfig(addresses []string, dialTimeout time.Duration, opts ...func(*clientv3.Config)) clientv3.Config {
 
-	return clientv3.Config{
+	result := clientv3.Config{
 		Endpoints:   addresses,
 		DialTimeout: dialTimeout,
 	}
+	for _, opt := range opts {
+		opt(&result)
+	}
+	return result
+}
+
+func withEtcdCredentials(username, password string) func(*clientv3.Config) {
+	return func(c *clientv3.Config) {
+		c.Username = username
+		c.Password = password
+	}
+}
+
+func withEtcdKeepAlive(keepAliveTime, keepAliveTimeout time.Duration) func(*clientv3.Config) {
+	return func(c *clientv3.Config) {
+		c.DialKeepAliveTime = keepAliveTime
+		c.DialKeepAliveTimeout = keepAliveTimeout
+	}
+}
+
+func withEtcdTLS(tlsConfig *tls.Config) func(*clientv3.Config) {
+	return func(c *clientv3.Config) {
+		c.TLS = tlsConfig
+	}
+}
+
+// redactedEtcdClientConfig копия конфигурации клиента etcd без пароля для журнала.
+func redactedEtcdClientConfig(c *clientv3.Config) *clientv3.Config {
+
+	if c == nil || c.Password == "" {
+		return c
+	}
+	result := *c
+	result.Password = "********"
+
+	return &result
 }
 
 func slogJSON(flags map[string]interface{}) bool {
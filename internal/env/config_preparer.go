@@ -11,8 +11,10 @@
 package env
 
 import (
+	"bufio"
 	"bytes"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/alog"
@@ -25,6 +27,8 @@ import (
 	"time"
 )
 
+const defaultCacheMaxEntries = 10000
+
 type preparer struct {
 	env     *environments
 	flagMap map[string]interface{}
@@ -33,6 +37,119 @@ type preparer struct {
 
 var ErrEmptyAddress = fmt.Errorf("can't configure epmty address")
 
+// getAuthConfig при ошибке чтения ключей проверка подлинности остаётся
+// включённой, чтобы неверная настройка не открыла доступ всем клиентам.
+func (p *preparer) getAuthConfig() (AuthConfig, error) {
+
+	if !p.yml.AuthEnabled() {
+		return AuthConfig{}, fmt.Errorf("auth disabled")
+	}
+	var errs []error
+
+	result := AuthConfig{
+		APIKeys:     make(map[string]string),
+		Enabled:     true,
+		JWTAudience: p.yml.AuthJWTAudience(),
+		JWTIssuer:   p.yml.AuthJWTIssuer(),
+	}
+	for name, key := range p.yml.AuthAPIKeys() {
+		if key == "" {
+			errs = append(errs, fmt.Errorf("empty API key for %s", name))
+			continue
+		}
+		result.APIKeys[tool.HashSHA256(key)] = name
+	}
+	if fileName := p.yml.AuthAPIKeysFile(); fileName != "" {
+		keys, err := loadAPIKeysFile(fileName)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for name, key := range keys {
+			result.APIKeys[tool.HashSHA256(key)] = name
+		}
+	}
+	for _, fileName := range p.yml.AuthJWTPublicKeyFiles() {
+		if key := tool.LoadPublicKey(fileName); key != nil {
+			result.JWTPublicKeys = append(result.JWTPublicKeys, key)
+		} else {
+			errs = append(errs, fmt.Errorf("can't load JWT public key %s", fileName))
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// getEncryptionConfig при ошибке чтения ключей шифрование остаётся
+// включённым, чтобы неверная настройка не привела к записи открытых значений.
+func (p *preparer) getEncryptionConfig() (EncryptionConfig, error) {
+
+	if !p.yml.EncryptionEnabled() {
+		return EncryptionConfig{}, fmt.Errorf("encryption disabled")
+	}
+	var errs []error
+
+	result := EncryptionConfig{Enabled: true, Prefixes: p.yml.EncryptionPrefixes()}
+
+	if len(result.Prefixes) == 0 {
+		errs = append(errs, fmt.Errorf("no encryption prefixes"))
+	}
+	if fileName := p.yml.EncryptionPublicKeyFile(); fileName != "" {
+		if result.PublicKey = tool.LoadPublicKey(fileName); result.PublicKey == nil {
+			errs = append(errs, fmt.Errorf("can't load encryption public key %s", fileName))
+		}
+	} else {
+		errs = append(errs, fmt.Errorf("no encryption public key"))
+	}
+	fileNames := append([]string{p.yml.EncryptionPrivateKeyFile()}, p.yml.EncryptionPreviousPrivateKeyFiles()...)
+
+	for _, fileName := range fileNames {
+		if key := tool.LoadPrivateKey(fileName); key != nil {
+			result.PrivateKeys = append(result.PrivateKeys, key)
+		} else {
+			errs = append(errs, fmt.Errorf("can't load encryption private key %s", fileName))
+		}
+	}
+	if result.PublicKey != nil && len(result.PrivateKeys) > 0 &&
+		!result.PublicKey.Equal(&result.PrivateKeys[0].PublicKey) {
+		errs = append(errs, fmt.Errorf("encryption private key doesn't match public key"))
+	}
+	return result, errors.Join(errs...)
+}
+
+// getCacheConfig по умолчанию и при ошибке в настройках кэш ограничен
+// defaultCacheMaxEntries записями (CachePolicyLRU), чтобы не расти без предела.
+func (p *preparer) getCacheConfig() (CacheConfig, error) {
+
+	result := CacheConfig{
+		MaxBytes:       p.yml.CacheMaxBytes(),
+		MaxEntries:     p.yml.CacheMaxEntries(),
+		NegativeExpire: time.Duration(p.yml.CacheNegativeExpireMs()) * time.Millisecond,
+		NegativeWatch:  p.yml.CacheNegativeWatch(),
+		Policy:         p.yml.CachePolicy(),
+	}
+	var err error
+
+	switch result.Policy {
+	case CachePolicyLRU, CachePolicyTTL:
+	case "":
+		result.Policy = CachePolicyLRU
+	default:
+		err = fmt.Errorf("unknown cache policy: %s", result.Policy)
+		result.Policy = CachePolicyLRU
+	}
+	if result.MaxBytes < 0 || result.MaxEntries < 0 {
+		err = fmt.Errorf("negative cache bounds: %d bytes, %d entries", result.MaxBytes, result.MaxEntries)
+		result.MaxBytes, result.MaxEntries = 0, 0
+	}
+	if result.NegativeExpire < 0 {
+		err = fmt.Errorf("negative cache expire: %v", result.NegativeExpire)
+		result.NegativeExpire = 0
+	}
+	if result.Policy == CachePolicyLRU && result.MaxBytes == 0 && result.MaxEntries == 0 {
+		result.MaxEntries = defaultCacheMaxEntries
+	}
+	return result, err
+}
+
 func (p *preparer) getCacheExpire() (time.Duration, error) {
 	return toTimePrepareProperty(
 		flagCacheExpireMs,
@@ -63,6 +180,16 @@ func (p *preparer) getEtcdAddresses() ([]string, error) {
 	return []string{}, fmt.Errorf("etcd servers disabled")
 }
 
+// getEtcdCredentials пользователь и пароль etcd.
+func (p *preparer) getEtcdCredentials() (string, string, error) {
+	if p.yml.EtcdEnabled() {
+		username, er1 := stringPrepareProperty(flagEtcdUsername, p.flagMap, p.env.EtcdUsername, p.yml.EtcdUsername())
+		password, er2 := stringPrepareProperty(flagEtcdPassword, p.flagMap, p.env.EtcdPassword, p.yml.EtcdPassword())
+		return username, password, errors.Join(er1, er2)
+	}
+	return "", "", fmt.Errorf("etcd servers disabled")
+}
+
 func (p *preparer) getEtcdDialTimeout() (time.Duration, error) {
 	if p.yml.EtcdEnabled() {
 		result, err := timePrepareProperty(
@@ -77,6 +204,65 @@ func (p *preparer) getEtcdDialTimeout() (time.Duration, error) {
 	return 0, fmt.Errorf("etcd servers disabled")
 }
 
+func (p *preparer) getEtcdKeepAliveTime() (time.Duration, error) {
+	if p.yml.EtcdEnabled() {
+		return timePrepareProperty(
+			flagEtcdKeepAliveTime, p.flagMap[flagEtcdKeepAliveTime],
+			p.env.EtcdKeepAliveTime,
+			p.yml.EtcdKeepAliveTime())
+	}
+	return 0, fmt.Errorf("etcd servers disabled")
+}
+
+func (p *preparer) getEtcdKeepAliveTimeout() (time.Duration, error) {
+	if p.yml.EtcdEnabled() {
+		return timePrepareProperty(
+			flagEtcdKeepAliveTimeout, p.flagMap[flagEtcdKeepAliveTimeout],
+			p.env.EtcdKeepAliveTimeout,
+			p.yml.EtcdKeepAliveTimeout())
+	}
+	return 0, fmt.Errorf("etcd servers disabled")
+}
+
+func (p *preparer) getEtcdPoolConfig() (PoolConfig, error) {
+	if p.yml.EtcdEnabled() {
+		return PoolConfig{
+			Enabled:        p.yml.EtcdPoolEnabled(),
+			HealthInterval: p.yml.EtcdPoolHealthInterval(),
+			MaxIdle:        p.yml.EtcdPoolMaxIdle(),
+			MaxOpen:        p.yml.EtcdPoolMaxOpen(),
+			MinIdle:        p.yml.EtcdPoolMinIdle(),
+		}, nil
+	}
+	return PoolConfig{}, fmt.Errorf("etcd servers disabled")
+}
+
+// getEtcdTLSConfig TLS конфигурация клиента etcd: сертификат центра
+// сертификации кластера и, для проверки подлинности по сертификату,
+// клиентский сертификат и ключ.
+func (p *preparer) getEtcdTLSConfig() (*tls.Config, error) {
+	if p.yml.EtcdEnabled() && p.yml.EtcdTLSEnabled() {
+		caFile, er1 := stringPrepareProperty(flagEtcdCAFile, p.flagMap, p.env.EtcdCAFile, p.yml.EtcdTLSCAFile())
+		certFile, er2 := stringPrepareProperty(flagEtcdCertFile, p.flagMap, p.env.EtcdCertFile, p.yml.EtcdTLSCertFile())
+		keyFile, er3 := stringPrepareProperty(flagEtcdKeyFile, p.flagMap, p.env.EtcdKeyFile, p.yml.EtcdTLSKeyFile())
+
+		if err := errors.Join(er1, er2, er3); err != nil {
+			return nil, err
+		}
+		return tool.LoadClientTLSConfig(caFile, certFile, keyFile)
+	}
+	return nil, fmt.Errorf("etcd TLS disabled")
+}
+
+// etcdTLSRequired ошибка загрузки TLS конфигурации клиента etcd при
+// включённом etcd.tls, выключенный TLS ошибкой не считается.
+func (p *preparer) etcdTLSRequired(err error) error {
+	if p.yml.EtcdEnabled() && p.yml.EtcdTLSEnabled() {
+		return err
+	}
+	return nil
+}
+
 func (p *preparer) getGRPCAddress() (string, error) {
 	if p.yml.GRPCEnabled() {
 		return serverAddressPrepareProperty(
@@ -92,11 +278,15 @@ func (p *preparer) getGRPCTransportCredentials() (credentials.TransportCredentia
 	if p.yml.GRPCEnabled() {
 		return serverTransportCredentialsPrepareProperty(
 			flagGRPCCertFile,
-			flagGRPCKeyFile, p.flagMap,
+			flagGRPCKeyFile,
+			flagGRPCCAFile, p.flagMap,
 			p.env.GRPCCertFile,
 			p.env.GRPCKeyFile,
+			p.env.GRPCCAFile,
 			p.yml.GRPCTLSCertFile(),
 			p.yml.GRPCTLSKeyFile(),
+			p.yml.GRPCTLSCAFile(),
+			p.yml.GRPCTLSClientAuth(),
 		)
 	}
 	return nil, fmt.Errorf("gRPC server disabled")
@@ -118,16 +308,118 @@ func (p *preparer) getHTTPTLSConfig() (*tls.Config, error) {
 	if p.yml.HTTPTLSEnabled() {
 		return serverTLSConfigPrepareProperty(
 			flagHTTPCertFile,
-			flagHTTPKeyFile, p.flagMap,
+			flagHTTPKeyFile,
+			flagHTTPCAFile, p.flagMap,
 			p.env.HTTPCertFile,
 			p.env.HTTPKeyFile,
+			p.env.HTTPCAFile,
 			p.yml.HTTPTLSCertFile(),
 			p.yml.HTTPTLSKeyFile(),
+			p.yml.HTTPTLSCAFile(),
+			p.yml.HTTPTLSClientAuth(),
 		)
 	}
 	return nil, fmt.Errorf("HTTP server disabled")
 }
 
+func (p *preparer) getOutboxConfig() (OutboxConfig, error) {
+	if p.yml.OutboxEnabled() {
+		return OutboxConfig{
+			BatchSize: p.yml.OutboxBatchSize(),
+			Enabled:   true,
+			Interval:  p.yml.OutboxInterval(),
+			Lease:     p.yml.OutboxLease(),
+		}, nil
+	}
+	return OutboxConfig{}, fmt.Errorf("outbox disabled")
+}
+
+func (p *preparer) getPurgeConfig() (PurgeConfig, error) {
+	if p.yml.PurgeEnabled() {
+		return PurgeConfig{
+			BatchSize: p.yml.PurgeBatchSize(),
+			Enabled:   true,
+			Interval:  p.yml.PurgeInterval(),
+			Retention: time.Duration(p.yml.PurgeRetentionDays()) * 24 * time.Hour,
+		}, nil
+	}
+	return PurgeConfig{}, fmt.Errorf("purge disabled")
+}
+
+func (p *preparer) getRBACConfig() (RBACConfig, error) {
+	if p.yml.RBACEnabled() {
+		return RBACConfig{
+			Bindings:        p.yml.RBACBindings(),
+			Enabled:         true,
+			Postgres:        p.yml.RBACPostgresEnabled(),
+			RefreshInterval: p.yml.RBACPostgresRefreshInterval(),
+			Roles:           p.yml.RBACRoles(),
+		}, nil
+	}
+	return RBACConfig{}, fmt.Errorf("rbac disabled")
+}
+
+func (p *preparer) getReconcileConfig() (ReconcileConfig, error) {
+	if p.yml.ReconcileEnabled() {
+		result := ReconcileConfig{
+			Enabled:       true,
+			Interval:      p.yml.ReconcileInterval(),
+			Prefix:        p.yml.ReconcilePrefix(),
+			Repair:        p.yml.ReconcileRepair(),
+			SourceOfTruth: p.yml.ReconcileSourceOfTruth(),
+		}
+		if result.Repair && result.Prefix == "" {
+			// Без префикса исправление затронуло бы все ключи etcd.
+			result.Repair = false
+			return result, fmt.Errorf("reconcile repair requires prefix")
+		}
+		return result, nil
+	}
+	return ReconcileConfig{}, fmt.Errorf("reconcile disabled")
+}
+
+func (p *preparer) getTracingConfig() (TracingConfig, error) {
+	if p.yml.OtelEnabled() {
+		return TracingConfig{
+			Enabled:     true,
+			Endpoint:    p.yml.OtelEndpoint(),
+			Insecure:    p.yml.OtelInsecure(),
+			SampleRatio: p.yml.OtelSampleRatio(),
+			ServiceName: p.yml.OtelServiceName(),
+		}, nil
+	}
+	return TracingConfig{}, fmt.Errorf("tracing disabled")
+}
+
+// loadAPIKeysFile чтение API-ключей из файла: строка на ключ в формате
+// имя:ключ, пустые строки и строки начинающиеся с # пропускаются.
+func loadAPIKeysFile(fileName string) (map[string]string, error) {
+
+	file, err := os.Open(fileName)
+
+	if err != nil {
+		return nil, err
+	}
+	defer tool.FileClose(file)
+
+	result := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, key, ok := strings.Cut(text, ":")
+		name, key = strings.TrimSpace(name), strings.TrimSpace(key)
+		if !ok || name == "" || key == "" {
+			return result, fmt.Errorf("%s:%d: expected name:key", fileName, line)
+		}
+		result[name] = key
+	}
+	return result, scanner.Err()
+}
+
 func makeDBPool(flm map[string]interface{}, env *environments, yml YamlConfig) (*pgxpool.Pool, error) {
 	if yml.DBEnabled() {
 
@@ -246,95 +538,120 @@ func serverAddressPrepareProperty(
 func serverTransportCredentialsPrepareProperty(
 	nameCertFile string,
 	nameKeyFile string,
+	nameCAFile string,
 	flm map[string]interface{},
 	envTLSCertFile string,
 	envTLSKeyFile string,
+	envTLSCAFile string,
 	ymlTLSCertFile string,
 	ymlTLSKeyFile string,
-) (tCredentials credentials.TransportCredentials, err error) {
-
-	certFile, keyFile := ymlTLSCertFile, ymlTLSKeyFile
-	getFlagGRPCCertFile := func() {
-		if cf, ok := flm[nameCertFile].(*string); !ok {
-			err = fmt.Errorf("bad value of %s : %v", flagGRPCCertFile, flm[flagGRPCCertFile])
-		} else {
-			certFile = *cf
-		}
-	}
-	getFlagGRPCKeyFile := func() {
-		if kf, ok := flm[nameKeyFile].(*string); !ok {
-			err = fmt.Errorf("bad value of %s : %v", flagGRPCKeyFile, flm[flagGRPCKeyFile])
-		} else {
-			keyFile = *kf
-		}
-	}
-	if envTLSCertFile != "" {
-		certFile = envTLSCertFile
-	}
-	if envTLSKeyFile != "" {
-		keyFile = envTLSKeyFile
-	}
-	if certFile == "" {
-		getFlagGRPCCertFile()
-	}
-	if keyFile == "" {
-		getFlagGRPCKeyFile()
-	}
-	setIfFlagChanged(nameCertFile, getFlagGRPCCertFile)
-	setIfFlagChanged(nameKeyFile, getFlagGRPCKeyFile)
+	ymlTLSCAFile string,
+	ymlTLSClientAuth string,
+) (credentials.TransportCredentials, error) {
+
+	certFile, keyFile, caFile, clientAuth, err := serverTLSPrepareProperty(
+		nameCertFile, nameKeyFile, nameCAFile, flm,
+		envTLSCertFile, envTLSKeyFile, envTLSCAFile,
+		ymlTLSCertFile, ymlTLSKeyFile, ymlTLSCAFile, ymlTLSClientAuth,
+	)
 	if err != nil {
 		return nil, err
 	}
-	return tool.LoadServerTLSCredentials(certFile, keyFile)
+	return tool.LoadServerTLSCredentials(certFile, keyFile, caFile, clientAuth)
 }
 
 func serverTLSConfigPrepareProperty(
 	nameCertFile string,
 	nameKeyFile string,
+	nameCAFile string,
 	flm map[string]interface{},
 	envTLSCertFile string,
 	envTLSKeyFile string,
+	envTLSCAFile string,
 	ymlTLSCertFile string,
 	ymlTLSKeyFile string,
-) (tConfig *tls.Config, err error) {
-	certFile, keyFile := ymlTLSCertFile, ymlTLSKeyFile
-	getFlagCertFile := func() {
-		if cf, ok := flm[nameCertFile].(*string); !ok {
-			err = fmt.Errorf("bad value of %s : %v", flagGRPCCertFile, flm[flagGRPCCertFile])
-		} else {
-			certFile = *cf
-		}
+	ymlTLSCAFile string,
+	ymlTLSClientAuth string,
+) (*tls.Config, error) {
+
+	certFile, keyFile, caFile, clientAuth, err := serverTLSPrepareProperty(
+		nameCertFile, nameKeyFile, nameCAFile, flm,
+		envTLSCertFile, envTLSKeyFile, envTLSCAFile,
+		ymlTLSCertFile, ymlTLSKeyFile, ymlTLSCAFile, ymlTLSClientAuth,
+	)
+	if err != nil {
+		return nil, err
 	}
-	getFlagKeyFile := func() {
-		if kf, ok := flm[nameKeyFile].(*string); !ok {
-			err = fmt.Errorf("bad value of %s : %v", flagGRPCKeyFile, flm[flagGRPCKeyFile])
-		} else {
-			keyFile = *kf
-		}
+	return tool.LoadServerTLSConfig(certFile, keyFile, caFile, clientAuth)
+}
+
+// serverTLSPrepareProperty файлы сертификата, ключа и центра сертификации
+// сервера и режим проверки клиентских сертификатов.
+func serverTLSPrepareProperty(
+	nameCertFile string,
+	nameKeyFile string,
+	nameCAFile string,
+	flm map[string]interface{},
+	envTLSCertFile string,
+	envTLSKeyFile string,
+	envTLSCAFile string,
+	ymlTLSCertFile string,
+	ymlTLSKeyFile string,
+	ymlTLSCAFile string,
+	ymlTLSClientAuth string,
+) (certFile, keyFile, caFile string, clientAuth tls.ClientAuthType, err error) {
+
+	if clientAuth, err = clientAuthPrepareProperty(ymlTLSClientAuth); err != nil {
+		return "", "", "", clientAuth, err
 	}
-	if envTLSCertFile != "" {
-		certFile = envTLSCertFile
+	certFile, er1 := stringPrepareProperty(nameCertFile, flm, envTLSCertFile, ymlTLSCertFile)
+	keyFile, er2 := stringPrepareProperty(nameKeyFile, flm, envTLSKeyFile, ymlTLSKeyFile)
+
+	if clientAuth != tls.NoClientCert {
+		var er3 error
+		caFile, er3 = stringPrepareProperty(nameCAFile, flm, envTLSCAFile, ymlTLSCAFile)
+		err = errors.Join(er1, er2, er3)
+	} else {
+		err = errors.Join(er1, er2)
 	}
-	if envTLSKeyFile != "" {
-		keyFile = envTLSKeyFile
+	return certFile, keyFile, caFile, clientAuth, err
+}
+
+// clientAuthPrepareProperty режим проверки клиентских сертификатов:
+// require — сертификат обязателен, verify_if_given — проверяется,
+// если передан, none или пусто — не запрашивается.
+func clientAuthPrepareProperty(mode string) (tls.ClientAuthType, error) {
+
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "require":
+		return tls.RequireAndVerifyClientCert, nil
+	case "verify_if_given":
+		return tls.VerifyClientCertIfGiven, nil
 	}
-	if certFile == "" {
-		getFlagCertFile()
+	return tls.NoClientCert, fmt.Errorf("bad value of client_auth: %s", mode)
+}
+
+func stringPrepareProperty(name string, flm map[string]interface{}, env string, yml string) (result string, err error) {
+
+	result = yml
+	getFlag := func() {
+		if f, ok := flm[name].(*string); !ok {
+			err = fmt.Errorf("bad value of %s : %v", name, flm[name])
+		} else {
+			result = *f
+		}
 	}
-	if keyFile == "" {
-		getFlagKeyFile()
+	if env != "" {
+		result = env
 	}
-	setIfFlagChanged(nameCertFile, getFlagCertFile)
-	setIfFlagChanged(nameKeyFile, getFlagKeyFile)
-	if err != nil {
-		return nil, err
+	if result == "" {
+		getFlag()
 	}
-	cer, err := tls.LoadX509KeyPair(certFile, keyFile)
+	setIfFlagChanged(name, getFlag)
 
-	if err != nil {
-		return nil, err
-	}
-	return &tls.Config{Certificates: []tls.Certificate{cer}}, nil
+	return result, err
 }
 
 func setupLogger(debug bool, slogJSON bool) *slog.Logger { // *flm[propertyDebug].(*bool)
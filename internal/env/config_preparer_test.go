@@ -11,11 +11,23 @@
 package env
 
 import (
+	"crypto/tls"
 	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/tool"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
 
+const (
+	testCAFile         = "../../tool/test_ca-cert.pem"
+	testPrivateKeyFile = "../../tool/test_private-key.pem"
+	testPublicKeyFile  = "../../tool/test_public-key.pem"
+	testServerCertFile = "../../tool/test_server-cert.pem"
+	testServerKeyFile  = "../../tool/test_server-key.pem"
+)
+
 func TestPreparerPositive(t *testing.T) {
 	for _, test := range []struct {
 		name string
@@ -29,6 +41,26 @@ func TestPreparerPositive(t *testing.T) {
 			"test #1 positive #2 for serverAddressPrepareProperty",
 			serverAddressPreparePropertyPositiveTest2,
 		},
+		{
+			"test #2 positive for loadAPIKeysFile",
+			loadAPIKeysFilePositiveTest,
+		},
+		{
+			"test #3 positive for serverTLSConfigPrepareProperty with client_auth",
+			serverTLSConfigPreparePropertyPositiveTest,
+		},
+		{
+			"test #4 positive for etcdClientConfig with credentials, keepalive and TLS",
+			etcdClientConfigPositiveTest,
+		},
+		{
+			"test #5 positive for getEncryptionConfig",
+			getEncryptionConfigPositiveTest,
+		},
+		{
+			"test #6 positive for getCacheConfig default bounds",
+			getCacheConfigPositiveTest,
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			got, err := test.fRun(t)
@@ -38,6 +70,62 @@ func TestPreparerPositive(t *testing.T) {
 	}
 }
 
+func serverTLSConfigPreparePropertyPositiveTest(t *testing.T) (interface{}, error) {
+	got, err := serverTLSConfigPrepareProperty(
+		"", "", "", make(map[string]interface{}),
+		"", "", "",
+		testServerCertFile, testServerKeyFile, testCAFile, "verify_if_given",
+	)
+	if got != nil {
+		assert.Equal(t, tls.VerifyClientCertIfGiven, got.ClientAuth)
+		assert.NotNil(t, got.ClientCAs)
+	}
+	return got, err
+}
+
+func etcdClientConfigPositiveTest(t *testing.T) (interface{}, error) {
+	tlsConfig, err := tool.LoadClientTLSConfig(testCAFile, "", "")
+	if err != nil {
+		return nil, err
+	}
+	got := etcdClientConfig(
+		[]string{"localhost:2379"},
+		time.Second,
+		withEtcdCredentials("user", "secret"),
+		withEtcdKeepAlive(30*time.Second, 10*time.Second),
+		withEtcdTLS(tlsConfig),
+	)
+	assert.Equal(t, "user", got.Username)
+	assert.Equal(t, "secret", got.Password)
+	assert.Equal(t, 30*time.Second, got.DialKeepAliveTime)
+	assert.Equal(t, 10*time.Second, got.DialKeepAliveTimeout)
+	assert.Same(t, tlsConfig, got.TLS)
+	assert.Equal(t, "********", redactedEtcdClientConfig(&got).Password)
+	assert.Equal(t, "secret", got.Password)
+	return got, nil
+}
+
+func getEncryptionConfigPositiveTest(t *testing.T) (interface{}, error) {
+	yml := &yamlConfig{}
+	yml.EtcdClient.Encryption = encryptionConfig{
+		Enabled:        true,
+		Prefixes:       []string{"/secrets/"},
+		PrivateKeyFile: testPrivateKeyFile,
+		PublicKeyFile:  testPublicKeyFile,
+	}
+	got, err := (&preparer{yml: yml}).getEncryptionConfig()
+	assert.True(t, got.Enabled)
+	assert.Len(t, got.PrivateKeys, 1)
+	assert.NotNil(t, got.PublicKey)
+	return got, err
+}
+
+func getCacheConfigPositiveTest(t *testing.T) (interface{}, error) {
+	got, err := (&preparer{yml: &yamlConfig{}}).getCacheConfig()
+	assert.Equal(t, CacheConfig{MaxEntries: defaultCacheMaxEntries, Policy: CachePolicyLRU}, got)
+	return got, err
+}
+
 func serverAddressPreparePropertyPositiveTest2(t *testing.T) (interface{}, error) {
 	got, err := serverAddressPrepareProperty("", make(map[string]interface{}), []string{"l", "1"}, "", 0)
 	assert.Equal(t, "l:1", got)
@@ -51,6 +139,17 @@ func serverAddressPreparePropertyPositiveTest1(t *testing.T) (interface{}, error
 	return got, err
 }
 
+func loadAPIKeysFilePositiveTest(t *testing.T) (interface{}, error) {
+	fileName := filepath.Join(t.TempDir(), "api-keys")
+	data := "# name:key\n\nclient1: key1\nclient2:key:2\n"
+	if err := os.WriteFile(fileName, []byte(data), 0o600); err != nil {
+		return nil, err
+	}
+	got, err := loadAPIKeysFile(fileName)
+	assert.Equal(t, map[string]string{"client1": "key1", "client2": "key:2"}, got)
+	return got, err
+}
+
 func TestPreparerNegative(t *testing.T) {
 	for _, test := range []struct {
 		name string
@@ -76,10 +175,38 @@ func TestPreparerNegative(t *testing.T) {
 			"test #3 negative #2 for serverTLSConfigPrepareProperty",
 			serverTLSConfigPreparePropertyNegativeTest2,
 		},
+		{
+			"test #3 negative #3 for serverTLSConfigPrepareProperty bad client_auth",
+			serverTLSConfigPreparePropertyNegativeTest3,
+		},
+		{
+			"test #3 negative #4 for serverTLSConfigPrepareProperty without CA file",
+			serverTLSConfigPreparePropertyNegativeTest4,
+		},
 		{
 			"test #4 negative for toTimePrepareProperty",
 			toTimePreparePropertyNegativeTest,
 		},
+		{
+			"test #5 negative for loadAPIKeysFile",
+			loadAPIKeysFileNegativeTest,
+		},
+		{
+			"test #6 negative for getEncryptionConfig without keys",
+			getEncryptionConfigNegativeTest,
+		},
+		{
+			"test #7 negative for getCacheConfig unknown policy",
+			getCacheConfigNegativeTest,
+		},
+		{
+			"test #8 negative for getEtcdTLSConfig enabled without CA file",
+			getEtcdTLSConfigNegativeTest,
+		},
+		{
+			"test #9 negative for getReconcileConfig repair without prefix",
+			getReconcileConfigNegativeTest,
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			got, err := test.fRun(t)
@@ -103,19 +230,40 @@ func serverAddressPreparePropertyNegativeTest2(t *testing.T) (interface{}, error
 }
 
 func serverTransportCredentialsPreparePropertyNegativeTest(t *testing.T) (interface{}, error) {
-	got, err := serverTransportCredentialsPrepareProperty("", "", make(map[string]interface{}), "", "", "", "")
+	got, err := serverTransportCredentialsPrepareProperty("", "", "", make(map[string]interface{}), "", "", "", "", "", "", "")
 	assert.Nil(t, got)
 	return nil, err
 }
 
 func serverTLSConfigPreparePropertyNegativeTest1(t *testing.T) (interface{}, error) {
-	got, err := serverTLSConfigPrepareProperty("", "", make(map[string]interface{}), "", "", "", "")
+	got, err := serverTLSConfigPrepareProperty("", "", "", make(map[string]interface{}), "", "", "", "", "", "", "")
 	assert.Nil(t, got)
 	return nil, err
 }
 
 func serverTLSConfigPreparePropertyNegativeTest2(t *testing.T) (interface{}, error) {
-	got, err := serverTLSConfigPrepareProperty("test", "test", map[string]interface{}{"test": ""}, "", "", "", "")
+	got, err := serverTLSConfigPrepareProperty("test", "test", "test", map[string]interface{}{"test": ""}, "", "", "", "", "", "", "")
+	assert.Nil(t, got)
+	return nil, err
+}
+
+func serverTLSConfigPreparePropertyNegativeTest3(t *testing.T) (interface{}, error) {
+	got, err := serverTLSConfigPrepareProperty(
+		"", "", "", make(map[string]interface{}),
+		"", "", "",
+		testServerCertFile, testServerKeyFile, testCAFile, "optional",
+	)
+	assert.Nil(t, got)
+	return nil, err
+}
+
+func serverTLSConfigPreparePropertyNegativeTest4(t *testing.T) (interface{}, error) {
+	s := ""
+	got, err := serverTLSConfigPrepareProperty(
+		"", "", "", map[string]interface{}{"": &s},
+		"", "", "",
+		testServerCertFile, testServerKeyFile, "", "require",
+	)
 	assert.Nil(t, got)
 	return nil, err
 }
@@ -127,5 +275,60 @@ func toTimePreparePropertyNegativeTest(t *testing.T) (interface{}, error) {
 	return nil, err
 }
 
+func loadAPIKeysFileNegativeTest(t *testing.T) (interface{}, error) {
+	fileName := filepath.Join(t.TempDir(), "api-keys")
+	if err := os.WriteFile(fileName, []byte("client1\n"), 0o600); err != nil {
+		return err, nil
+	}
+	_, err := loadAPIKeysFile(fileName)
+	return nil, err
+}
+
+func getEncryptionConfigNegativeTest(t *testing.T) (interface{}, error) {
+	yml := &yamlConfig{}
+	yml.EtcdClient.Encryption = encryptionConfig{
+		Enabled:        true,
+		PrivateKeyFile: filepath.Join(t.TempDir(), "absent.pem"),
+	}
+	got, err := (&preparer{yml: yml}).getEncryptionConfig()
+	assert.True(t, got.Enabled)
+	return nil, err
+}
+
+func getCacheConfigNegativeTest(t *testing.T) (interface{}, error) {
+	yml := &yamlConfig{}
+	yml.EtcdClient.Cache.MaxBytes = 1024
+	yml.EtcdClient.Cache.NegativeExpireMs = -1
+	yml.EtcdClient.Cache.Policy = "lfu"
+	got, err := (&preparer{yml: yml}).getCacheConfig()
+	assert.Equal(t, CacheConfig{MaxBytes: 1024, Policy: CachePolicyLRU}, got)
+	return nil, err
+}
+
+// getEtcdTLSConfigNegativeTest ошибка загрузки включённого TLS останавливает
+// запуск, а не заменяется подключением к etcd без TLS.
+func getEtcdTLSConfigNegativeTest(t *testing.T) (interface{}, error) {
+	yml := &yamlConfig{}
+	yml.EtcdClient.Etcd.Enabled = true
+	yml.EtcdClient.Etcd.TLS.Enabled = true
+	yml.EtcdClient.Etcd.TLS.CAFile = filepath.Join(t.TempDir(), "absent-ca.pem")
+	p := &preparer{env: &environments{}, yml: yml}
+	got, err := p.getEtcdTLSConfig()
+	assert.Panics(t, func() { tool.IfErrorThenPanic(p.etcdTLSRequired(err)) })
+	yml.EtcdClient.Etcd.TLS.Enabled = false
+	assert.Nil(t, p.etcdTLSRequired(err))
+	assert.Nil(t, got)
+	return nil, err
+}
+
+func getReconcileConfigNegativeTest(t *testing.T) (interface{}, error) {
+	yml := &yamlConfig{}
+	yml.EtcdClient.Reconcile.Enabled = true
+	yml.EtcdClient.Reconcile.Repair = true
+	got, err := (&preparer{yml: yml}).getReconcileConfig()
+	assert.Equal(t, ReconcileConfig{Enabled: true}, got)
+	return nil, err
+}
+
 //!-
 /* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -19,19 +19,26 @@ import (
 
 // Environments статичная конфигурация из переменных окружения.
 type environments struct {
-	CacheExpireMs      int           `env:"CACHE_EXPIRE_MS"`
-	CacheGCIntervalSec int           `env:"CACHE_GC_INTERVAL_SEC"`
-	DataBaseDSN        string        `env:"DATABASE_DSN"`
-	EtcdAddresses      []string      `env:"ETCD_ADDRESSES" envSeparator:","`
-	EtcdDialTimeout    time.Duration `env:"ETCD_DIAL_TIMEOUT"`
-	GRPCAddress        []string      `env:"GRPC_ADDRESS" envSeparator:":"`
-	GRPCCAFile         string        `env:"GRPC_CA_FILE"`
-	GRPCCertFile       string        `env:"GRPC_CERT_FILE"`
-	GRPCKeyFile        string        `env:"GRPC_KEY_FILE"`
-	HTTPAddress        []string      `env:"HTTP_ADDRESS" envSeparator:":"`
-	HTTPCAFile         string        `env:"HTTP_CA_FILE"`
-	HTTPCertFile       string        `env:"HTTP_CERT_FILE"`
-	HTTPKeyFile        string        `env:"HTTP_KEY_FILE"`
+	CacheExpireMs        int           `env:"CACHE_EXPIRE_MS"`
+	CacheGCIntervalSec   int           `env:"CACHE_GC_INTERVAL_SEC"`
+	DataBaseDSN          string        `env:"DATABASE_DSN"`
+	EtcdAddresses        []string      `env:"ETCD_ADDRESSES" envSeparator:","`
+	EtcdCAFile           string        `env:"ETCD_CA_FILE"`
+	EtcdCertFile         string        `env:"ETCD_CERT_FILE"`
+	EtcdDialTimeout      time.Duration `env:"ETCD_DIAL_TIMEOUT"`
+	EtcdKeepAliveTime    time.Duration `env:"ETCD_KEEPALIVE_TIME"`
+	EtcdKeepAliveTimeout time.Duration `env:"ETCD_KEEPALIVE_TIMEOUT"`
+	EtcdKeyFile          string        `env:"ETCD_KEY_FILE"`
+	EtcdPassword         string        `env:"ETCD_PASSWORD"`
+	EtcdUsername         string        `env:"ETCD_USERNAME"`
+	GRPCAddress          []string      `env:"GRPC_ADDRESS" envSeparator:":"`
+	GRPCCAFile           string        `env:"GRPC_CA_FILE"`
+	GRPCCertFile         string        `env:"GRPC_CERT_FILE"`
+	GRPCKeyFile          string        `env:"GRPC_KEY_FILE"`
+	HTTPAddress          []string      `env:"HTTP_ADDRESS" envSeparator:":"`
+	HTTPCAFile           string        `env:"HTTP_CA_FILE"`
+	HTTPCertFile         string        `env:"HTTP_CERT_FILE"`
+	HTTPKeyFile          string        `env:"HTTP_KEY_FILE"`
 }
 
 func getEnvironments() (env *environments, err error) {
@@ -45,6 +52,10 @@ func (e *environments) String() string {
 	return fmt.Sprintf(
 		`CACHE_EXPIRE_MS: %d
 CACHE_GC_INTERVAL_SEC: %d
+ETCD_CA_FILE: %s
+ETCD_CERT_FILE: %s
+ETCD_KEY_FILE: %s
+ETCD_USERNAME: %s
 GRPC_ADDRESS: %s
 GRPC_CA_FILE: %s
 GRPC_CERT_FILE: %s
@@ -55,6 +66,10 @@ HTTP_CERT_FILE: %s
 HTTP_KEY_FILE: %s`,
 		e.CacheExpireMs,
 		e.CacheGCIntervalSec,
+		e.EtcdCAFile,
+		e.EtcdCertFile,
+		e.EtcdKeyFile,
+		e.EtcdUsername,
 		e.GRPCAddress,
 		e.GRPCCAFile,
 		e.GRPCCertFile,
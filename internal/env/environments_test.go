@@ -54,6 +54,10 @@ func Test_environmentsString(t *testing.T) {
 			fRun: func(e *environments) string { return e.String() },
 			want: `CACHE_EXPIRE_MS: 0
 CACHE_GC_INTERVAL_SEC: 0
+ETCD_CA_FILE: 
+ETCD_CERT_FILE: 
+ETCD_KEY_FILE: 
+ETCD_USERNAME: 
 GRPC_ADDRESS: []
 GRPC_CA_FILE: 
 GRPC_CERT_FILE: 
@@ -16,21 +16,28 @@ import (
 )
 
 const (
-	flagCacheExpireMs      = "cache-expire-ms"
-	flagCacheGCIntervalSec = "cache-gc-interval-sec"
-	flagDatabaseDSN        = "database-dsn"
-	flagDebug              = "debug"
-	flagEtcdAddresses      = "etcd-addresses"
-	flagEtcdDialTimeout    = "etcd-dial-timeout"
-	flagGRPCAddress        = "grpc-address"
-	flagGRPCCAFile         = "grpc-ca-file"
-	flagGRPCCertFile       = "grpc-cert-file"
-	flagGRPCKeyFile        = "grpc-key-file"
-	flagHTTPAddress        = "http-address"
-	flagHTTPCAFile         = "http-ca-file"
-	flagHTTPCertFile       = "http-cert-file"
-	flagHTTPKeyFile        = "http-key-file"
-	flagSlogJson           = "slog-json"
+	flagCacheExpireMs        = "cache-expire-ms"
+	flagCacheGCIntervalSec   = "cache-gc-interval-sec"
+	flagDatabaseDSN          = "database-dsn"
+	flagDebug                = "debug"
+	flagEtcdAddresses        = "etcd-addresses"
+	flagEtcdCAFile           = "etcd-ca-file"
+	flagEtcdCertFile         = "etcd-cert-file"
+	flagEtcdDialTimeout      = "etcd-dial-timeout"
+	flagEtcdKeepAliveTime    = "etcd-keepalive-time"
+	flagEtcdKeepAliveTimeout = "etcd-keepalive-timeout"
+	flagEtcdKeyFile          = "etcd-key-file"
+	flagEtcdPassword         = "etcd-password"
+	flagEtcdUsername         = "etcd-username"
+	flagGRPCAddress          = "grpc-address"
+	flagGRPCCAFile           = "grpc-ca-file"
+	flagGRPCCertFile         = "grpc-cert-file"
+	flagGRPCKeyFile          = "grpc-key-file"
+	flagHTTPAddress          = "http-address"
+	flagHTTPCAFile           = "http-ca-file"
+	flagHTTPCertFile         = "http-cert-file"
+	flagHTTPKeyFile          = "http-key-file"
+	flagSlogJson             = "slog-json"
 )
 
 func makeFlagsParse() map[string]interface{} {
@@ -66,11 +73,46 @@ func makeFlagsParse() map[string]interface{} {
 			"localhost:1379,localhost:2379,localhost:3379",
 			"etcd servers host and port",
 		)
+		flagsMap[flagEtcdCAFile] = pflag.String(
+			flagEtcdCAFile,
+			"",
+			"etcd CA file",
+		)
+		flagsMap[flagEtcdCertFile] = pflag.String(
+			flagEtcdCertFile,
+			"",
+			"etcd client certificate file",
+		)
 		flagsMap[flagEtcdDialTimeout] = pflag.Duration(
 			flagEtcdDialTimeout,
 			2*time.Second,
 			"etcd servers host and port",
 		)
+		flagsMap[flagEtcdKeepAliveTime] = pflag.Duration(
+			flagEtcdKeepAliveTime,
+			0,
+			"etcd keepalive ping interval",
+		)
+		flagsMap[flagEtcdKeepAliveTimeout] = pflag.Duration(
+			flagEtcdKeepAliveTimeout,
+			0,
+			"etcd keepalive ping timeout",
+		)
+		flagsMap[flagEtcdKeyFile] = pflag.String(
+			flagEtcdKeyFile,
+			"",
+			"etcd client key file",
+		)
+		flagsMap[flagEtcdPassword] = pflag.String(
+			flagEtcdPassword,
+			"",
+			"etcd password",
+		)
+		flagsMap[flagEtcdUsername] = pflag.String(
+			flagEtcdUsername,
+			"",
+			"etcd username",
+		)
 		flagsMap[flagGRPCAddress] = pflag.StringP(
 			flagGRPCAddress,
 			"g",
@@ -43,6 +43,11 @@ func Test_makeFlagsParse(t *testing.T) {
 
 func CallMakeFlagsParse(t *testing.T) {
 	tests := []string{
+		flagEtcdCAFile,
+		flagEtcdCertFile,
+		flagEtcdKeyFile,
+		flagEtcdPassword,
+		flagEtcdUsername,
 		flagGRPCAddress,
 		flagGRPCCAFile,
 		flagGRPCCertFile,
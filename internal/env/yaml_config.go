@@ -20,30 +20,57 @@ var _ YamlConfig = (*yamlConfig)(nil)
 // YamlConfig статичная конфигурация собранная из Yaml-файла.
 type YamlConfig interface {
 	fmt.Stringer
+	AuthAPIKeys() map[string]string
+	AuthAPIKeysFile() string
+	AuthEnabled() bool
+	AuthJWTAudience() string
+	AuthJWTIssuer() string
+	AuthJWTPublicKeyFiles() []string
 	CacheEnabled() bool
 	CacheExpireMs() int
 	CacheGCIntervalSec() int
+	CacheMaxBytes() int64
+	CacheMaxEntries() int
+	CacheNegativeExpireMs() int
+	CacheNegativeWatch() bool
+	CachePolicy() string
 	DBEnabled() bool
 	DBHost() string
+	DBMigrate() bool
 	DBName() string
 	DBPort() int
 	DBRetryIncrease() int
 	DBRetryTries() int
 	DBUserName() string
 	DBUserPassword() string
+	EncryptionEnabled() bool
+	EncryptionPrefixes() []string
+	EncryptionPreviousPrivateKeyFiles() []string
+	EncryptionPrivateKeyFile() string
+	EncryptionPublicKeyFile() string
 	EtcdAddresses() []string
 	EtcdEnabled() bool
 	EtcdDialTimeout() time.Duration
+	EtcdKeepAliveTime() time.Duration
+	EtcdKeepAliveTimeout() time.Duration
+	EtcdPassword() string
+	EtcdPoolEnabled() bool
+	EtcdPoolHealthInterval() time.Duration
+	EtcdPoolMaxIdle() int
+	EtcdPoolMaxOpen() int
+	EtcdPoolMinIdle() int
 	EtcdTLSCAFile() string
 	EtcdTLSCertFile() string
 	EtcdTLSEnabled() bool
 	EtcdTLSKeyFile() string
+	EtcdUsername() string
 	GRPCAddress() string
 	GRPCEnabled() bool
 	GRPCPort() int
 	GRPCProto() string
 	GRPCTLSCAFile() string
 	GRPCTLSCertFile() string
+	GRPCTLSClientAuth() string
 	GRPCTLSEnabled() bool
 	GRPCTLSKeyFile() string
 	HTTPAddress() string
@@ -51,12 +78,37 @@ type YamlConfig interface {
 	HTTPPort() int
 	HTTPTLSCAFile() string
 	HTTPTLSCertFile() string
+	HTTPTLSClientAuth() string
 	HTTPTLSEnabled() bool
 	HTTPTLSKeyFile() string
+	OtelEnabled() bool
+	OtelEndpoint() string
+	OtelInsecure() bool
+	OtelSampleRatio() float64
+	OtelServiceName() string
+	OutboxBatchSize() int
+	OutboxEnabled() bool
+	OutboxInterval() time.Duration
+	OutboxLease() time.Duration
+	PurgeBatchSize() int
+	PurgeEnabled() bool
+	PurgeInterval() time.Duration
+	PurgeRetentionDays() int
+	RBACBindings() map[string][]string
+	RBACEnabled() bool
+	RBACPostgresEnabled() bool
+	RBACPostgresRefreshInterval() time.Duration
+	RBACRoles() map[string][]RBACRule
+	ReconcileEnabled() bool
+	ReconcileInterval() time.Duration
+	ReconcilePrefix() string
+	ReconcileRepair() bool
+	ReconcileSourceOfTruth() string
 }
 
 type yamlConfig struct {
 	EtcdClient struct {
+		Auth  authConfig
 		Cache struct {
 			Enabled     bool
 			cacheConfig `mapstructure:",squash"`
@@ -64,14 +116,17 @@ type yamlConfig struct {
 		DB struct {
 			Enabled  bool
 			dbConfig `mapstructure:",squash"`
+			Migrate  bool
 			Retry    struct {
 				Increase int `mapstructure:"increase"`
 				Tries    int `mapstructure:"tries"`
 			}
 		}
-		Etcd struct {
+		Encryption encryptionConfig
+		Etcd       struct {
 			Enabled    bool
 			etcdConfig `mapstructure:",squash"`
+			Pool       etcdPoolConfig
 			TLS        struct {
 				Enabled   bool
 				tlsConfig `mapstructure:",squash"`
@@ -93,12 +148,34 @@ type yamlConfig struct {
 				tlsConfig `mapstructure:",squash"`
 			}
 		}
+		Otel      otelConfig
+		Outbox    outboxConfig
+		Purge     purgeConfig
+		RBAC      rbacConfig
+		Reconcile reconcileConfig
 	}
 }
 
+type apiKeyConfig struct {
+	Key  string
+	Name string
+}
+
+type authConfig struct {
+	APIKeys     []apiKeyConfig `mapstructure:"api_keys"`
+	APIKeysFile string         `mapstructure:"api_keys_file"`
+	Enabled     bool
+	JWT         jwtConfig
+}
+
 type cacheConfig struct {
-	ExpireMs      int `mapstructure:"expire_ms"`
-	GCIntervalSec int `mapstructure:"gc_interval_sec"`
+	ExpireMs         int    `mapstructure:"expire_ms"`
+	GCIntervalSec    int    `mapstructure:"gc_interval_sec"`
+	MaxBytes         int64  `mapstructure:"max_bytes"`
+	MaxEntries       int    `mapstructure:"max_entries"`
+	NegativeExpireMs int    `mapstructure:"negative_expire_ms"`
+	NegativeWatch    bool   `mapstructure:"negative_watch"`
+	Policy           string `mapstructure:"policy"`
 }
 
 type dbConfig struct {
@@ -109,9 +186,29 @@ type dbConfig struct {
 	UserPassword string `mapstructure:"password"`
 }
 
+type encryptionConfig struct {
+	Enabled                 bool
+	Prefixes                []string
+	PreviousPrivateKeyFiles []string `mapstructure:"previous_private_key_files"`
+	PrivateKeyFile          string   `mapstructure:"private_key_file"`
+	PublicKeyFile           string   `mapstructure:"public_key_file"`
+}
+
 type etcdConfig struct {
-	Addresses   []string
-	DialTimeout time.Duration `mapstructure:"dial_timeout"`
+	Addresses        []string
+	DialTimeout      time.Duration `mapstructure:"dial_timeout"`
+	KeepAliveTime    time.Duration `mapstructure:"keepalive_time"`
+	KeepAliveTimeout time.Duration `mapstructure:"keepalive_timeout"`
+	Password         string
+	Username         string
+}
+
+type etcdPoolConfig struct {
+	Enabled        bool
+	HealthInterval time.Duration `mapstructure:"health_interval"`
+	MaxIdle        int           `mapstructure:"max_idle"`
+	MaxOpen        int           `mapstructure:"max_open"`
+	MinIdle        int           `mapstructure:"min_idle"`
 }
 
 type grpcConfig struct {
@@ -125,10 +222,127 @@ type httpConfig struct {
 	Port    int16
 }
 
+type jwtConfig struct {
+	Audience       string
+	Issuer         string
+	PublicKeyFiles []string `mapstructure:"public_key_files"`
+}
+
+type otelConfig struct {
+	Enabled     bool
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+	ServiceName string  `mapstructure:"service_name"`
+}
+
+type outboxConfig struct {
+	BatchSize int `mapstructure:"batch_size"`
+	Enabled   bool
+	Interval  time.Duration
+	Lease     time.Duration
+}
+
+type purgeConfig struct {
+	BatchSize     int `mapstructure:"batch_size"`
+	Enabled       bool
+	Interval      time.Duration
+	RetentionDays int `mapstructure:"retention_days"`
+}
+
+type rbacBindingConfig struct {
+	Roles   []string
+	Subject string
+}
+
+type rbacConfig struct {
+	Bindings []rbacBindingConfig
+	Enabled  bool
+	Postgres rbacPostgresConfig
+	Roles    []rbacRoleConfig
+}
+
+type rbacPostgresConfig struct {
+	Enabled         bool
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+type rbacRoleConfig struct {
+	Name  string
+	Rules []RBACRule
+}
+
+type reconcileConfig struct {
+	Enabled       bool
+	Interval      time.Duration
+	Prefix        string
+	Repair        bool
+	SourceOfTruth string `mapstructure:"source_of_truth"`
+}
+
 type tlsConfig struct {
-	CAFile   string `mapstructure:"ca_file"`
-	CertFile string `mapstructure:"cert_file"`
-	KeyFile  string `mapstructure:"key_file"`
+	CAFile     string `mapstructure:"ca_file"`
+	CertFile   string `mapstructure:"cert_file"`
+	ClientAuth string `mapstructure:"client_auth"`
+	KeyFile    string `mapstructure:"key_file"`
+}
+
+// AuthAPIKeys статичные API-ключи клиентов: имя клиента → ключ.
+func (y *yamlConfig) AuthAPIKeys() map[string]string {
+
+	result := make(map[string]string)
+
+	if y != nil {
+		for _, item := range y.EtcdClient.Auth.APIKeys {
+			result[item.Name] = item.Key
+		}
+	}
+	return result
+}
+
+// AuthAPIKeysFile файл с API-ключами клиентов, строка на ключ в формате имя:ключ.
+func (y *yamlConfig) AuthAPIKeysFile() string {
+
+	if y != nil {
+		return y.EtcdClient.Auth.APIKeysFile
+	}
+	return ""
+}
+
+// AuthEnabled тумблер проверки подлинности клиентов HTTP и gRPC.
+func (y *yamlConfig) AuthEnabled() bool {
+
+	if y != nil {
+		return y.EtcdClient.Auth.Enabled
+	}
+	return false
+}
+
+// AuthJWTAudience ожидаемый получатель (aud) JWT, пустой — не проверяется.
+func (y *yamlConfig) AuthJWTAudience() string {
+
+	if y != nil {
+		return y.EtcdClient.Auth.JWT.Audience
+	}
+	return ""
+}
+
+// AuthJWTIssuer ожидаемый издатель (iss) JWT, пустой — не проверяется.
+func (y *yamlConfig) AuthJWTIssuer() string {
+
+	if y != nil {
+		return y.EtcdClient.Auth.JWT.Issuer
+	}
+	return ""
+}
+
+// AuthJWTPublicKeyFiles файлы публичных RSA-ключей для проверки подписи JWT.
+func (y *yamlConfig) AuthJWTPublicKeyFiles() []string {
+
+	if y != nil {
+		return y.EtcdClient.Auth.JWT.PublicKeyFiles
+	}
+	return []string{}
 }
 
 // CacheEnabled тумблер включения локального кэша.
@@ -158,6 +372,53 @@ func (y *yamlConfig) CacheGCIntervalSec() int {
 	return 0
 }
 
+// CacheMaxBytes предельный объём ключей и значений в кэше в байтах.
+func (y *yamlConfig) CacheMaxBytes() int64 {
+
+	if y != nil {
+		return y.EtcdClient.Cache.MaxBytes
+	}
+	return 0
+}
+
+// CacheMaxEntries предельное количество записей в кэше.
+func (y *yamlConfig) CacheMaxEntries() int {
+
+	if y != nil {
+		return y.EtcdClient.Cache.MaxEntries
+	}
+	return 0
+}
+
+// CacheNegativeExpireMs срок действия записи кэша отсутствующих ключей
+// в миллисекундах.
+func (y *yamlConfig) CacheNegativeExpireMs() int {
+
+	if y != nil {
+		return y.EtcdClient.Cache.NegativeExpireMs
+	}
+	return 0
+}
+
+// CacheNegativeWatch подписка на запись всех ключей etcd для кэша
+// отсутствующих ключей.
+func (y *yamlConfig) CacheNegativeWatch() bool {
+
+	if y != nil {
+		return y.EtcdClient.Cache.NegativeWatch
+	}
+	return false
+}
+
+// CachePolicy политика вытеснения записей из кэша: lru или ttl.
+func (y *yamlConfig) CachePolicy() string {
+
+	if y != nil {
+		return y.EtcdClient.Cache.Policy
+	}
+	return ""
+}
+
 // DBEnabled тумблер подключения к базе данных PostgreSQL.
 func (y *yamlConfig) DBEnabled() bool {
 
@@ -176,6 +437,15 @@ func (y *yamlConfig) DBHost() string {
 	return ""
 }
 
+// DBMigrate тумблер применения миграций схемы базы данных PostgreSQL при запуске.
+func (y *yamlConfig) DBMigrate() bool {
+
+	if y != nil {
+		return y.EtcdClient.DB.Migrate
+	}
+	return false
+}
+
 // DBName имя базы данных PostgreSQL.
 func (y *yamlConfig) DBName() string {
 
@@ -232,6 +502,53 @@ func (y *yamlConfig) DBUserPassword() string {
 	return ""
 }
 
+// EncryptionEnabled тумблер шифрования значений ключей.
+func (y *yamlConfig) EncryptionEnabled() bool {
+
+	if y != nil {
+		return y.EtcdClient.Encryption.Enabled
+	}
+	return false
+}
+
+// EncryptionPrefixes префиксы ключей, значения которых шифруются.
+func (y *yamlConfig) EncryptionPrefixes() []string {
+
+	if y != nil {
+		return y.EtcdClient.Encryption.Prefixes
+	}
+	return []string{}
+}
+
+// EncryptionPreviousPrivateKeyFiles файлы предыдущих закрытых RSA-ключей,
+// нужных для расшифровки значений до смены ключа.
+func (y *yamlConfig) EncryptionPreviousPrivateKeyFiles() []string {
+
+	if y != nil {
+		return y.EtcdClient.Encryption.PreviousPrivateKeyFiles
+	}
+	return []string{}
+}
+
+// EncryptionPrivateKeyFile файл текущего закрытого RSA-ключа.
+func (y *yamlConfig) EncryptionPrivateKeyFile() string {
+
+	if y != nil {
+		return y.EtcdClient.Encryption.PrivateKeyFile
+	}
+	return ""
+}
+
+// EncryptionPublicKeyFile файл текущего открытого RSA-ключа, которым
+// шифруются ключи данных.
+func (y *yamlConfig) EncryptionPublicKeyFile() string {
+
+	if y != nil {
+		return y.EtcdClient.Encryption.PublicKeyFile
+	}
+	return ""
+}
+
 func (y *yamlConfig) EtcdAddresses() []string {
 
 	if y != nil {
@@ -256,6 +573,82 @@ func (y *yamlConfig) EtcdDialTimeout() time.Duration {
 	return 0
 }
 
+// EtcdKeepAliveTime интервал проверки соединения с etcd (gRPC keepalive),
+// 0 — проверка выключена.
+func (y *yamlConfig) EtcdKeepAliveTime() time.Duration {
+
+	if y != nil {
+		return y.EtcdClient.Etcd.KeepAliveTime
+	}
+	return 0
+}
+
+// EtcdKeepAliveTimeout время ожидания ответа на проверку соединения с etcd,
+// после которого соединение закрывается.
+func (y *yamlConfig) EtcdKeepAliveTimeout() time.Duration {
+
+	if y != nil {
+		return y.EtcdClient.Etcd.KeepAliveTimeout
+	}
+	return 0
+}
+
+// EtcdPassword пароль пользователя etcd.
+func (y *yamlConfig) EtcdPassword() string {
+
+	if y != nil {
+		return y.EtcdClient.Etcd.Password
+	}
+	return ""
+}
+
+// EtcdPoolEnabled тумблер пула клиентов etcd, при выключенном
+// на каждый запрос создаётся новый клиент.
+func (y *yamlConfig) EtcdPoolEnabled() bool {
+
+	if y != nil {
+		return y.EtcdClient.Etcd.Pool.Enabled
+	}
+	return false
+}
+
+// EtcdPoolHealthInterval интервал проверки состояния простаивающих клиентов в пуле etcd.
+func (y *yamlConfig) EtcdPoolHealthInterval() time.Duration {
+
+	if y != nil {
+		return y.EtcdClient.Etcd.Pool.HealthInterval
+	}
+	return 0
+}
+
+// EtcdPoolMaxIdle максимальное количество простаивающих клиентов в пуле etcd.
+func (y *yamlConfig) EtcdPoolMaxIdle() int {
+
+	if y != nil {
+		return y.EtcdClient.Etcd.Pool.MaxIdle
+	}
+	return 0
+}
+
+// EtcdPoolMaxOpen максимальное количество открытых клиентов в пуле etcd.
+func (y *yamlConfig) EtcdPoolMaxOpen() int {
+
+	if y != nil {
+		return y.EtcdClient.Etcd.Pool.MaxOpen
+	}
+	return 0
+}
+
+// EtcdPoolMinIdle минимальное количество простаивающих клиентов в пуле etcd,
+// которое поддерживает фоновая проверка состояния.
+func (y *yamlConfig) EtcdPoolMinIdle() int {
+
+	if y != nil {
+		return y.EtcdClient.Etcd.Pool.MinIdle
+	}
+	return 0
+}
+
 func (y *yamlConfig) EtcdTLSCAFile() string {
 
 	if y != nil {
@@ -288,6 +681,15 @@ func (y *yamlConfig) EtcdTLSKeyFile() string {
 	return ""
 }
 
+// EtcdUsername пользователь etcd, пустой — без проверки подлинности.
+func (y *yamlConfig) EtcdUsername() string {
+
+	if y != nil {
+		return y.EtcdClient.Etcd.Username
+	}
+	return ""
+}
+
 // GRPCAddress адрес для выставления конечных точек gRPC-сервера.
 func (y *yamlConfig) GRPCAddress() string {
 
@@ -342,6 +744,16 @@ func (y *yamlConfig) GRPCTLSCertFile() string {
 	return ""
 }
 
+// GRPCTLSClientAuth режим проверки клиентских сертификатов gRPC-сервером:
+// none, require или verify_if_given.
+func (y *yamlConfig) GRPCTLSClientAuth() string {
+
+	if y != nil {
+		return y.EtcdClient.GRPC.TLS.ClientAuth
+	}
+	return ""
+}
+
 // GRPCTLSKeyFile TLS ключ для gRPC-сервера.
 func (y *yamlConfig) GRPCTLSKeyFile() string {
 
@@ -405,6 +817,16 @@ func (y *yamlConfig) HTTPTLSCertFile() string {
 	return ""
 }
 
+// HTTPTLSClientAuth режим проверки клиентских сертификатов HTTP-сервером:
+// none, require или verify_if_given.
+func (y *yamlConfig) HTTPTLSClientAuth() string {
+
+	if y != nil {
+		return y.EtcdClient.HTTP.TLS.ClientAuth
+	}
+	return ""
+}
+
 // HTTPTLSKeyFile TLS ключ для HTTP-сервера.
 func (y *yamlConfig) HTTPTLSKeyFile() string {
 
@@ -423,6 +845,227 @@ func (y *yamlConfig) HTTPTLSEnabled() bool {
 	return false
 }
 
+// OtelEnabled тумблер экспорта трассировки OpenTelemetry.
+func (y *yamlConfig) OtelEnabled() bool {
+
+	if y != nil {
+		return y.EtcdClient.Otel.Enabled
+	}
+	return false
+}
+
+// OtelEndpoint адрес OTLP/gRPC коллектора трассировки.
+func (y *yamlConfig) OtelEndpoint() string {
+
+	if y != nil {
+		return y.EtcdClient.Otel.Endpoint
+	}
+	return ""
+}
+
+// OtelInsecure тумблер подключения к коллектору трассировки без TLS.
+func (y *yamlConfig) OtelInsecure() bool {
+
+	if y != nil {
+		return y.EtcdClient.Otel.Insecure
+	}
+	return false
+}
+
+// OtelSampleRatio доля запросов попадающих в трассировку, от 0 до 1.
+func (y *yamlConfig) OtelSampleRatio() float64 {
+
+	if y != nil {
+		return y.EtcdClient.Otel.SampleRatio
+	}
+	return 0
+}
+
+// OtelServiceName имя сервиса в атрибутах ресурса трассировки.
+func (y *yamlConfig) OtelServiceName() string {
+
+	if y != nil {
+		return y.EtcdClient.Otel.ServiceName
+	}
+	return ""
+}
+
+// OutboxBatchSize количество записей outbox доставляемых в etcd за один проход.
+func (y *yamlConfig) OutboxBatchSize() int {
+
+	if y != nil {
+		return y.EtcdClient.Outbox.BatchSize
+	}
+	return 0
+}
+
+// OutboxEnabled тумблер записи в PostgreSQL через transactional outbox
+// с последующей доставкой в etcd.
+func (y *yamlConfig) OutboxEnabled() bool {
+
+	if y != nil {
+		return y.EtcdClient.Outbox.Enabled
+	}
+	return false
+}
+
+// OutboxInterval интервал проверки новых записей outbox.
+func (y *yamlConfig) OutboxInterval() time.Duration {
+
+	if y != nil {
+		return y.EtcdClient.Outbox.Interval
+	}
+	return 0
+}
+
+// OutboxLease время, на которое запись outbox закрепляется за экземпляром прокси.
+func (y *yamlConfig) OutboxLease() time.Duration {
+
+	if y != nil {
+		return y.EtcdClient.Outbox.Lease
+	}
+	return 0
+}
+
+// PurgeBatchSize количество записей, удаляемых за один запрос.
+func (y *yamlConfig) PurgeBatchSize() int {
+
+	if y != nil {
+		return y.EtcdClient.Purge.BatchSize
+	}
+	return 0
+}
+
+// PurgeEnabled тумблер фонового удаления из PostgreSQL записей,
+// помеченных удалёнными.
+func (y *yamlConfig) PurgeEnabled() bool {
+
+	if y != nil {
+		return y.EtcdClient.Purge.Enabled
+	}
+	return false
+}
+
+// PurgeInterval интервал фонового удаления записей, помеченных удалёнными.
+func (y *yamlConfig) PurgeInterval() time.Duration {
+
+	if y != nil {
+		return y.EtcdClient.Purge.Interval
+	}
+	return 0
+}
+
+// PurgeRetentionDays срок в днях, в течение которого запись, помеченная
+// удалённой, может быть восстановлена.
+func (y *yamlConfig) PurgeRetentionDays() int {
+
+	if y != nil {
+		return y.EtcdClient.Purge.RetentionDays
+	}
+	return 0
+}
+
+// RBACBindings роли клиентов: способ проверки и имя клиента (Principal),
+// например api_key:name → имена ролей.
+func (y *yamlConfig) RBACBindings() map[string][]string {
+
+	result := make(map[string][]string)
+
+	if y != nil {
+		for _, item := range y.EtcdClient.RBAC.Bindings {
+			result[item.Subject] = append(result[item.Subject], item.Roles...)
+		}
+	}
+	return result
+}
+
+// RBACEnabled тумблер проверки прав доступа к ключам по префиксам.
+func (y *yamlConfig) RBACEnabled() bool {
+
+	if y != nil {
+		return y.EtcdClient.RBAC.Enabled
+	}
+	return false
+}
+
+// RBACPostgresEnabled тумблер чтения ролей и их назначений из PostgreSQL
+// в дополнение к заданным в конфигурации.
+func (y *yamlConfig) RBACPostgresEnabled() bool {
+
+	if y != nil {
+		return y.EtcdClient.RBAC.Postgres.Enabled
+	}
+	return false
+}
+
+// RBACPostgresRefreshInterval интервал повторного чтения ролей из PostgreSQL.
+func (y *yamlConfig) RBACPostgresRefreshInterval() time.Duration {
+
+	if y != nil {
+		return y.EtcdClient.RBAC.Postgres.RefreshInterval
+	}
+	return 0
+}
+
+// RBACRoles правила ролей: имя роли → префиксы ключей и разрешённые действия.
+func (y *yamlConfig) RBACRoles() map[string][]RBACRule {
+
+	result := make(map[string][]RBACRule)
+
+	if y != nil {
+		for _, item := range y.EtcdClient.RBAC.Roles {
+			result[item.Name] = append(result[item.Name], item.Rules...)
+		}
+	}
+	return result
+}
+
+// ReconcileEnabled тумблер фоновой сверки записей etcd и PostgreSQL.
+func (y *yamlConfig) ReconcileEnabled() bool {
+
+	if y != nil {
+		return y.EtcdClient.Reconcile.Enabled
+	}
+	return false
+}
+
+// ReconcileInterval интервал фоновой сверки записей etcd и PostgreSQL.
+func (y *yamlConfig) ReconcileInterval() time.Duration {
+
+	if y != nil {
+		return y.EtcdClient.Reconcile.Interval
+	}
+	return 0
+}
+
+// ReconcilePrefix префикс ключей участвующих в сверке.
+func (y *yamlConfig) ReconcilePrefix() string {
+
+	if y != nil {
+		return y.EtcdClient.Reconcile.Prefix
+	}
+	return ""
+}
+
+// ReconcileRepair тумблер исправления расхождений фоновой сверкой,
+// при выключенном расхождения только записываются в журнал.
+func (y *yamlConfig) ReconcileRepair() bool {
+
+	if y != nil {
+		return y.EtcdClient.Reconcile.Repair
+	}
+	return false
+}
+
+// ReconcileSourceOfTruth хранилище, по которому исправляются расхождения: postgres или etcd.
+func (y *yamlConfig) ReconcileSourceOfTruth() string {
+
+	if y != nil {
+		return y.EtcdClient.Reconcile.SourceOfTruth
+	}
+	return ""
+}
+
 func (y *yamlConfig) String() string {
 	return fmt.Sprintf(
 		`CacheEnabled: %v
@@ -442,6 +1085,7 @@ GRPCPort: %d
 GRPCProto: %s
 GRPCTLSCAFile: %s
 GRPCTLSCertFile: %s
+GRPCTLSClientAuth: %s
 GRPCTLSKeyFile: %s
 GRPCTLSEnabled: %v
 HTTPAddress: %s
@@ -449,6 +1093,7 @@ HTTPEnabled: %v
 HTTPPort: %d
 HTTPTLSCAFile: %s
 HTTPTLSCertFile: %s
+HTTPTLSClientAuth: %s
 HTTPTLSEnabled: %v
 HTTPTLSKeyFile: %s`,
 		y.CacheEnabled(),
@@ -468,6 +1113,7 @@ HTTPTLSKeyFile: %s`,
 		y.GRPCProto(),
 		y.GRPCTLSCAFile(),
 		y.GRPCTLSCertFile(),
+		y.GRPCTLSClientAuth(),
 		y.GRPCTLSKeyFile(),
 		y.GRPCTLSEnabled(),
 		y.HTTPAddress(),
@@ -475,6 +1121,7 @@ HTTPTLSKeyFile: %s`,
 		y.HTTPPort(),
 		y.HTTPTLSCAFile(),
 		y.HTTPTLSCertFile(),
+		y.HTTPTLSClientAuth(),
 		y.HTTPTLSEnabled(),
 		y.HTTPTLSKeyFile(),
 	)
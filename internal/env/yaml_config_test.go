@@ -45,6 +45,7 @@ GRPCPort: 0
 GRPCProto: 
 GRPCTLSCAFile: 
 GRPCTLSCertFile: 
+GRPCTLSClientAuth: 
 GRPCTLSKeyFile: 
 GRPCTLSEnabled: false
 HTTPAddress: 
@@ -52,6 +53,7 @@ HTTPEnabled: false
 HTTPPort: 0
 HTTPTLSCAFile: 
 HTTPTLSCertFile: 
+HTTPTLSClientAuth: 
 HTTPTLSEnabled: false
 HTTPTLSKeyFile: `,
 		},
@@ -75,6 +77,7 @@ GRPCPort: 0
 GRPCProto: 
 GRPCTLSCAFile: 
 GRPCTLSCertFile: 
+GRPCTLSClientAuth: 
 GRPCTLSKeyFile: 
 GRPCTLSEnabled: false
 HTTPAddress: 
@@ -82,6 +85,7 @@ HTTPEnabled: false
 HTTPPort: 0
 HTTPTLSCAFile: 
 HTTPTLSCertFile: 
+HTTPTLSClientAuth: 
 HTTPTLSEnabled: false
 HTTPTLSKeyFile: `,
 		},
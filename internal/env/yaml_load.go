@@ -35,6 +35,7 @@ import (
 //	    enabled: true
 //	    ca_file: cert/grpc-test_ca-cert.pem
 //	    cert_file: cert/grpc-test_server-cert.pem
+//	    client_auth: require
 //	    key_file: cert/grpc-test_server-key.pem
 //	http:
 //	  address: localhost
@@ -44,6 +45,7 @@ import (
 //	    enabled: true
 //	    ca_file: cert/http-test_ca-cert.pem
 //	    cert_file: cert/http-test_server-cert.pem
+//	    client_auth: verify_if_given
 //	    key_file: cert/http-test_server-key.pem
 func LoadConfig(path string) (cfg YamlConfig, err error) {
 
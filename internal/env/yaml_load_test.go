@@ -42,6 +42,7 @@ func TestLoadConfig(t *testing.T) {
 			fRun:  LoadConfig,
 			want: wantLoadConfig{
 				yamlConfig: &yamlConfig{EtcdClient: struct {
+					Auth  authConfig
 					Cache struct {
 						Enabled     bool
 						cacheConfig `mapstructure:",squash"`
@@ -49,14 +50,17 @@ func TestLoadConfig(t *testing.T) {
 					DB struct {
 						Enabled  bool
 						dbConfig `mapstructure:",squash"`
+						Migrate  bool
 						Retry    struct {
 							Increase int `mapstructure:"increase"`
 							Tries    int `mapstructure:"tries"`
 						}
 					}
-					Etcd struct {
+					Encryption encryptionConfig
+					Etcd       struct {
 						Enabled    bool
 						etcdConfig `mapstructure:",squash"`
+						Pool       etcdPoolConfig
 						TLS        struct {
 							Enabled   bool
 							tlsConfig `mapstructure:",squash"`
@@ -78,20 +82,40 @@ func TestLoadConfig(t *testing.T) {
 							tlsConfig `mapstructure:",squash"`
 						}
 					}
+					Otel      otelConfig
+					Outbox    outboxConfig
+					Purge     purgeConfig
+					RBAC      rbacConfig
+					Reconcile reconcileConfig
 				}{
+					Auth: authConfig{
+						APIKeys:     []apiKeyConfig{{Key: "test-api-key", Name: "test"}},
+						APIKeysFile: "",
+						Enabled:     true,
+						JWT: jwtConfig{
+							Audience:       "etcd-proxy",
+							Issuer:         "etcd-client-test",
+							PublicKeyFiles: []string{"tool/test_public-key.pem"},
+						},
+					},
 					Cache: struct {
 						Enabled     bool
 						cacheConfig `mapstructure:",squash"`
 					}{
 						Enabled: true,
 						cacheConfig: cacheConfig{
-							ExpireMs:      1000,
-							GCIntervalSec: 10,
+							ExpireMs:         1000,
+							GCIntervalSec:    10,
+							MaxBytes:         67108864,
+							MaxEntries:       10000,
+							NegativeExpireMs: 500,
+							Policy:           "lru",
 						},
 					},
 					DB: struct {
 						Enabled  bool
 						dbConfig `mapstructure:",squash"`
+						Migrate  bool
 						Retry    struct {
 							Increase int `mapstructure:"increase"`
 							Tries    int `mapstructure:"tries"`
@@ -105,6 +129,7 @@ func TestLoadConfig(t *testing.T) {
 							UserName:     "dbuser",
 							UserPassword: "password",
 						},
+						Migrate: true,
 						Retry: struct {
 							Increase int `mapstructure:"increase"`
 							Tries    int `mapstructure:"tries"`
@@ -113,9 +138,17 @@ func TestLoadConfig(t *testing.T) {
 							Tries:    3,
 						},
 					},
+					Encryption: encryptionConfig{
+						Enabled:                 true,
+						Prefixes:                []string{"/secrets/"},
+						PreviousPrivateKeyFiles: []string{},
+						PrivateKeyFile:          "tool/test_private-key.pem",
+						PublicKeyFile:           "tool/test_public-key.pem",
+					},
 					Etcd: struct {
 						Enabled    bool
 						etcdConfig `mapstructure:",squash"`
+						Pool       etcdPoolConfig
 						TLS        struct {
 							Enabled   bool
 							tlsConfig `mapstructure:",squash"`
@@ -123,8 +156,19 @@ func TestLoadConfig(t *testing.T) {
 					}{
 						Enabled: true,
 						etcdConfig: etcdConfig{
-							Addresses:   []string{"localhost:1379", "localhost:2379", "localhost:3379"},
-							DialTimeout: 2 * time.Second,
+							Addresses:        []string{"localhost:1379", "localhost:2379", "localhost:3379"},
+							DialTimeout:      2 * time.Second,
+							KeepAliveTime:    30 * time.Second,
+							KeepAliveTimeout: 10 * time.Second,
+							Password:         "test",
+							Username:         "test",
+						},
+						Pool: etcdPoolConfig{
+							Enabled:        true,
+							HealthInterval: 5 * time.Second,
+							MaxIdle:        16,
+							MaxOpen:        64,
+							MinIdle:        2,
 						},
 						TLS: struct {
 							Enabled   bool
@@ -132,9 +176,9 @@ func TestLoadConfig(t *testing.T) {
 						}{
 							Enabled: true,
 							tlsConfig: tlsConfig{
-								CAFile:   "cert/etcd-test_ca-cert.pem",
-								CertFile: "cert/etcd-test_server-cert.pem",
-								KeyFile:  "cert/etcd-test_server-key.pem",
+								CAFile:   "../../tool/test_ca-cert.pem",
+								CertFile: "../../tool/test_server-cert.pem",
+								KeyFile:  "../../tool/test_server-key.pem",
 							},
 						},
 					},
@@ -158,9 +202,10 @@ func TestLoadConfig(t *testing.T) {
 						}{
 							Enabled: true,
 							tlsConfig: tlsConfig{
-								CAFile:   "cert/grpc-test_ca-cert.pem",
-								CertFile: "cert/grpc-test_server-cert.pem",
-								KeyFile:  "cert/grpc-test_server-key.pem",
+								CAFile:     "cert/grpc-test_ca-cert.pem",
+								CertFile:   "cert/grpc-test_server-cert.pem",
+								ClientAuth: "require",
+								KeyFile:    "cert/grpc-test_server-key.pem",
 							},
 						},
 					},
@@ -183,12 +228,51 @@ func TestLoadConfig(t *testing.T) {
 						}{
 							Enabled: true,
 							tlsConfig: tlsConfig{
-								CAFile:   "cert/http-test_ca-cert.pem",
-								CertFile: "cert/http-test_server-cert.pem",
-								KeyFile:  "cert/http-test_server-key.pem",
+								CAFile:     "cert/http-test_ca-cert.pem",
+								CertFile:   "cert/http-test_server-cert.pem",
+								ClientAuth: "verify_if_given",
+								KeyFile:    "cert/http-test_server-key.pem",
 							},
 						},
 					},
+					Otel: otelConfig{
+						Enabled:     false,
+						Endpoint:    "localhost:4317",
+						Insecure:    true,
+						SampleRatio: 0.5,
+						ServiceName: "etcd-proxy",
+					},
+					Outbox: outboxConfig{
+						BatchSize: 100,
+						Enabled:   true,
+						Interval:  time.Second,
+						Lease:     30 * time.Second,
+					},
+					Purge: purgeConfig{
+						BatchSize:     500,
+						Enabled:       true,
+						Interval:      time.Hour,
+						RetentionDays: 30,
+					},
+					RBAC: rbacConfig{
+						Bindings: []rbacBindingConfig{{Roles: []string{"team-a"}, Subject: "api_key:test"}},
+						Enabled:  true,
+						Postgres: rbacPostgresConfig{Enabled: false, RefreshInterval: time.Minute},
+						Roles: []rbacRoleConfig{{
+							Name: "team-a",
+							Rules: []RBACRule{
+								{Actions: []string{"read", "watch"}, Prefix: "/team-a/"},
+								{Actions: []string{"read", "write", "delete", "watch"}, Prefix: "/team-a/app/"},
+							},
+						}},
+					},
+					Reconcile: reconcileConfig{
+						Enabled:       true,
+						Interval:      5 * time.Minute,
+						Prefix:        "kv/",
+						Repair:        false,
+						SourceOfTruth: "postgres",
+					},
 				}},
 				err: nil,
 			},
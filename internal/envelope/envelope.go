@@ -0,0 +1,196 @@
+/*
+ * This file was last modified at 2026-10-18 23:05 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * envelope.go
+ * $Id$
+ */
+//!+
+
+// Package envelope шифрование значений ключей «конвертом»: значение
+// шифруется случайным ключом данных AES-256-GCM, а ключ данных —
+// открытым мастер-ключом RSA (RSA-OAEP) и хранится рядом со значением.
+package envelope
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/tool"
+	"strings"
+)
+
+const (
+	dataKeySize = 32
+	keyIDSize   = 16
+	prefix      = "enc:v1:"
+)
+
+var (
+	ErrBadValue     = fmt.Errorf("bad encrypted value")
+	ErrNoPublicKey  = fmt.Errorf("no encryption public key")
+	ErrUnknownKeyID = fmt.Errorf("unknown encryption key id")
+)
+
+var encoding = base64.RawStdEncoding
+
+// Envelope шифрование значений ключей с префиксами из env.EncryptionConfig.
+// Зашифрованное значение имеет вид enc:v1:<id ключа>:<ключ данных>:<данные>,
+// ключ записи проверяется при расшифровке (AAD), поэтому значение нельзя
+// перенести под другой ключ. Nil или выключенный Envelope значения не меняет.
+type Envelope struct {
+	config      env.EncryptionConfig
+	keyID       string
+	privateKeys map[string]*rsa.PrivateKey
+}
+
+func New(config env.EncryptionConfig) *Envelope {
+
+	result := &Envelope{config: config, privateKeys: make(map[string]*rsa.PrivateKey)}
+
+	if config.PublicKey != nil {
+		result.keyID = KeyID(config.PublicKey)
+	}
+	for _, key := range config.PrivateKeys {
+		if key != nil {
+			result.privateKeys[KeyID(&key.PublicKey)] = key
+		}
+	}
+	return result
+}
+
+// KeyID идентификатор мастер-ключа: начало SHA-256 открытого ключа.
+func KeyID(key *rsa.PublicKey) string {
+	return tool.HashSHA256(string(x509.MarshalPKCS1PublicKey(key)))[:keyIDSize]
+}
+
+// IsEncrypted значение зашифровано Envelope.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func (e *Envelope) Enabled() bool {
+	return e != nil && e.config.Enabled
+}
+
+// Match значение ключа key должно храниться зашифрованным.
+func (e *Envelope) Match(key string) bool {
+
+	if !e.Enabled() {
+		return false
+	}
+	for _, p := range e.config.Prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Encrypt шифрование значения ключа key, значения ключей вне настроенных
+// префиксов возвращаются без изменений.
+func (e *Envelope) Encrypt(key, value string) (string, error) {
+
+	if !e.Match(key) {
+		return value, nil
+	}
+	if e.config.PublicKey == nil {
+		return "", ErrNoPublicKey
+	}
+	dataKey := make([]byte, dataKeySize)
+
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	wrapped, err := tool.EncryptRSAOAEP(e.config.PublicKey, dataKey)
+
+	if err != nil {
+		return "", err
+	}
+	sealed, err := tool.EncryptAESGCM(dataKey, []byte(value), []byte(key))
+
+	if err != nil {
+		return "", err
+	}
+	return prefix + e.keyID + ":" + encoding.EncodeToString(wrapped) + ":" + encoding.EncodeToString(sealed), nil
+}
+
+// Decrypt расшифровка значения ключа key, незашифрованные значения
+// возвращаются без изменений, чтобы читались записи, сделанные до
+// включения шифрования. Значение ключа вне настроенных префиксов,
+// которое лишь похоже на зашифрованное, при ошибке расшифровки
+// возвращается без изменений.
+func (e *Envelope) Decrypt(key, value string) (string, error) {
+
+	if !e.Enabled() || !IsEncrypted(value) {
+		return value, nil
+	}
+	plain, err := e.decrypt(key, value)
+
+	if err != nil && !e.Match(key) {
+		return value, nil
+	}
+	return plain, err
+}
+
+func (e *Envelope) decrypt(key, value string) (string, error) {
+
+	keyID, wrapped, sealed, err := parse(value)
+
+	if err != nil {
+		return "", err
+	}
+	privateKey, ok := e.privateKeys[keyID]
+
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKeyID, keyID)
+	}
+	dataKey, err := tool.DecryptRSAOAEP(privateKey, wrapped)
+
+	if err != nil {
+		return "", err
+	}
+	plain, err := tool.DecryptAESGCM(dataKey, sealed, []byte(key))
+
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// Stale значение ключа key не зашифровано текущим мастер-ключом
+// и должно быть перешифровано при смене ключа.
+func (e *Envelope) Stale(key, value string) bool {
+
+	if !e.Match(key) {
+		return false
+	}
+	if !IsEncrypted(value) {
+		return true
+	}
+	keyID, _, _, err := parse(value)
+
+	return err == nil && keyID != e.keyID
+}
+
+func parse(value string) (keyID string, wrapped, sealed []byte, err error) {
+
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+
+	if len(parts) != 3 {
+		return "", nil, nil, ErrBadValue
+	}
+	if wrapped, err = encoding.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, ErrBadValue
+	}
+	if sealed, err = encoding.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, ErrBadValue
+	}
+	return parts[0], wrapped, sealed, nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
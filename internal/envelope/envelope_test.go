@@ -0,0 +1,207 @@
+/*
+ * This file was last modified at 2026-10-18 23:05 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * envelope_test.go
+ * $Id$
+ */
+//!+
+
+package envelope
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/tool"
+	"strings"
+	"testing"
+)
+
+const testPrivateKeyFile = "../../tool/test_private-key.pem"
+
+func TestEnvelope(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for methods Encrypt(...) and Decrypt(...)",
+			positiveEncryptDecrypt,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, "secret", i) },
+		},
+		{
+			"test #1 positive for methods Encrypt(...) and Decrypt(...) outside prefixes",
+			positivePassThrough,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []string{"public", "plain"}, i)
+			},
+		},
+		{
+			"test #2 negative for method Decrypt(...) with other key",
+			negativeDecryptOtherKey,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(bool)) },
+		},
+		{
+			"test #3 positive for method Stale(...) after master key rotation",
+			positiveStaleRotation,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []interface{}{true, "secret", false, "secret"}, i)
+			},
+		},
+		{
+			"test #4 negative for method Decrypt(...) unknown key id",
+			negativeDecryptUnknownKeyID,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(bool)) },
+		},
+		{
+			"test #5 negative for method Encrypt(...) without public key",
+			negativeEncryptNoPublicKey,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(bool)) },
+		},
+		{
+			"test #6 positive for method Decrypt(...) encrypted-looking value outside prefixes",
+			positiveDecryptOutsidePrefixes,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []string{"enc:v1:not-a-secret", "secret"}, i)
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveEncryptDecrypt(t *testing.T) (interface{}, error) {
+
+	e := New(testConfig(tool.LoadPrivateKey(testPrivateKeyFile)))
+	encrypted, err := e.Encrypt("/secrets/key", "secret")
+
+	if err != nil {
+		return nil, err
+	}
+	assert.True(t, IsEncrypted(encrypted))
+	assert.False(t, strings.Contains(encrypted, "secret"))
+
+	return e.Decrypt("/secrets/key", encrypted)
+}
+
+func positivePassThrough(_ *testing.T) (interface{}, error) {
+
+	e := New(testConfig(tool.LoadPrivateKey(testPrivateKeyFile)))
+	encrypted, err := e.Encrypt("/public/key", "public")
+
+	if err != nil {
+		return nil, err
+	}
+	decrypted, err := e.Decrypt("/secrets/key", "plain")
+
+	return []string{encrypted, decrypted}, err
+}
+
+func negativeDecryptOtherKey(_ *testing.T) (interface{}, error) {
+
+	e := New(testConfig(tool.LoadPrivateKey(testPrivateKeyFile)))
+	encrypted, err := e.Encrypt("/secrets/key", "secret")
+
+	if err != nil {
+		return nil, err
+	}
+	_, err = e.Decrypt("/secrets/other", encrypted)
+
+	return err != nil, nil
+}
+
+func positiveStaleRotation(_ *testing.T) (interface{}, error) {
+
+	previous := tool.LoadPrivateKey(testPrivateKeyFile)
+	current, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := New(testConfig(previous)).Encrypt("/secrets/key", "secret")
+
+	if err != nil {
+		return nil, err
+	}
+	e := New(testConfig(current, previous))
+	stale := e.Stale("/secrets/key", encrypted)
+	decrypted, err := e.Decrypt("/secrets/key", encrypted)
+
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err = e.Encrypt("/secrets/key", decrypted)
+
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := New(testConfig(current)).Decrypt("/secrets/key", encrypted)
+
+	return []interface{}{stale, decrypted, e.Stale("/secrets/key", encrypted), rotated}, err
+}
+
+func negativeDecryptUnknownKeyID(_ *testing.T) (interface{}, error) {
+
+	current, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := New(testConfig(tool.LoadPrivateKey(testPrivateKeyFile))).Encrypt("/secrets/key", "secret")
+
+	if err != nil {
+		return nil, err
+	}
+	_, err = New(testConfig(current)).Decrypt("/secrets/key", encrypted)
+
+	return errors.Is(err, ErrUnknownKeyID), nil
+}
+
+func negativeEncryptNoPublicKey(_ *testing.T) (interface{}, error) {
+
+	e := New(env.EncryptionConfig{Enabled: true, Prefixes: []string{"/secrets/"}})
+	_, err := e.Encrypt("/secrets/key", "secret")
+
+	return errors.Is(err, ErrNoPublicKey), nil
+}
+
+func positiveDecryptOutsidePrefixes(_ *testing.T) (interface{}, error) {
+
+	e := New(testConfig(tool.LoadPrivateKey(testPrivateKeyFile)))
+	raw, err := e.Decrypt("/public/key", "enc:v1:not-a-secret")
+
+	if err != nil {
+		return nil, err
+	}
+	// Значение, зашифрованное до исключения префикса из настроек.
+	encrypted, err := e.Encrypt("/secrets/key", "secret")
+
+	if err != nil {
+		return nil, err
+	}
+	e.config.Prefixes = []string{"/other/"}
+	decrypted, err := e.Decrypt("/secrets/key", encrypted)
+
+	return []string{raw, decrypted}, err
+}
+
+func testConfig(keys ...*rsa.PrivateKey) env.EncryptionConfig {
+	return env.EncryptionConfig{
+		Enabled:     true,
+		Prefixes:    []string{"/secrets/"},
+		PrivateKeys: keys,
+		PublicKey:   &keys[0].PublicKey,
+	}
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,216 @@
+/*
+ * This file was last modified at 2026-10-18 17:00 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * metrics.go
+ * $Id$
+ */
+//!+
+
+// Package metrics метрики Prometheus для HTTP и gRPC серверов, кэша,
+// пула клиентов etcd и пула подключений PostgreSQL.
+package metrics
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/memory"
+	"github.com/victor-skurikhin/etcd-client/v1/pool"
+)
+
+const namespace = "etcd_proxy"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Количество HTTP запросов по маршрутам.",
+	}, []string{"method", "route", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Время обработки HTTP запросов по маршрутам.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	grpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "Количество gRPC запросов по методам.",
+	}, []string{"method", "code"})
+	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "Время обработки gRPC запросов по методам.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	cacheCoalesced = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "coalesced_total",
+		Help:      "Количество чтений при промахе кэша, объединённых с одновременным чтением того же ключа или страницы.",
+	}, []string{"cache", "op"})
+	watchReconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "watch",
+		Name:      "reconnects_total",
+		Help:      "Количество переподключений цикла подписки на инвалидацию кэша.",
+	}, []string{"watcher"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		grpcRequests,
+		grpcDuration,
+		cacheCoalesced,
+		watchReconnects,
+	)
+}
+
+// Handler — обработчик конечной точки /metrics.
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+}
+
+// RegisterCache — метрики кэша: попадания, промахи, вытеснения, количество
+// и объём записей.
+func RegisterCache(name string, stats func() memory.Stats) error {
+
+	labels := prometheus.Labels{"cache": name}
+
+	return register(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "cache", Name: "hits_total",
+			Help: "Количество попаданий в кэш.", ConstLabels: labels,
+		}, func() float64 { return float64(stats().Hits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "cache", Name: "misses_total",
+			Help: "Количество промахов кэша.", ConstLabels: labels,
+		}, func() float64 { return float64(stats().Misses) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "cache", Name: "evictions_total",
+			Help: "Количество вытесненных по сроку действия записей кэша.", ConstLabels: labels,
+		}, func() float64 { return float64(stats().Evictions) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "cache", Name: "capacity_evictions_total",
+			Help: "Количество записей кэша, вытесненных при превышении ограничений.", ConstLabels: labels,
+		}, func() float64 { return float64(stats().CapacityEvictions) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "cache", Name: "size",
+			Help: "Количество записей в кэше.", ConstLabels: labels,
+		}, func() float64 { return float64(stats().Size) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "cache", Name: "bytes",
+			Help: "Объём ключей и значений в кэше в байтах.", ConstLabels: labels,
+		}, func() float64 { return float64(stats().Bytes) }),
+	)
+}
+
+// RegisterEtcdPool — метрики пула клиентов etcd.
+func RegisterEtcdPool(stats func() pool.Stats) error {
+	return register(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "etcd_pool", Name: "open",
+			Help: "Количество открытых клиентов etcd.",
+		}, func() float64 { return float64(stats().Open) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "etcd_pool", Name: "idle",
+			Help: "Количество простаивающих клиентов etcd.",
+		}, func() float64 { return float64(stats().Idle) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "etcd_pool", Name: "in_use",
+			Help: "Количество занятых клиентов etcd.",
+		}, func() float64 { return float64(stats().InUse) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "etcd_pool", Name: "waits_total",
+			Help: "Количество ожиданий свободного клиента etcd.",
+		}, func() float64 { return float64(stats().WaitCount) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "etcd_pool", Name: "wait_duration_seconds_total",
+			Help: "Суммарное время ожидания свободного клиента etcd.",
+		}, func() float64 { return stats().WaitDuration.Seconds() }),
+	)
+}
+
+// RegisterPgxPool — метрики пула подключений PostgreSQL из pgxpool.Stat().
+func RegisterPgxPool(dbPool *pgxpool.Pool) error {
+
+	if dbPool == nil {
+		return nil
+	}
+	gauge := func(name, help string, value func(*pgxpool.Stat) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "pgx_pool", Name: name, Help: help,
+		}, func() float64 { return value(dbPool.Stat()) })
+	}
+	counter := func(name, help string, value func(*pgxpool.Stat) float64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "pgx_pool", Name: name, Help: help,
+		}, func() float64 { return value(dbPool.Stat()) })
+	}
+	return register(
+		gauge("acquired_conns", "Количество занятых подключений.",
+			func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) }),
+		gauge("constructing_conns", "Количество устанавливаемых подключений.",
+			func(s *pgxpool.Stat) float64 { return float64(s.ConstructingConns()) }),
+		gauge("idle_conns", "Количество простаивающих подключений.",
+			func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) }),
+		gauge("max_conns", "Максимальный размер пула подключений.",
+			func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) }),
+		gauge("total_conns", "Общее количество подключений.",
+			func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) }),
+		counter("acquires_total", "Количество успешных получений подключения.",
+			func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) }),
+		counter("acquire_duration_seconds_total", "Суммарное время получения подключений.",
+			func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() }),
+		counter("canceled_acquires_total", "Количество получений подключения, отменённых контекстом.",
+			func(s *pgxpool.Stat) float64 { return float64(s.CanceledAcquireCount()) }),
+		counter("empty_acquires_total", "Количество получений подключения с ожиданием.",
+			func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) }),
+		counter("new_conns_total", "Количество созданных подключений.",
+			func(s *pgxpool.Stat) float64 { return float64(s.NewConnsCount()) }),
+	)
+}
+
+// CacheCoalesced — учёт чтения op кэша cache, получившего результат
+// одновременного чтения вместо отдельного запроса к хранилищу.
+func CacheCoalesced(cache, op string) {
+	cacheCoalesced.WithLabelValues(cache, op).Inc()
+}
+
+// WatchReconnect — учёт переподключения цикла подписки watcher.
+func WatchReconnect(watcher string) {
+	watchReconnects.WithLabelValues(watcher).Inc()
+}
+
+// register регистрация коллекторов, повторная регистрация не ошибка.
+func register(cs ...prometheus.Collector) error {
+
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			var are prometheus.AlreadyRegisteredError
+
+			if !errors.As(err, &are) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,104 @@
+/*
+ * This file was last modified at 2026-10-18 17:00 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * metrics_test.go
+ * $Id$
+ */
+//!+
+
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/memory"
+	"github.com/victor-skurikhin/etcd-client/v1/pool"
+	"io"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for function New() request count by route",
+			positiveRouteMetrics,
+			positiveRouteMetricsCheck,
+		},
+		{
+			"test #1 positive for functions RegisterCache and RegisterEtcdPool",
+			positiveRegisterMetrics,
+			positiveRegisterMetricsCheck,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveRouteMetrics(_ *testing.T) (interface{}, error) {
+
+	app := fiber.New()
+	app.Get("/metrics", Handler())
+	app.Use(New())
+	app.Get("/get/:name", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/get/key1", nil)); err != nil {
+		return nil, err
+	}
+	return scrape(app)
+}
+
+func positiveRouteMetricsCheck(t *testing.T, i interface{}) bool {
+	return assert.Contains(t, i,
+		`etcd_proxy_http_requests_total{method="GET",route="/get/:name",status="200"} 1`,
+	)
+}
+
+func positiveRegisterMetrics(_ *testing.T) (interface{}, error) {
+
+	app := fiber.New()
+	app.Get("/metrics", Handler())
+	stats := func() memory.Stats { return memory.Stats{Bytes: 64, CapacityEvictions: 5, Hits: 3, Size: 2} }
+
+	if err := RegisterCache("test", stats); err != nil {
+		return nil, err
+	}
+	if err := RegisterEtcdPool(func() pool.Stats { return pool.Stats{Open: 4, InUse: 1} }); err != nil {
+		return nil, err
+	}
+	return scrape(app)
+}
+
+func positiveRegisterMetricsCheck(t *testing.T, i interface{}) bool {
+	return assert.Contains(t, i, `etcd_proxy_cache_hits_total{cache="test"} 3`) &&
+		assert.Contains(t, i, `etcd_proxy_cache_size{cache="test"} 2`) &&
+		assert.Contains(t, i, `etcd_proxy_cache_bytes{cache="test"} 64`) &&
+		assert.Contains(t, i, `etcd_proxy_cache_capacity_evictions_total{cache="test"} 5`) &&
+		assert.Contains(t, i, `etcd_proxy_etcd_pool_open 4`)
+}
+
+func scrape(app *fiber.App) (string, error) {
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/metrics", nil))
+
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+
+	return string(body), err
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,78 @@
+/*
+ * This file was last modified at 2026-10-18 17:00 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * middleware.go
+ * $Id$
+ */
+//!+
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"strconv"
+	"time"
+)
+
+// New — промежуточный обработчик Fiber: количество и время
+// обработки запросов по методу и шаблону маршрута.
+func New() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+
+		start := time.Now()
+		err := c.Next()
+		code := c.Response().StatusCode()
+
+		if err != nil {
+			code = fiber.StatusInternalServerError
+			var fe *fiber.Error
+
+			if errors.As(err, &fe) {
+				code = fe.Code
+			}
+		}
+		method := c.Method()
+		route := c.Route().Path
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// UnaryServerInterceptor — учёт количества и времени обработки унарных gRPC запросов.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeGRPC(info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor — учёт количества и времени обработки потоковых gRPC запросов.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		start := time.Now()
+		err := handler(srv, ss)
+		observeGRPC(info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func observeGRPC(method string, start time.Time, err error) {
+	grpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	grpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,262 @@
+/*
+ * This file was last modified at 2026-10-18 18:20 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * migrations.go
+ * $Id$
+ */
+//!+
+
+// Package migrations версионные миграции схемы PostgreSQL, встроенные
+// в исполняемый файл. Применённые версии хранятся в schema_migrations,
+// параллельный запуск нескольких прокси упорядочивается advisory lock.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// advisoryLockKey ключ pg_advisory_lock миграций ("etcdprxy").
+const advisoryLockKey int64 = 0x6574636470727879
+
+const (
+	createSchemaMigrations = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    BIGINT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+)`
+	deleteSchemaMigration = `DELETE FROM schema_migrations WHERE version = $1`
+	insertSchemaMigration = `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+	selectSchemaMigration = `SELECT version, applied_at FROM schema_migrations ORDER BY version`
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var (
+	ErrBadPool      = fmt.Errorf("bad Database pool")
+	ErrNoMigrations = fmt.Errorf("no applied migrations")
+)
+
+// Migration версия схемы: SQL применения и отката.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status состояние миграции, AppliedAt нулевое у неприменённой.
+type Status struct {
+	Migration
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	migrations []Migration
+	pool       *pgxpool.Pool
+	sLog       *slog.Logger
+}
+
+// New — миграции встроенные в исполняемый файл для базы данных из конфигурации.
+func New(cfg env.Config) (*Migrator, error) {
+
+	migrations, err := Load(embedded)
+
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{migrations: migrations, pool: cfg.DBPool(), sLog: cfg.Logger()}, nil
+}
+
+// Load — чтение миграций из каталога sql в fsys, файлы именуются
+// <версия>_<имя>.up.sql и <версия>_<имя>.down.sql.
+func Load(fsys fs.FS) ([]Migration, error) {
+
+	entries, err := fs.ReadDir(fsys, "sql")
+
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+
+		if err != nil {
+			return nil, err
+		}
+		data, err := fs.ReadFile(fsys, path.Join("sql", entry.Name()))
+
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d has different names: %s, %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+	result := make([]Migration, 0, len(byVersion))
+
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration version %d must have both up and down files", m.Version)
+		}
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+
+	return result, nil
+}
+
+// Up — применение всех неприменённых миграций по возрастанию версии,
+// возвращает количество применённых.
+func (m *Migrator) Up(ctx context.Context) (count int, err error) {
+
+	err = m.locked(ctx, func(conn *pgxpool.Conn, applied map[int64]time.Time) error {
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := apply(ctx, conn, migration.Up, insertSchemaMigration, migration.Version, migration.Name); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+			}
+			m.sLog.InfoContext(ctx, env.MSG+"Migrator.Up", "version", migration.Version, "name", migration.Name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down — откат последней применённой миграции.
+func (m *Migrator) Down(ctx context.Context) (result Migration, err error) {
+
+	err = m.locked(ctx, func(conn *pgxpool.Conn, applied map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := apply(ctx, conn, migration.Down, deleteSchemaMigration, migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+			}
+			m.sLog.InfoContext(ctx, env.MSG+"Migrator.Down", "version", migration.Version, "name", migration.Name)
+			result = migration
+			return nil
+		}
+		return ErrNoMigrations
+	})
+	return result, err
+}
+
+// Status — состояние всех известных миграций.
+func (m *Migrator) Status(ctx context.Context) (result []Status, err error) {
+
+	err = m.locked(ctx, func(_ *pgxpool.Conn, applied map[int64]time.Time) error {
+		for _, migration := range m.migrations {
+			result = append(result, Status{Migration: migration, AppliedAt: applied[migration.Version]})
+		}
+		return nil
+	})
+	return result, err
+}
+
+// locked выполнение f на одном подключении под pg_advisory_lock,
+// f получает применённые версии из schema_migrations.
+func (m *Migrator) locked(ctx context.Context, f func(*pgxpool.Conn, map[int64]time.Time) error) error {
+
+	if m.pool == nil {
+		return ErrBadPool
+	}
+	conn, err := m.pool.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockKey); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockKey); err != nil {
+			m.sLog.ErrorContext(ctx, env.MSG+"Migrator.locked", "msg", "advisory unlock", "err", err)
+		}
+	}()
+	if _, err = conn.Exec(ctx, createSchemaMigrations); err != nil {
+		return err
+	}
+	applied, err := appliedVersions(ctx, conn)
+
+	if err != nil {
+		return err
+	}
+	return f(conn, applied)
+}
+
+func appliedVersions(ctx context.Context, conn *pgxpool.Conn) (map[int64]time.Time, error) {
+
+	rows, err := conn.Query(ctx, selectSchemaMigration)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make(map[int64]time.Time)
+
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		result[version] = appliedAt
+	}
+	return result, rows.Err()
+}
+
+// apply выполнение SQL миграции и учёт версии в одной транзакции.
+func apply(ctx context.Context, conn *pgxpool.Conn, sql, record string, args ...any) error {
+
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, record, args...)
+		return err
+	})
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
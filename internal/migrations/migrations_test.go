@@ -0,0 +1,97 @@
+/*
+ * This file was last modified at 2026-10-18 18:20 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * migrations_test.go
+ * $Id$
+ */
+//!+
+
+package migrations
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMigrations(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for function Load embedded migrations",
+			func(_ *testing.T) (interface{}, error) { return Load(embedded) },
+			positiveLoadEmbeddedCheck,
+		},
+		{
+			"test #1 positive for function Load ordered by version",
+			positiveLoadOrdered,
+			positiveLoadOrderedCheck,
+		},
+		{
+			"test #2 negative for function Load without down file",
+			negativeLoadWithoutDown,
+			func(t *testing.T, i interface{}) bool { return assert.ErrorContains(t, i.(error), "both up and down") },
+		},
+		{
+			"test #3 negative for function Up without database pool",
+			negativeUpWithoutPool,
+			func(t *testing.T, i interface{}) bool { return assert.ErrorIs(t, i.(error), ErrBadPool) },
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveLoadEmbeddedCheck(t *testing.T, i interface{}) bool {
+
+	migrations := i.([]Migration)
+
+	return assert.NotEmpty(t, migrations) &&
+		assert.Equal(t, int64(1), migrations[0].Version) &&
+		assert.Equal(t, "create_key_value", migrations[0].Name) &&
+		assert.Contains(t, migrations[0].Up, "CREATE TABLE IF NOT EXISTS key_value") &&
+		assert.Contains(t, migrations[0].Down, "DROP TABLE IF EXISTS key_value")
+}
+
+func positiveLoadOrdered(_ *testing.T) (interface{}, error) {
+	return Load(fstest.MapFS{
+		"sql/0010_second.up.sql":   {Data: []byte("up 10")},
+		"sql/0010_second.down.sql": {Data: []byte("down 10")},
+		"sql/0002_first.up.sql":    {Data: []byte("up 2")},
+		"sql/0002_first.down.sql":  {Data: []byte("down 2")},
+		"sql/README.md":            {Data: []byte("ignored")},
+	})
+}
+
+func positiveLoadOrderedCheck(t *testing.T, i interface{}) bool {
+	return assert.Equal(t, []Migration{
+		{Version: 2, Name: "first", Up: "up 2", Down: "down 2"},
+		{Version: 10, Name: "second", Up: "up 10", Down: "down 10"},
+	}, i)
+}
+
+// negative-функции возвращают ожидаемую ошибку как результат.
+func negativeLoadWithoutDown(_ *testing.T) (interface{}, error) {
+	_, err := Load(fstest.MapFS{"sql/0001_first.up.sql": {Data: []byte("up 1")}})
+	return err, nil
+}
+
+func negativeUpWithoutPool(_ *testing.T) (interface{}, error) {
+	m := &Migrator{sLog: slog.Default()}
+	_, err := m.Up(context.Background())
+	return err, nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
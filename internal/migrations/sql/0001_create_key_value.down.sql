@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS key_value_key_pattern_idx;
+
+DROP TABLE IF EXISTS key_value;
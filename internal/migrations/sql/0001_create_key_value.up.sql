@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS key_value (
+    key        TEXT PRIMARY KEY,
+    value      TEXT NOT NULL DEFAULT '',
+    version    BIGINT NOT NULL DEFAULT 1,
+    deleted    BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS key_value_key_pattern_idx
+    ON key_value (key text_pattern_ops)
+    WHERE NOT deleted;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS key_value_outbox_pending_idx;
+
+DROP TABLE IF EXISTS key_value_outbox;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS key_value_outbox (
+    id           BIGSERIAL PRIMARY KEY,
+    key          TEXT NOT NULL,
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    last_error   TEXT,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    processed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS key_value_outbox_pending_idx
+    ON key_value_outbox (id)
+    WHERE processed_at IS NULL;
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS key_value_history_trigger ON key_value;
+
+DROP FUNCTION IF EXISTS key_value_history_append();
+
+DROP INDEX IF EXISTS key_value_history_key_idx;
+
+DROP TABLE IF EXISTS key_value_history;
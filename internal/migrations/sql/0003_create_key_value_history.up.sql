@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS key_value_history (
+    id         BIGSERIAL PRIMARY KEY,
+    key        TEXT NOT NULL,
+    value      TEXT NOT NULL DEFAULT '',
+    version    BIGINT NOT NULL,
+    deleted    BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS key_value_history_key_idx
+    ON key_value_history (key, id);
+
+CREATE OR REPLACE FUNCTION key_value_history_append() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO key_value_history (key, value, version, deleted, created_at)
+    VALUES (NEW.key, NEW.value, NEW.version, NEW.deleted, NEW.created_at);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS key_value_history_trigger ON key_value;
+
+CREATE TRIGGER key_value_history_trigger
+    AFTER INSERT OR UPDATE ON key_value
+    FOR EACH ROW EXECUTE FUNCTION key_value_history_append();
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS key_value_history_changed_at_idx;
+
+DROP INDEX IF EXISTS key_value_history_revision_idx;
+
+ALTER TABLE key_value_history DROP COLUMN IF EXISTS revision;
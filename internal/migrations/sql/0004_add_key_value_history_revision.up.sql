@@ -0,0 +1,8 @@
+ALTER TABLE key_value_history ADD COLUMN IF NOT EXISTS revision BIGINT;
+
+CREATE INDEX IF NOT EXISTS key_value_history_revision_idx
+    ON key_value_history (key, revision)
+    WHERE revision IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS key_value_history_changed_at_idx
+    ON key_value_history (key, changed_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS key_value_deleted_idx;
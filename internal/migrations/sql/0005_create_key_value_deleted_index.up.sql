@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS key_value_deleted_idx
+    ON key_value (updated_at)
+    WHERE deleted;
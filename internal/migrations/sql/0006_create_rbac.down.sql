@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS rbac_binding;
+
+DROP TABLE IF EXISTS rbac_role_rule;
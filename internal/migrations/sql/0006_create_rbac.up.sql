@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS rbac_role_rule (
+    role       TEXT NOT NULL,
+    prefix     TEXT NOT NULL,
+    actions    TEXT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (role, prefix)
+);
+
+CREATE TABLE IF NOT EXISTS rbac_binding (
+    subject    TEXT NOT NULL,
+    role       TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (subject, role)
+);
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS key_value_history_update_trigger ON key_value;
+
+DROP TRIGGER IF EXISTS key_value_history_trigger ON key_value;
+
+CREATE TRIGGER key_value_history_trigger
+    AFTER INSERT OR UPDATE ON key_value
+    FOR EACH ROW EXECUTE FUNCTION key_value_history_append();
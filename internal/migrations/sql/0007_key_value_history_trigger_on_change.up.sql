@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS key_value_history_trigger ON key_value;
+
+CREATE TRIGGER key_value_history_trigger
+    AFTER INSERT ON key_value
+    FOR EACH ROW EXECUTE FUNCTION key_value_history_append();
+
+CREATE TRIGGER key_value_history_update_trigger
+    AFTER UPDATE ON key_value
+    FOR EACH ROW
+    WHEN (OLD.version IS DISTINCT FROM NEW.version OR OLD.deleted IS DISTINCT FROM NEW.deleted)
+    EXECUTE FUNCTION key_value_history_append();
@@ -0,0 +1,224 @@
+/*
+ * This file was last modified at 2026-10-18 22:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * enforcer.go
+ * $Id$
+ */
+//!+
+
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/auth"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultRefreshInterval = time.Minute
+
+const (
+	selectRoleRules = `SELECT role, prefix, actions FROM rbac_role_rule ORDER BY role, prefix`
+	selectBindings  = `SELECT subject, role FROM rbac_binding ORDER BY subject, role`
+)
+
+// Enforcer проверка прав клиента из контекста запроса (auth.FromContext)
+// по текущей политике. Nil или выключенный Enforcer разрешает всё,
+// клиент без Principal при включённой проверке не допускается.
+type Enforcer struct {
+	config env.RBACConfig
+	dbPool *pgxpool.Pool
+	policy atomic.Pointer[Policy]
+	sLog   *slog.Logger
+}
+
+var (
+	onceEnforcer = new(sync.Once)
+	enforcerInst *Enforcer
+)
+
+// GetEnforcer — потокобезопасное (thread-safe) создание проверки прав
+// доступа, при хранении ролей в PostgreSQL политика перечитывается
+// в фоне до отмены ctx.
+func GetEnforcer(ctx context.Context, cfg env.Config) *Enforcer {
+
+	onceEnforcer.Do(func() {
+		enforcerInst = &Enforcer{config: cfg.RBACConfig(), dbPool: cfg.DBPool(), sLog: cfg.Logger()}
+
+		if !enforcerInst.config.Enabled {
+			return
+		}
+		if err := enforcerInst.Reload(ctx); err != nil {
+			enforcerInst.sLog.ErrorContext(ctx, env.MSG+"GetEnforcer", "msg", "policy", "err", err)
+		}
+		if enforcerInst.config.Postgres {
+			go enforcerInst.refreshLoop(ctx)
+		}
+	})
+	return enforcerInst
+}
+
+// NewEnforcer проверка прав по ролям и назначениям из config без PostgreSQL.
+func NewEnforcer(config env.RBACConfig, sLog *slog.Logger) (*Enforcer, error) {
+
+	result := &Enforcer{config: config, sLog: sLog}
+	policy, err := NewPolicy(config.Roles, config.Bindings)
+
+	if err != nil {
+		return result, err
+	}
+	result.policy.Store(policy)
+
+	return result, nil
+}
+
+// Authorize ErrPermissionDenied, если действие action над ключом key не разрешено.
+func (e *Enforcer) Authorize(ctx context.Context, action Action, key string) error {
+	return e.authorize(ctx, action, key, (*Policy).Allowed)
+}
+
+// AuthorizeAny ErrPermissionDenied, если действие action не разрешено ни над одним ключом.
+func (e *Enforcer) AuthorizeAny(ctx context.Context, action Action) error {
+	return e.authorize(ctx, action, "", func(p *Policy, subject string, action Action, _ string) bool {
+		return p.AllowedAny(subject, action)
+	})
+}
+
+// AuthorizePrefix ErrPermissionDenied, если действие action не разрешено
+// ни над одним ключом с префиксом prefix.
+func (e *Enforcer) AuthorizePrefix(ctx context.Context, action Action, prefix string) error {
+	return e.authorize(ctx, action, prefix, (*Policy).AllowedPrefix)
+}
+
+// Filter проверка ключей выборки или подписки: false — ключ скрывается от клиента.
+func (e *Enforcer) Filter(ctx context.Context, action Action) func(key string) bool {
+
+	if e == nil || !e.config.Enabled {
+		return func(string) bool { return true }
+	}
+	p, ok := auth.FromContext(ctx)
+	policy := e.policy.Load()
+
+	return func(key string) bool {
+		return ok && policy.Allowed(p.String(), action, key)
+	}
+}
+
+// Reload повторное чтение политики: роли и назначения из конфигурации
+// дополняются прочитанными из PostgreSQL. При ошибке остаётся прежняя политика.
+func (e *Enforcer) Reload(ctx context.Context) error {
+
+	roles := make(map[string][]env.RBACRule, len(e.config.Roles))
+	bindings := make(map[string][]string, len(e.config.Bindings))
+
+	for name, rules := range e.config.Roles {
+		roles[name] = append(roles[name], rules...)
+	}
+	for subject, names := range e.config.Bindings {
+		bindings[subject] = append(bindings[subject], names...)
+	}
+	if e.config.Postgres {
+		if err := e.loadPostgres(ctx, roles, bindings); err != nil {
+			return err
+		}
+	}
+	policy, err := NewPolicy(roles, bindings)
+
+	if err != nil {
+		return err
+	}
+	e.policy.Store(policy)
+
+	return nil
+}
+
+func (e *Enforcer) authorize(
+	ctx context.Context,
+	action Action,
+	key string,
+	allowed func(*Policy, string, Action, string) bool,
+) error {
+
+	if e == nil || !e.config.Enabled {
+		return nil
+	}
+	p, ok := auth.FromContext(ctx)
+
+	if !ok {
+		return fmt.Errorf("%w: %s %q: unauthenticated", ErrPermissionDenied, action, key)
+	}
+	if !allowed(e.policy.Load(), p.String(), action, key) {
+		e.sLog.WarnContext(ctx, env.MSG+"Enforcer.authorize", "msg", "denied", "action", action, "key", key)
+		return fmt.Errorf("%w: %s %q", ErrPermissionDenied, action, key)
+	}
+	return nil
+}
+
+func (e *Enforcer) loadPostgres(ctx context.Context, roles map[string][]env.RBACRule, bindings map[string][]string) error {
+
+	if e.dbPool == nil {
+		return fmt.Errorf("rbac postgres: database disabled")
+	}
+	rows, err := e.dbPool.Query(ctx, selectRoleRules)
+
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name string
+		var rule env.RBACRule
+
+		if err = rows.Scan(&name, &rule.Prefix, &rule.Actions); err != nil {
+			rows.Close()
+			return err
+		}
+		roles[name] = append(roles[name], rule)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	if rows, err = e.dbPool.Query(ctx, selectBindings); err != nil {
+		return err
+	}
+	for rows.Next() {
+		var subject, role string
+
+		if err = rows.Scan(&subject, &role); err != nil {
+			rows.Close()
+			return err
+		}
+		bindings[subject] = append(bindings[subject], role)
+	}
+	return rows.Err()
+}
+
+func (e *Enforcer) refreshLoop(ctx context.Context) {
+
+	interval := e.config.RefreshInterval
+
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Reload(ctx); err != nil {
+				e.sLog.ErrorContext(ctx, env.MSG+"Enforcer.refreshLoop", "err", err)
+			}
+		}
+	}
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
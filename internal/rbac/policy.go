@@ -0,0 +1,144 @@
+/*
+ * This file was last modified at 2026-10-18 22:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * policy.go
+ * $Id$
+ */
+//!+
+
+// Package rbac проверка прав доступа клиентов к ключам: роль разрешает
+// действия (read, write, delete, watch) над ключами с заданным префиксом.
+package rbac
+
+import (
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/auth"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"strings"
+)
+
+type Action string
+
+const (
+	ActionDelete Action = "delete"
+	ActionRead   Action = "read"
+	ActionWatch  Action = "watch"
+	ActionWrite  Action = "write"
+)
+
+var (
+	ErrBadAction        = fmt.Errorf("bad rbac action")
+	ErrBadSubject       = fmt.Errorf("bad rbac subject")
+	ErrPermissionDenied = fmt.Errorf("permission denied")
+)
+
+// Rule действия, разрешённые для ключей, начинающихся с Prefix;
+// пустой Prefix — все ключи.
+type Rule struct {
+	Actions map[Action]struct{}
+	Prefix  string
+}
+
+// Policy правила ролей и назначенные клиентам роли.
+type Policy struct {
+	bindings map[string][]string
+	roles    map[string][]Rule
+}
+
+// NewPolicy политика из правил ролей roles и назначений bindings
+// (клиент в виде Principal.String(), например api_key:name → имена ролей),
+// неизвестное действие или клиент без способа проверки — ошибка.
+func NewPolicy(roles map[string][]env.RBACRule, bindings map[string][]string) (*Policy, error) {
+
+	result := &Policy{
+		bindings: make(map[string][]string, len(bindings)),
+		roles:    make(map[string][]Rule, len(roles)),
+	}
+	for subject, names := range bindings {
+		if err := checkSubject(subject); err != nil {
+			return nil, err
+		}
+		result.bindings[subject] = append(result.bindings[subject], names...)
+	}
+	for name, rules := range roles {
+		for _, rule := range rules {
+			r, err := makeRule(rule)
+
+			if err != nil {
+				return nil, fmt.Errorf("role %s: %w", name, err)
+			}
+			result.roles[name] = append(result.roles[name], r)
+		}
+	}
+	return result, nil
+}
+
+// Allowed разрешено ли клиенту subject действие action над ключом key.
+func (p *Policy) Allowed(subject string, action Action, key string) bool {
+	return p.any(subject, action, func(rule Rule) bool {
+		return strings.HasPrefix(key, rule.Prefix)
+	})
+}
+
+// AllowedPrefix разрешено ли клиенту subject действие action хотя бы
+// над частью ключей с префиксом prefix: остальные ключи отфильтровываются.
+func (p *Policy) AllowedPrefix(subject string, action Action, prefix string) bool {
+	return p.any(subject, action, func(rule Rule) bool {
+		return strings.HasPrefix(prefix, rule.Prefix) || strings.HasPrefix(rule.Prefix, prefix)
+	})
+}
+
+// AllowedAny разрешено ли клиенту subject действие action над какими-либо ключами.
+func (p *Policy) AllowedAny(subject string, action Action) bool {
+	return p.any(subject, action, func(Rule) bool { return true })
+}
+
+func (p *Policy) any(subject string, action Action, match func(Rule) bool) bool {
+
+	if p == nil {
+		return false
+	}
+	for _, role := range p.bindings[subject] {
+		for _, rule := range p.roles[role] {
+			if _, ok := rule.Actions[action]; ok && match(rule) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkSubject клиент назначения должен быть задан вместе со способом
+// проверки: одно имя у API-ключа, JWT и сертификата — разные клиенты.
+func checkSubject(subject string) error {
+
+	method, name, ok := strings.Cut(subject, ":")
+
+	if !ok || name == "" {
+		return fmt.Errorf("%w: %q", ErrBadSubject, subject)
+	}
+	switch method {
+	case auth.MethodAPIKey, auth.MethodJWT, auth.MethodMTLS:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrBadSubject, subject)
+}
+
+func makeRule(rule env.RBACRule) (Rule, error) {
+
+	result := Rule{Actions: make(map[Action]struct{}, len(rule.Actions)), Prefix: rule.Prefix}
+
+	for _, name := range rule.Actions {
+		switch action := Action(strings.ToLower(name)); action {
+		case ActionDelete, ActionRead, ActionWatch, ActionWrite:
+			result.Actions[action] = struct{}{}
+		default:
+			return Rule{}, fmt.Errorf("%w: %q", ErrBadAction, name)
+		}
+	}
+	return result, nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,206 @@
+/*
+ * This file was last modified at 2026-10-18 22:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * rbac_test.go
+ * $Id$
+ */
+//!+
+
+package rbac
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/auth"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"log/slog"
+	"testing"
+)
+
+func TestRBAC(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for method Policy.Allowed(string, Action, string)",
+			positivePolicyAllowed,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []bool{true, true, false, false, false}, i)
+			},
+		},
+		{
+			"test #1 positive for method Policy.AllowedPrefix(string, Action, string)",
+			positivePolicyAllowedPrefix,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []bool{true, true, false}, i)
+			},
+		},
+		{
+			"test #2 negative for function NewPolicy(...) bad action",
+			negativeNewPolicy,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(bool)) },
+		},
+		{
+			"test #3 negative for method Enforcer.Authorize(...) without principal",
+			negativeEnforcerNoPrincipal,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(bool)) },
+		},
+		{
+			"test #4 positive for method Enforcer.Filter(context.Context, Action)",
+			positiveEnforcerFilter,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []bool{true, false, false}, i)
+			},
+		},
+		{
+			"test #5 positive for method Enforcer.Authorize(...) disabled",
+			positiveEnforcerDisabled,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(bool)) },
+		},
+		{
+			"test #6 negative for function NewPolicy(...) subject without method",
+			negativeNewPolicySubject,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(bool)) },
+		},
+		{
+			"test #7 negative for method Enforcer.Authorize(...) same name with other method",
+			negativeEnforcerMethodCollision,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []bool{true, false, false}, i)
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positivePolicyAllowed(_ *testing.T) (interface{}, error) {
+
+	policy, err := NewPolicy(testRoles(), testBindings())
+
+	if err != nil {
+		return nil, err
+	}
+	return []bool{
+		policy.Allowed("api_key:test", ActionRead, "/team-a/config"),
+		policy.Allowed("api_key:test", ActionWrite, "/team-a/app/key"),
+		policy.Allowed("api_key:test", ActionWrite, "/team-a/config"),
+		policy.Allowed("api_key:test", ActionRead, "/team-b/config"),
+		policy.Allowed("api_key:other", ActionRead, "/team-a/config"),
+	}, nil
+}
+
+func positivePolicyAllowedPrefix(_ *testing.T) (interface{}, error) {
+
+	policy, err := NewPolicy(testRoles(), testBindings())
+
+	if err != nil {
+		return nil, err
+	}
+	return []bool{
+		policy.AllowedPrefix("api_key:test", ActionWatch, "/team-a/app/"),
+		policy.AllowedPrefix("api_key:test", ActionWrite, "/team-a/"),
+		policy.AllowedPrefix("api_key:test", ActionWatch, "/team-b/"),
+	}, nil
+}
+
+func negativeNewPolicy(_ *testing.T) (interface{}, error) {
+
+	roles := map[string][]env.RBACRule{"bad": {{Actions: []string{"admin"}, Prefix: "/"}}}
+	_, err := NewPolicy(roles, nil)
+
+	return errors.Is(err, ErrBadAction), nil
+}
+
+func negativeEnforcerNoPrincipal(_ *testing.T) (interface{}, error) {
+
+	enforcer, err := testEnforcer(true)
+
+	if err != nil {
+		return nil, err
+	}
+	err = enforcer.Authorize(context.Background(), ActionRead, "/team-a/config")
+
+	return errors.Is(err, ErrPermissionDenied), nil
+}
+
+func positiveEnforcerFilter(_ *testing.T) (interface{}, error) {
+
+	enforcer, err := testEnforcer(true)
+
+	if err != nil {
+		return nil, err
+	}
+	ctx := auth.NewContext(context.Background(), auth.Principal{Method: auth.MethodAPIKey, Name: "test"})
+	allow := enforcer.Filter(ctx, ActionRead)
+	deny := enforcer.Filter(context.Background(), ActionRead)
+
+	return []bool{allow("/team-a/config"), allow("/team-b/config"), deny("/team-a/config")}, nil
+}
+
+func positiveEnforcerDisabled(_ *testing.T) (interface{}, error) {
+
+	enforcer, err := testEnforcer(false)
+
+	if err != nil {
+		return nil, err
+	}
+	return enforcer.Authorize(context.Background(), ActionDelete, "/team-b/config") == nil, nil
+}
+
+func negativeNewPolicySubject(_ *testing.T) (interface{}, error) {
+
+	_, err1 := NewPolicy(testRoles(), map[string][]string{"test": {"team-a"}})
+	_, err2 := NewPolicy(testRoles(), map[string][]string{"basic:test": {"team-a"}})
+
+	return errors.Is(err1, ErrBadSubject) && errors.Is(err2, ErrBadSubject), nil
+}
+
+func negativeEnforcerMethodCollision(_ *testing.T) (interface{}, error) {
+
+	enforcer, err := testEnforcer(true)
+
+	if err != nil {
+		return nil, err
+	}
+	result := make([]bool, 0, 3)
+
+	for _, method := range []string{auth.MethodAPIKey, auth.MethodJWT, auth.MethodMTLS} {
+		ctx := auth.NewContext(context.Background(), auth.Principal{Method: method, Name: "test"})
+		result = append(result, enforcer.Authorize(ctx, ActionRead, "/team-a/config") == nil)
+	}
+	return result, nil
+}
+
+func testEnforcer(enabled bool) (*Enforcer, error) {
+	return NewEnforcer(env.RBACConfig{
+		Bindings: testBindings(),
+		Enabled:  enabled,
+		Roles:    testRoles(),
+	}, slog.Default())
+}
+
+func testBindings() map[string][]string {
+	return map[string][]string{"api_key:test": {"team-a"}}
+}
+
+func testRoles() map[string][]env.RBACRule {
+	return map[string][]env.RBACRule{
+		"team-a": {
+			{Actions: []string{"read", "watch"}, Prefix: "/team-a/"},
+			{Actions: []string{"read", "write", "delete", "watch"}, Prefix: "/team-a/app/"},
+		},
+	}
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
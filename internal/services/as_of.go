@@ -0,0 +1,153 @@
+/*
+ * This file was last modified at 2026-10-18 20:30 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * as_of.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/rbac"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+const (
+	AsOfSourceEtcd     = "etcd"
+	AsOfSourcePostgres = "postgres"
+)
+
+var (
+	ErrAsOfConflict    = fmt.Errorf("revision and at are mutually exclusive")
+	ErrAsOfUnsupported = fmt.Errorf("revision and at are not supported by etcd-proxy")
+	ErrBadRevision     = fmt.Errorf("revision must be positive")
+)
+
+// getAsOf запись key на момент asOf и хранилище, из которого она прочитана.
+func (k *keyValueDataService) getAsOf(ctx context.Context, key string, asOf dto.AsOf) (entity.KeyValue, string, error) {
+
+	if err := k.enforcer.Authorize(ctx, rbac.ActionRead, key); err != nil {
+		return entity.KeyValue{}, "", err
+	}
+	got, source, err := k.readAsOf(ctx, key, key, 1, false, asOf)
+
+	if err != nil {
+		return entity.KeyValue{}, "", err
+	}
+	if len(got) == 0 || got[0].Key() != key {
+		return entity.KeyValue{}, source, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return got[0], source, nil
+}
+
+// getPoint текущее значение записи или, если задан asOf, значение на момент asOf.
+func (k *keyValueDataService) getPoint(ctx context.Context, key string, asOf dto.AsOf) (entity.KeyValue, string, error) {
+
+	if asOf.IsZero() {
+		got, err := k.get(ctx, key)
+		return got, "", err
+	}
+	return k.getAsOf(ctx, key, asOf)
+}
+
+// listAsOf страница записей по префиксу на момент asOf и хранилище,
+// из которого она прочитана.
+func (k *keyValueDataService) listAsOf(
+	ctx context.Context,
+	prefix, from string,
+	limit int64,
+	keysOnly bool,
+	asOf dto.AsOf,
+) (dto.List[entity.KeyValue], string, error) {
+
+	allow, err := authorizeList(ctx, k.enforcer, prefix)
+
+	if err != nil {
+		return dto.List[entity.KeyValue]{}, "", err
+	}
+	limit = listLimit(limit)
+	got, source, err := k.readAsOf(ctx, prefix, from, listFetchLimit(limit), keysOnly, asOf)
+
+	if err != nil {
+		return dto.List[entity.KeyValue]{}, "", err
+	}
+	return makeListPage(got, limit, allow, listItem(keysOnly)), source, nil
+}
+
+// readAsOf чтение по ревизии выполняется в etcd, пока ревизия не удалена
+// уплотнением (compaction), иначе и при чтении на момент времени —
+// из key_value_history в PostgreSQL.
+func (k *keyValueDataService) readAsOf(
+	ctx context.Context,
+	prefix, from string,
+	limit int64,
+	keysOnly bool,
+	asOf dto.AsOf,
+) ([]entity.KeyValue, string, error) {
+
+	if asOf.Revision != 0 && !asOf.At.IsZero() {
+		return nil, "", ErrAsOfConflict
+	}
+	if asOf.At.IsZero() {
+		if asOf.Revision < 1 {
+			return nil, "", ErrBadRevision
+		}
+		got, err := entity.ListKeyValueAsOf(ctx, k.etcdRepo, prefix, from, limit, keysOnly, asOf.Revision, time.Time{})
+
+		if err == nil {
+			return got, AsOfSourceEtcd, nil
+		}
+		if !errors.Is(err, rpctypes.ErrCompacted) {
+			k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.readAsOf", "msg", "etcd read failed", "err", err)
+			return nil, "", err
+		}
+		k.sLog.InfoContext(ctx, env.MSG+"keyValueDataService.readAsOf",
+			"msg", "revision compacted, read postgres history", "revision", asOf.Revision,
+		)
+	}
+	got, err := entity.ListKeyValueAsOf(ctx, k.postgresRepo, prefix, from, limit, keysOnly, asOf.Revision, asOf.At)
+
+	if err != nil {
+		k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.readAsOf", "msg", "postgres history failed", "err", err)
+		return nil, "", err
+	}
+	return got, AsOfSourcePostgres, nil
+}
+
+// markRevision сохранение в key_value_history ревизии etcd, в которой
+// записан ключ, для чтения по ревизии после уплотнения etcd. Строка
+// истории выбирается по версии записи unit, которую вернул PostgreSQL.
+func (k *keyValueDataService) markRevision(ctx context.Context, unit entity.KeyValue, revision int64) {
+
+	if revision < 1 || unit.Version() < 1 {
+		return
+	}
+	if marked, err := entity.MarkKeyValueRevision(ctx, k.postgresRepo, unit, revision); err != nil {
+		k.sLog.WarnContext(ctx, env.MSG+"keyValueDataService.markRevision", "key", unit.Key(), "revision", revision, "err", err)
+	} else if !marked {
+		k.sLog.DebugContext(ctx, env.MSG+"keyValueDataService.markRevision", "msg", "no history", "key", unit.Key())
+	}
+}
+
+func makeAsOf(revision int64, at *timestamppb.Timestamp) dto.AsOf {
+
+	result := dto.AsOf{Revision: revision}
+
+	if at != nil {
+		result.At = at.AsTime()
+	}
+	return result
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
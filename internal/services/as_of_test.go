@@ -0,0 +1,152 @@
+/*
+ * This file was last modified at 2026-10-18 20:30 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * as_of_test.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestAsOf(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for method getAsOf(context.Context, string, dto.AsOf) by revision from etcd",
+			positiveGetAsOfEtcd,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, AsOfSourceEtcd, i) },
+		},
+		{
+			"test #1 positive for method getAsOf(context.Context, string, dto.AsOf) compacted revision",
+			positiveGetAsOfCompacted,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, AsOfSourcePostgres, i) },
+		},
+		{
+			"test #2 positive for method listAsOf(context.Context, string, string, int64, bool, dto.AsOf) by time",
+			positiveListAsOfAt,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, []string{"key1", "key2"}, i) },
+		},
+		{
+			"test #3 negative for method getAsOf(context.Context, string, dto.AsOf) revision and at",
+			negativeGetAsOfConflict,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, errors.Is(i.(error), ErrAsOfConflict)) },
+		},
+		{
+			"test #4 positive for method markRevision(context.Context, entity.KeyValue, int64) by written version",
+			positiveMarkRevision,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(bool)) },
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveGetAsOfEtcd(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	mocks.etcd.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueListAsOf("key1", 1, false, 5, time.Time{}), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{MakeKeyValueNow("key1", "value1")}, nil).
+		Times(1)
+
+	_, source, err := srv.getAsOf(context.Background(), "key1", dto.AsOf{Revision: 5})
+
+	return source, err
+}
+
+// positiveGetAsOfCompacted ревизия удалена уплотнением etcd и читается
+// из истории PostgreSQL.
+func positiveGetAsOfCompacted(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	action := entity.MakeKeyValueListAsOf("key1", 1, false, 5, time.Time{})
+	mocks.etcd.
+		EXPECT().
+		Get(gomock.Any(), action, gomock.Any(), gomock.Any()).
+		Return(nil, rpctypes.ErrCompacted).
+		Times(1)
+	mocks.postgres.
+		EXPECT().
+		Get(gomock.Any(), action, gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{MakeKeyValueNow("key1", "value1")}, nil).
+		Times(1)
+
+	_, source, err := srv.getAsOf(context.Background(), "key1", dto.AsOf{Revision: 5})
+
+	return source, err
+}
+
+// positiveListAsOfAt чтение на момент времени не обращается к etcd.
+func positiveListAsOfAt(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	at := time.Now().Add(-time.Hour)
+	mocks.postgres.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueListAsOf("", listFetchLimit(DefaultListLimit), false, 0, at), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{MakeKeyValueNow("key1", "value1"), MakeKeyValueNow("key2", "value2")}, nil).
+		Times(1)
+
+	got, source, err := srv.listAsOf(context.Background(), "key", "", 0, false, dto.AsOf{At: at})
+
+	if err != nil || source != AsOfSourcePostgres {
+		return nil, err
+	}
+	keys := make([]string, 0, len(got.Items))
+
+	for _, item := range got.Items {
+		keys = append(keys, item.Key())
+	}
+	return keys, nil
+}
+
+// positiveMarkRevision ревизия сопоставляется строке истории с версией,
+// которую вернула запись в PostgreSQL; запись без версии не сопоставляется.
+func positiveMarkRevision(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	written := entity.MakeKeyValue("key1", "value1", 3, entity.DefaultTAttributes())
+	mocks.postgres.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueRevisionMark(7), written, gomock.Any()).
+		Return([]entity.KeyValue{written}, nil).
+		Times(1)
+
+	srv.markRevision(context.Background(), written, 7)
+	srv.markRevision(context.Background(), MakeKeyValueNow("key1", "value1"), 8)
+
+	return true, nil
+}
+
+func negativeGetAsOfConflict(t *testing.T) (interface{}, error) {
+
+	srv, _ := newTestKeyValueDataServiceWithOutbox(t)
+	_, _, err := srv.getAsOf(context.Background(), "key1", dto.AsOf{At: time.Now(), Revision: 5})
+
+	return err, nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
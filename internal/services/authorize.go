@@ -0,0 +1,66 @@
+/*
+ * This file was last modified at 2026-10-18 22:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * authorize.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/rbac"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// authorizeList выборка по префиксу допускается, если клиенту доступна
+// хотя бы часть ключей префикса, остальные ключи отфильтровываются.
+func authorizeList(ctx context.Context, enforcer *rbac.Enforcer, prefix string) (func(string) bool, error) {
+
+	if err := enforcer.AuthorizePrefix(ctx, rbac.ActionRead, prefix); err != nil {
+		return nil, err
+	}
+	return enforcer.Filter(ctx, rbac.ActionRead), nil
+}
+
+// authorizeTxn проверка до выполнения транзакции: чтение ключей условий
+// и запись или удаление ключей обеих ветвей, так как выполняемая ветвь
+// заранее неизвестна.
+func authorizeTxn(ctx context.Context, enforcer *rbac.Enforcer, request dto.TxnRequest) error {
+
+	for _, compare := range request.Compare {
+		if err := enforcer.Authorize(ctx, rbac.ActionRead, compare.Key); err != nil {
+			return err
+		}
+	}
+	for _, op := range append(append([]dto.TxnOp{}, request.Success...), request.Failure...) {
+		action := rbac.ActionWrite
+
+		if strings.ToUpper(op.Type) == dto.EventDelete {
+			action = rbac.ActionDelete
+		}
+		if err := enforcer.Authorize(ctx, action, op.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// permissionDenied ошибка gRPC PermissionDenied для запрещённого политикой
+// доступа, nil — для остальных ошибок, передаваемых в поле Error ответа.
+func permissionDenied(err error) error {
+
+	if errors.Is(err, rbac.ErrPermissionDenied) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,102 @@
+/*
+ * This file was last modified at 2026-10-18 22:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * authorize_test.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/auth"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/rbac"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"testing"
+
+	pb "github.com/victor-skurikhin/etcd-client/v1/proto"
+)
+
+func TestAuthorize(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for method list(...) filtered by rbac",
+			positiveAuthorizeList,
+			func(t *testing.T, i interface{}) bool {
+				result := i.(dto.List[entity.KeyValue])
+				return assert.Len(t, result.Items, 1) && assert.Equal(t, "key1", result.Items[0].Key())
+			},
+		},
+		{
+			"test #1 negative for method Get(context.Context, *pb.KeyValueDataRequest) permission denied",
+			negativeAuthorizeGRPCGet,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, codes.PermissionDenied, i) },
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveAuthorizeList(t *testing.T) (interface{}, error) {
+
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+	srv := newTestKeyValueDataServiceWithList(t)
+	enforcer, err := newTestEnforcer("key1")
+
+	if err != nil {
+		return nil, err
+	}
+	srv.enforcer = enforcer
+
+	return srv.ApiList(newTestPrincipalContext(), "key", "", 5, false)
+}
+
+func negativeAuthorizeGRPCGet(t *testing.T) (interface{}, error) {
+
+	srv, _ := newTestKeyValueDataServiceWithOutbox(t)
+	enforcer, err := newTestEnforcer("key1")
+
+	if err != nil {
+		return nil, err
+	}
+	srv.enforcer = enforcer
+	_, err = srv.Get(newTestPrincipalContext(), &pb.KeyValueDataRequest{
+		Union: &pb.KeyValueDataRequest_Key{Key: &pb.Key{Key: "key2"}},
+	})
+	return status.Code(err), nil
+}
+
+func newTestEnforcer(prefix string) (*rbac.Enforcer, error) {
+	return rbac.NewEnforcer(env.RBACConfig{
+		Bindings: map[string][]string{"api_key:test": {"reader"}},
+		Enabled:  true,
+		Roles: map[string][]env.RBACRule{
+			"reader": {{Actions: []string{"read"}, Prefix: prefix}},
+		},
+	}, slog.Default())
+}
+
+func newTestPrincipalContext() context.Context {
+	return auth.NewContext(context.Background(), auth.Principal{Method: auth.MethodAPIKey, Name: "test"})
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
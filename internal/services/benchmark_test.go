@@ -0,0 +1,118 @@
+/*
+ * This file was last modified at 2026-10-18 16:05 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * benchmark_test.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/memory"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/pool"
+	"github.com/victor-skurikhin/etcd-client/v1/pool/etcd_pool"
+	"log/slog"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	clientV3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+const benchmarkKeys = 100
+
+// BenchmarkEtcdProxyServicePut сравнение задержки ApiPut с новым клиентом
+// на каждый запрос и с клиентами из пула на встроенном сервере etcd.
+func BenchmarkEtcdProxyServicePut(b *testing.B) {
+
+	clientConfig := startEmbedEtcd(b)
+
+	for _, bench := range []struct {
+		name string
+		pool pool.EtcdPool
+	}{
+		{"single_fabric", etcd_pool.NewSingleFabric(clientConfig)},
+		{"pool", etcd_pool.New(clientConfig, env.PoolConfig{MinIdle: 1}, slog.Default())},
+	} {
+		b.Cleanup(func() { _ = bench.pool.GracefulClose() })
+		b.Run(bench.name, func(b *testing.B) {
+			srv := newTestEtcdProxyService(b, clientConfig, bench.pool)
+			ctx := context.Background()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				data := dto.KeyValue{Key: fmt.Sprintf("bench%d", i%benchmarkKeys), Value: "value"}
+
+				if _, err := srv.ApiPut(ctx, data, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func newTestEtcdProxyService(b *testing.B, clientConfig clientV3.Config, pool pool.EtcdPool) *etcdProxyService {
+
+	client, err := clientV3.New(clientConfig)
+
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { _ = client.Close() })
+	inst := new(etcdProxyService)
+	inst.cache = memory.New(memory.Config{})
+	inst.client = client
+	inst.pool = pool
+	inst.sLog = slog.Default()
+
+	return inst
+}
+
+func startEmbedEtcd(b *testing.B) clientV3.Config {
+
+	cfg := embed.NewConfig()
+	cfg.Dir = b.TempDir()
+	cfg.LogLevel = "error"
+	clientURL, peerURL := freeURL(b), freeURL(b)
+	cfg.ListenClientUrls = []url.URL{clientURL}
+	cfg.AdvertiseClientUrls = []url.URL{clientURL}
+	cfg.ListenPeerUrls = []url.URL{peerURL}
+	cfg.AdvertisePeerUrls = []url.URL{peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+	server, err := embed.StartEtcd(cfg)
+
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(server.Close)
+
+	select {
+	case <-server.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		b.Fatal("embedded etcd server took too long to start")
+	}
+	return clientV3.Config{Endpoints: []string{clientURL.Host}, DialTimeout: time.Second}
+}
+
+func freeURL(b *testing.B) url.URL {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	return url.URL{Scheme: "http", Host: listener.Addr().String()}
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,124 @@
+/*
+ * This file was last modified at 2026-10-19 00:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * cache.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/memory"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"time"
+)
+
+// negativeCacheValue значение записи кэша отсутствующих ключей:
+// кэш не хранит пустые значения.
+var negativeCacheValue = []byte{0}
+
+// newCache локальный кэш по политике из настроек: ограниченный LRU или
+// неограниченный с удалением записей только по сроку действия.
+func newCache(cfg env.Config) memory.Cache {
+
+	config := cfg.CacheConfig()
+
+	if config.Policy == env.CachePolicyTTL {
+		return memory.New(memory.Config{
+			GCInterval: cfg.CacheGCInterval(),
+		})
+	}
+	return memory.NewLRU(memory.Config{
+		GCInterval: cfg.CacheGCInterval(),
+		MaxBytes:   config.MaxBytes,
+		MaxEntries: config.MaxEntries,
+	})
+}
+
+// newNegativeCache кэш отсутствующих ключей с теми же ограничениями, что
+// и основной, nil — если срок действия его записей не задан.
+func newNegativeCache(cfg env.Config) memory.Cache {
+
+	if cfg.CacheConfig().NegativeExpire <= 0 {
+		return nil
+	}
+	return newCache(cfg)
+}
+
+// cachedNotFound ключ записан в кэш отсутствующих ключей negative.
+func cachedNotFound(negative memory.Cache, key string) bool {
+
+	if negative == nil {
+		return false
+	}
+	data, err := negative.Get(key)
+
+	return err == nil && data != nil
+}
+
+// cacheNotFound запись отсутствующего ключа в кэш negative на срок expire.
+func cacheNotFound(negative memory.Cache, key string, expire time.Duration) error {
+
+	if negative == nil {
+		return nil
+	}
+	return negative.Set(key, negativeCacheValue, expire)
+}
+
+// cacheNotFoundRecheck запись отсутствующего ключа в кэш negative с
+// повторной проверкой exists после записи: ключ, созданный между чтением
+// и записью в кэш, мог прийти в подписке раньше, чем запись появилась в
+// кэше, и тогда удаляется из кэша здесь.
+func cacheNotFoundRecheck(negative memory.Cache, key string, expire time.Duration, exists func() (bool, error)) error {
+
+	if negative == nil {
+		return nil
+	}
+	if err := cacheNotFound(negative, key, expire); err != nil {
+		return err
+	}
+	found, err := exists()
+
+	if err != nil || found {
+		// При ошибке проверки отсутствие ключа не подтверждено.
+		return errors.Join(err, negative.Delete(key))
+	}
+	return nil
+}
+
+// forgetNotFound удаление ключа из кэша отсутствующих ключей negative
+// до того, как его удалит цикл подписки на инвалидацию.
+func forgetNotFound(negative memory.Cache, key string) error {
+
+	if negative == nil {
+		return nil
+	}
+	return negative.Delete(key)
+}
+
+// isNotFound ошибка чтения отсутствующего ключа, а не недоступного хранилища.
+func isNotFound(err error) bool {
+	return errors.Is(err, domain.ErrNotFound) || errors.Is(err, pgx.ErrNoRows)
+}
+
+// invalidatedCaches кэши, из которых цикл подписки удаляет
+// инвалидированные ключи.
+func invalidatedCaches(caches ...memory.Cache) []memory.Cache {
+
+	result := make([]memory.Cache, 0, len(caches))
+
+	for _, cache := range caches {
+		if cache != nil {
+			result = append(result, cache)
+		}
+	}
+	return result
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
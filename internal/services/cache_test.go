@@ -0,0 +1,168 @@
+/*
+ * This file was last modified at 2026-10-19 01:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * cache_test.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/memory"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestNegativeCache(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for method keyValueDataService.get(...) missing key read once",
+			positiveNegativeCacheGet,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []interface{}{true, true, uint64(1)}, i)
+			},
+		},
+		{
+			"test #1 negative for method keyValueDataService.get(...) stores unavailable",
+			negativeNegativeCacheGet,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []interface{}{true, 0}, i)
+			},
+		},
+		{
+			"test #2 positive for functions forgetNotFound(...) and invalidatedCaches(...)",
+			positiveForgetNotFound,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []interface{}{false, 1}, i)
+			},
+		},
+		{
+			"test #3 positive for function cacheNotFoundRecheck(...) key created before store",
+			positiveCacheNotFoundRecheck,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []bool{false, true, false}, i)
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveNegativeCacheGet(t *testing.T) (interface{}, error) {
+
+	k := newTestNegativeCacheService(t,
+		fmt.Errorf("no Kvs, length: 0: %w", domain.ErrNotFound),
+		pgx.ErrNoRows,
+		2,
+		1,
+	)
+	defer func() { _ = k.negative.Close() }()
+	_, err1 := k.get(context.Background(), "/missing")
+	_, err2 := k.get(context.Background(), "/missing")
+
+	return []interface{}{errors.Is(err1, ErrNotFound), errors.Is(err2, ErrNotFound), k.negative.Stats().Hits}, nil
+}
+
+func negativeNegativeCacheGet(t *testing.T) (interface{}, error) {
+
+	k := newTestNegativeCacheService(t,
+		fmt.Errorf("etcd unavailable"),
+		fmt.Errorf("postgres unavailable"),
+		2,
+		2,
+	)
+	defer func() { _ = k.negative.Close() }()
+	_, _ = k.get(context.Background(), "/missing")
+	_, err := k.get(context.Background(), "/missing")
+
+	return []interface{}{errors.Is(err, ErrNotFound), k.negative.Stats().Size}, nil
+}
+
+func positiveForgetNotFound(_ *testing.T) (interface{}, error) {
+
+	negative := memory.NewLRU(memory.Config{MaxEntries: 10})
+	defer func() { _ = negative.Close() }()
+
+	if err := cacheNotFound(negative, "/missing", time.Minute); err != nil {
+		return nil, err
+	}
+	if err := forgetNotFound(negative, "/missing"); err != nil {
+		return nil, err
+	}
+	return []interface{}{cachedNotFound(negative, "/missing"), len(invalidatedCaches(negative, nil))}, nil
+}
+
+// positiveCacheNotFoundRecheck ключ /created записан после чтения, и событие
+// подписки о нём обработано до записи в кэш; ключ /missing отсутствует;
+// проверка ключа /unknown не удалась.
+func positiveCacheNotFoundRecheck(_ *testing.T) (interface{}, error) {
+
+	negative := memory.NewLRU(memory.Config{MaxEntries: 10})
+	defer func() { _ = negative.Close() }()
+
+	if err := cacheNotFoundRecheck(negative, "/created", time.Minute, func() (bool, error) {
+		return true, nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := cacheNotFoundRecheck(negative, "/missing", time.Minute, func() (bool, error) {
+		return false, nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := cacheNotFoundRecheck(negative, "/unknown", time.Minute, func() (bool, error) {
+		return false, fmt.Errorf("etcd unavailable")
+	}); err == nil {
+		return nil, fmt.Errorf("error is expected")
+	}
+	return []bool{
+		cachedNotFound(negative, "/created"),
+		cachedNotFound(negative, "/missing"),
+		cachedNotFound(negative, "/unknown"),
+	}, nil
+}
+
+// newTestNegativeCacheService сервис, хранилища которого отвечают на чтение
+// ключа ошибками etcdErr и postgresErr ровно etcdTimes и postgresTimes раз.
+func newTestNegativeCacheService(t *testing.T, etcdErr, postgresErr error, etcdTimes, postgresTimes int) *keyValueDataService {
+
+	k, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	k.outboxConfig.Enabled = false
+	mocks.etcd.
+		EXPECT().
+		Do(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, etcdErr).
+		Times(etcdTimes)
+	mocks.postgres.
+		EXPECT().
+		Do(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, postgresErr).
+		Times(postgresTimes)
+	k.cache = memory.NewLRU(memory.Config{MaxEntries: 10})
+	k.negative = memory.NewLRU(memory.Config{MaxEntries: 10})
+	k.negativeExpire = time.Minute
+
+	return k
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
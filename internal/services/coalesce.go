@@ -0,0 +1,77 @@
+/*
+ * This file was last modified at 2026-10-19 01:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * coalesce.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/metrics"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	coalesceGet  = "get"
+	coalesceList = "list"
+)
+
+// coalesce объединение одновременных чтений op по одному ключу key в один
+// вызов fetch, результат которого получают все ожидающие. Чтение не
+// прерывается отменой контекста первого вызвавшего, пока его ждут другие,
+// но ограничено сроком этого контекста. Ожидающий выходит по отмене своего
+// контекста, не дожидаясь чтения. Объединённые вызовы учитываются в метрике
+// metrics.CacheCoalesced с меткой name.
+func coalesce[T any](
+	ctx context.Context,
+	group *singleflight.Group,
+	name, op, key string,
+	fetch func(context.Context) (T, error),
+) (T, error) {
+
+	var leader bool
+	ch := group.DoChan(op+"\x00"+key, func() (interface{}, error) {
+		leader = true
+		fetchCtx, cancel := detachContext(ctx)
+		defer cancel()
+		return fetch(fetchCtx)
+	})
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case result := <-ch:
+		if !leader {
+			metrics.CacheCoalesced(name, op)
+		}
+		if result.Err != nil {
+			var zero T
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	}
+}
+
+// coalesceListKey ключ объединения чтений страницы префикса.
+func coalesceListKey(prefix, from string, limit int64, keysOnly bool) string {
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%v", prefix, from, limit, keysOnly)
+}
+
+// detachContext контекст без отмены родительского, но с его сроком.
+func detachContext(ctx context.Context) (context.Context, context.CancelFunc) {
+
+	detached := context.WithoutCancel(ctx)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,133 @@
+/*
+ * This file was last modified at 2026-10-19 01:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * coalesce_test.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/singleflight"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesce(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for function coalesce(...) one fetch for concurrent calls",
+			positiveCoalesceShared,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []interface{}{int64(1), []string{"value", "value", "value", "value"}}, i)
+			},
+		},
+		{
+			"test #1 positive for function coalesce(...) fetch outlives canceled first caller",
+			positiveCoalesceCanceled,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []interface{}{true, "value"}, i)
+			},
+		},
+		{
+			"test #2 positive for function detachContext(...) keeps deadline",
+			positiveDetachContext,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []interface{}{true, nil}, i)
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveCoalesceShared(_ *testing.T) (interface{}, error) {
+
+	var (
+		calls   atomic.Int64
+		group   singleflight.Group
+		wg      sync.WaitGroup
+		release = make(chan struct{})
+		results = make([]string, 4)
+		errs    = make([]error, 4)
+	)
+	fetch := func(context.Context) (string, error) {
+		calls.Add(1)
+		<-release
+		return "value", nil
+	}
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = coalesce(context.Background(), &group, "test", coalesceGet, "/key", fetch)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	return []interface{}{calls.Load(), results}, errors.Join(errs...)
+}
+
+func positiveCoalesceCanceled(_ *testing.T) (interface{}, error) {
+
+	var group singleflight.Group
+	started, release := make(chan struct{}), make(chan struct{})
+	fetch := func(ctx context.Context) (string, error) {
+		close(started)
+		<-release
+		return "value", ctx.Err()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+
+	go func() {
+		_, err := coalesce(ctx, &group, "test", coalesceGet, "/key", fetch)
+		first <- err
+	}()
+	<-started
+	second := make(chan string, 1)
+
+	go func() {
+		result, _ := coalesce(context.Background(), &group, "test", coalesceGet, "/key", fetch)
+		second <- result
+	}()
+	cancel()
+	canceled := errors.Is(<-first, context.Canceled)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	return []interface{}{canceled, <-second}, nil
+}
+
+func positiveDetachContext(_ *testing.T) (interface{}, error) {
+
+	deadline := time.Now().Add(time.Minute)
+	parent, cancelParent := context.WithDeadline(context.Background(), deadline)
+	ctx, cancel := detachContext(parent)
+	defer cancel()
+	cancelParent()
+	got, _ := ctx.Deadline()
+
+	return []interface{}{got.Equal(deadline), ctx.Err()}, nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
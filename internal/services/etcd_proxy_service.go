@@ -11,16 +11,26 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/goccy/go-json"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/memory"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/repo"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/envelope"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/metrics"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/rbac"
+	"github.com/victor-skurikhin/etcd-client/v1/pool"
+	"github.com/victor-skurikhin/etcd-client/v1/pool/etcd_pool"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log/slog"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	pb "github.com/victor-skurikhin/etcd-client/v1/proto"
@@ -31,24 +41,38 @@ const BadOneOfUnionValue = "bad oneOf Union value"
 
 type EtcdProxyService interface {
 	pb.EtcdClientServiceServer
-	ApiDelete(ctx context.Context, key string) error
+	ApiAuthorizeWatch(ctx context.Context, prefix string) error
+	ApiDelete(ctx context.Context, key string, expectedVersion *int64) error
 	ApiGet(ctx context.Context, key string) (dto.Result, error)
-	ApiPut(ctx context.Context, data dto.KeyValue) error
+	ApiLeaseGrant(ctx context.Context, ttlSeconds int64) (dto.Lease, error)
+	ApiLeaseKeepAlive(ctx context.Context, id int64) (dto.Lease, error)
+	ApiLeaseRevoke(ctx context.Context, id int64) error
+	ApiList(ctx context.Context, prefix, from string, limit int64, keysOnly bool) (dto.List[dto.KeyValue], error)
+	ApiPut(ctx context.Context, data dto.KeyValue, expectedVersion *int64) (dto.Result, error)
+	ApiTxn(ctx context.Context, request dto.TxnRequest) (dto.TxnResult, error)
+	ApiWatch(ctx context.Context, request dto.WatchRequest, send func(dto.WatchEvent) error) error
 }
 
 type etcdProxyService struct {
 	pb.UnimplementedEtcdClientServiceServer
-	cache            *memory.Storage
+	cache            memory.Cache
 	cacheExpire      time.Duration
-	clientConfig     clientV3.Config
+	client           *clientV3.Client
+	enforcer         *rbac.Enforcer
+	envelope         *envelope.Envelope
 	etcdKeyValueRepo domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
-	hitCounter       atomic.Uint64
+	flight           singleflight.Group
+	negative         memory.Cache
+	negativeExpire   time.Duration
+	negativeWatch    bool
+	pool             pool.EtcdPool
 	postgresKeyValue domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
 	sLog             *slog.Logger
 }
 
 var _ EtcdProxyService = (*etcdProxyService)(nil)
 var (
+	ErrNoClient   = fmt.Errorf("etcd client is not available")
 	ErrNotFound   = fmt.Errorf("not found")
 	onceEtcdProxy = new(sync.Once)
 	etcdProxyServ *etcdProxyService
@@ -60,32 +84,79 @@ func GetEtcdProxyService(ctx context.Context, cfg env.Config) EtcdProxyService {
 
 	onceEtcdProxy.Do(func() {
 		etcdProxyServ = new(etcdProxyService)
-		etcdProxyServ.cache = memory.New(memory.Config{
-			GCInterval: cfg.CacheGCInterval(),
-		})
+		etcdProxyServ.cache = newCache(cfg)
 		etcdProxyServ.cacheExpire = cfg.CacheExpire()
-		etcdProxyServ.clientConfig = *cfg.EtcdClientConfig()
+		etcdProxyServ.enforcer = rbac.GetEnforcer(ctx, cfg)
+		etcdProxyServ.envelope = envelope.New(cfg.EncryptionConfig())
 		etcdProxyServ.etcdKeyValueRepo = repo.GetKeyValueEtcdRepo(cfg)
-		etcdProxyServ.hitCounter = atomic.Uint64{}
+		etcdProxyServ.negative = newNegativeCache(cfg)
+		etcdProxyServ.negativeExpire = cfg.CacheConfig().NegativeExpire
+		etcdProxyServ.negativeWatch = cfg.CacheConfig().NegativeWatch
+		etcdProxyServ.pool = etcd_pool.GetPool(cfg)
 		etcdProxyServ.postgresKeyValue = repo.GetKeyValuePostgresRepo(cfg)
 		etcdProxyServ.sLog = cfg.Logger()
+		// Долгоживущий клиент для подписок и аренд: потоки Watch и
+		// KeepAlive мультиплексируются в одном gRPC соединении.
+		if client, err := clientV3.New(*cfg.EtcdClientConfig()); err != nil {
+			etcdProxyServ.sLog.ErrorContext(ctx, env.MSG+"GetEtcdProxyService", "msg", "new client", "err", err)
+		} else {
+			etcdProxyServ.client = client
+		}
+		if err := metrics.RegisterCache("etcd_proxy", etcdProxyServ.cache.Stats); err != nil {
+			etcdProxyServ.sLog.ErrorContext(ctx, env.MSG+"GetEtcdProxyService", "msg", "metrics", "err", err)
+		}
+		if etcdProxyServ.negative != nil {
+			if err := metrics.RegisterCache("etcd_proxy_negative", etcdProxyServ.negative.Stats); err != nil {
+				etcdProxyServ.sLog.ErrorContext(ctx, env.MSG+"GetEtcdProxyService", "msg", "metrics", "err", err)
+			}
+		}
 		go func() {
-			etcdProxyServ.watch(ctx, cfg)
+			etcdProxyServ.watch(ctx)
 		}()
 	})
 	return etcdProxyServ
 }
 
-func (f *etcdProxyService) ApiDelete(ctx context.Context, key string) error {
-	return f.delete(ctx, key)
+// ApiAuthorizeWatch проверка прав подписки до начала потока событий,
+// чтобы отказ можно было вернуть кодом ответа.
+func (f *etcdProxyService) ApiAuthorizeWatch(ctx context.Context, prefix string) error {
+	return f.enforcer.AuthorizePrefix(ctx, rbac.ActionWatch, prefix)
+}
+
+func (f *etcdProxyService) ApiDelete(ctx context.Context, key string, expectedVersion *int64) error {
+	return f.delete(ctx, key, expectedVersion)
 }
 
 func (f *etcdProxyService) ApiGet(ctx context.Context, key string) (dto.Result, error) {
 	return f.get(ctx, key)
 }
 
-func (f *etcdProxyService) ApiPut(ctx context.Context, data dto.KeyValue) error {
-	return f.put(ctx, data)
+func (f *etcdProxyService) ApiLeaseGrant(ctx context.Context, ttlSeconds int64) (dto.Lease, error) {
+	return f.leaseGrant(ctx, ttlSeconds)
+}
+
+func (f *etcdProxyService) ApiLeaseKeepAlive(ctx context.Context, id int64) (dto.Lease, error) {
+	return f.leaseKeepAlive(ctx, id)
+}
+
+func (f *etcdProxyService) ApiLeaseRevoke(ctx context.Context, id int64) error {
+	return f.leaseRevoke(ctx, id)
+}
+
+func (f *etcdProxyService) ApiList(ctx context.Context, prefix, from string, limit int64, keysOnly bool) (dto.List[dto.KeyValue], error) {
+	return f.list(ctx, prefix, from, limit, keysOnly)
+}
+
+func (f *etcdProxyService) ApiPut(ctx context.Context, data dto.KeyValue, expectedVersion *int64) (dto.Result, error) {
+	return f.put(ctx, data, expectedVersion)
+}
+
+func (f *etcdProxyService) ApiTxn(ctx context.Context, request dto.TxnRequest) (dto.TxnResult, error) {
+	return f.txn(ctx, request)
+}
+
+func (f *etcdProxyService) ApiWatch(ctx context.Context, request dto.WatchRequest, send func(dto.WatchEvent) error) error {
+	return f.watchKeys(ctx, request, send)
 }
 
 func (f *etcdProxyService) Delete(ctx context.Context, request *pb.EtcdClientRequest) (*pb.EtcdClientResponse, error) {
@@ -100,9 +171,14 @@ func (f *etcdProxyService) Delete(ctx context.Context, request *pb.EtcdClientReq
 
 		key := u.Key.GetKey()
 
-		if err = f.delete(ctx, key); err != nil {
+		if err = f.delete(ctx, key, request.ExpectedVersion); err != nil {
+			if denied := permissionDenied(err); denied != nil {
+				return nil, denied
+			}
+			response.KeyValue = makePbConflictKeyValue(err)
 			response.Error = err.Error()
 			response.Status = pb.Status_FAIL
+			err = nil
 		} else {
 			response.Status = pb.Status_OK
 		}
@@ -129,10 +205,13 @@ func (f *etcdProxyService) Get(ctx context.Context, request *pb.EtcdClientReques
 		key := u.Key.GetKey()
 
 		if got, err := f.get(ctx, key); err != nil {
+			if denied := permissionDenied(err); denied != nil {
+				return nil, denied
+			}
 			response.Error = err.Error()
 			response.Status = pb.Status_FAIL
 		} else {
-			response.KeyValue = &pb.KeyValue{Key: key, Value: got.Value}
+			response.KeyValue = makePbKeyValue(key, got)
 			response.Status = pb.Status_OK
 		}
 	case *pb.EtcdClientRequest_KeyValue:
@@ -145,6 +224,39 @@ func (f *etcdProxyService) Get(ctx context.Context, request *pb.EtcdClientReques
 	return &response, err
 }
 
+func (f *etcdProxyService) List(ctx context.Context, request *pb.ListRequest) (*pb.ListResponse, error) {
+
+	f.sLog.InfoContext(ctx, env.MSG+"EtcdProxyService.List", "msg", "gRPC", "request", request)
+
+	var response = pb.ListResponse{Status: pb.Status_UNKNOWN}
+
+	// Чтение на момент ревизии или времени выполняет только сервис
+	// данных: у etcd-proxy нет истории PostgreSQL.
+	if request.GetRevision() != 0 || request.GetAt() != nil {
+		return nil, status.Error(codes.InvalidArgument, ErrAsOfUnsupported.Error())
+	}
+	got, err := f.list(ctx, request.GetPrefix(), request.GetFrom(), request.GetLimit(), request.GetKeysOnly())
+
+	if err != nil {
+		if denied := permissionDenied(err); denied != nil {
+			return nil, denied
+		}
+		response.Error = err.Error()
+		response.Status = pb.Status_FAIL
+		return &response, nil
+	}
+	response.KeyValues = make([]*pb.KeyValue, 0, len(got.Items))
+
+	for _, item := range got.Items {
+		response.KeyValues = append(response.KeyValues, &pb.KeyValue{Key: item.Key, Value: item.Value, Version: item.Version})
+	}
+	response.Next = got.Next
+	response.More = got.More
+	response.Status = pb.Status_OK
+
+	return &response, nil
+}
+
 func (f *etcdProxyService) Put(ctx context.Context, request *pb.EtcdClientRequest) (*pb.EtcdClientResponse, error) {
 
 	f.sLog.InfoContext(ctx, env.MSG+"EtcdProxyService.Put", "msg", "gRPC", "request", request)
@@ -155,13 +267,21 @@ func (f *etcdProxyService) Put(ctx context.Context, request *pb.EtcdClientReques
 	switch u := request.Union.(type) {
 	case *pb.EtcdClientRequest_KeyValue:
 
-		key := u.KeyValue.GetKey()
-		value := u.KeyValue.GetValue()
-
-		if err := f.put(ctx, dto.KeyValue{Key: key, Value: value}); err != nil {
+		data := dto.KeyValue{
+			Key:        u.KeyValue.GetKey(),
+			Value:      u.KeyValue.GetValue(),
+			TTLSeconds: u.KeyValue.GetTtlSeconds(),
+			Lease:      u.KeyValue.GetLease(),
+		}
+		if got, err := f.put(ctx, data, request.ExpectedVersion); err != nil {
+			if denied := permissionDenied(err); denied != nil {
+				return nil, denied
+			}
+			response.KeyValue = makePbConflictKeyValue(err)
 			response.Error = err.Error()
 			response.Status = pb.Status_FAIL
 		} else {
+			response.KeyValue = makePbKeyValue(data.Key, got)
 			response.Status = pb.Status_OK
 		}
 	case *pb.EtcdClientRequest_Key:
@@ -174,62 +294,151 @@ func (f *etcdProxyService) Put(ctx context.Context, request *pb.EtcdClientReques
 	return &response, err
 }
 
-func (f *etcdProxyService) delete(ctx context.Context, key string) error {
+func (f *etcdProxyService) Txn(ctx context.Context, request *pb.TxnRequest) (*pb.TxnResponse, error) {
+
+	f.sLog.InfoContext(ctx, env.MSG+"EtcdProxyService.Txn", "msg", "gRPC", "request", request)
+
+	var response = pb.TxnResponse{Status: pb.Status_UNKNOWN}
+
+	if got, err := f.txn(ctx, makeTxnRequest(request)); err != nil {
+		if denied := permissionDenied(err); denied != nil {
+			return nil, denied
+		}
+		response.Error = err.Error()
+		response.Status = pb.Status_FAIL
+	} else {
+		response.Succeeded = got.Succeeded
+		response.Status = pb.Status_OK
+	}
+	return &response, nil
+}
+
+func (f *etcdProxyService) Watch(request *pb.WatchRequest, stream pb.EtcdClientService_WatchServer) error {
+
+	ctx := stream.Context()
+	f.sLog.InfoContext(ctx, env.MSG+"EtcdProxyService.Watch", "msg", "gRPC", "request", request)
+
+	err := f.watchKeys(ctx, makeWatchRequest(request), func(event dto.WatchEvent) error {
+		return stream.Send(makePbWatchEvent(event))
+	})
+	if denied := permissionDenied(err); denied != nil {
+		return denied
+	}
+	return err
+}
+
+func (f *etcdProxyService) delete(ctx context.Context, key string, expectedVersion *int64) error {
 
-	cli, err := clientV3.New(f.clientConfig)
+	if err := f.enforcer.Authorize(ctx, rbac.ActionDelete, key); err != nil {
+		return err
+	}
+	client, err := f.pool.AcquireClient(ctx)
 
 	if err != nil {
 		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.delete", "err", err)
+		return err
 	}
-	defer func() { _ = cli.Close() }()
-
-	if resp, err := cli.Delete(ctx, key); err != nil {
+	defer func() { _ = f.pool.ReleaseClient(client) }()
+
+	if expectedVersion != nil {
+		_, err = f.etcdKeyValueRepo.Do(
+			ctx,
+			entity.MakeKeyValueCompareAndDelete(*expectedVersion),
+			entity.MakeKeyValue(key, "", 0, entity.DefaultTAttributes()),
+			func(domain.Scanner) entity.KeyValue {
+				return entity.KeyValue{}
+			})
+		if err != nil {
+			f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.delete", "msg", "compare and delete", "err", err)
+			return etcdVersionConflict(key, err)
+		}
+	} else if resp, err := client.Delete(ctx, key); err != nil {
 		f.sLog.ErrorContext(ctx,
 			env.MSG+"EtcdProxyService.delete",
-			"msg", "cli.ApiDelete", "err", err, "resp", resp,
+			"msg", "cli.Delete", "err", err, "resp", resp,
 		)
 		return err
 	} else {
 		f.sLog.DebugContext(ctx,
 			env.MSG+"EtcdProxyService.delete",
-			"msg", fmt.Sprintf("Delete is done. Metadata is %q\n", resp),
+			"msg", fmt.Sprintf("Delete is done. Metadata is %v\n", resp),
 		)
-		f.keyInvalidate(ctx, cli, key)
 	}
+	f.keyInvalidate(ctx, client, key)
+
 	return nil
 }
 
 func (f *etcdProxyService) get(ctx context.Context, key string) (dto.Result, error) {
 
+	if err := f.enforcer.Authorize(ctx, rbac.ActionRead, key); err != nil {
+		return dto.Result{}, err
+	}
 	data, err := f.cache.Get(key)
 
 	if err == nil && data != nil {
 
-		counter := f.hitCounter.Add(1)
-		f.sLog.DebugContext(ctx, env.MSG+"EtcdProxyService.get", "msg", fmt.Sprintf("cache hit count: %d", counter))
+		f.sLog.DebugContext(ctx,
+			env.MSG+"EtcdProxyService.get",
+			"msg", fmt.Sprintf("cache hit count: %d", f.cache.Stats().Hits),
+		)
 
-		return dto.Result{Value: string(data)}, nil
+		var result dto.Result
+		if er0 := json.Unmarshal(data, &result); er0 == nil {
+			return result, nil
+		} else {
+			f.sLog.DebugContext(ctx, env.MSG+"EtcdProxyService.get", "msg", "json.Unmarshal", "err", er0)
+		}
 	} else {
 		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.get", "msg", "cache.Get", "err", err)
 	}
-	if result, err := f.cliGet(ctx, key); err != nil {
-		return dto.Result{}, err
-	} else {
-		f.cacheSet(ctx, dto.KeyValue{Key: key, Value: result.Value})
-		return result, nil
+	if cachedNotFound(f.negative, key) {
+		return dto.Result{}, ErrNotFound
+	}
+	return coalesce(ctx, &f.flight, "etcd_proxy", coalesceGet, key, func(ctx context.Context) (dto.Result, error) {
+		if result, err := f.cliGet(ctx, key); errors.Is(err, ErrNotFound) {
+			if er0 := cacheNotFoundRecheck(f.negative, key, f.negativeExpire, func() (bool, error) {
+				return f.cliExists(ctx, key)
+			}); er0 != nil {
+				f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.get", "msg", "cacheNotFound", "err", er0)
+			}
+			return dto.Result{}, err
+		} else if err != nil {
+			return dto.Result{}, err
+		} else {
+			f.cacheSet(ctx, key, result)
+			return result, nil
+		}
+	})
+}
+
+// cliExists ключ есть в etcd.
+func (f *etcdProxyService) cliExists(ctx context.Context, key string) (bool, error) {
+
+	client, err := f.pool.AcquireClient(ctx)
+
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = f.pool.ReleaseClient(client) }()
+	got, err := client.Get(ctx, key, clientV3.WithCountOnly())
+
+	if err != nil {
+		return false, err
 	}
+	return got.Count > 0, nil
 }
 
 func (f *etcdProxyService) cliGet(ctx context.Context, key string) (result dto.Result, err error) {
 
-	cli, err := clientV3.New(f.clientConfig)
+	client, err := f.pool.AcquireClient(ctx)
 
 	if err != nil {
 		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.cliGet", "err", err)
 		return dto.Result{}, err
 	}
-	defer func() { _ = cli.Close() }()
-	got, err := cli.Get(ctx, key)
+	defer func() { _ = f.pool.ReleaseClient(client) }()
+	got, err := client.Get(ctx, key)
 
 	if err != nil {
 		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.cliGet", "err", err)
@@ -242,7 +451,22 @@ func (f *etcdProxyService) cliGet(ctx context.Context, key string) (result dto.R
 		if len(got.Kvs) < 1 {
 			return dto.Result{}, ErrNotFound
 		}
-		result = dto.Result{Value: string(got.Kvs[0].Value)}
+		value, err := f.envelope.Decrypt(key, string(got.Kvs[0].Value))
+
+		if err != nil {
+			f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.cliGet", "msg", "decrypt", "err", err)
+			return dto.Result{}, err
+		}
+		result = dto.Result{Value: value, Version: got.Kvs[0].Version}
+
+		if lease := clientV3.LeaseID(got.Kvs[0].Lease); lease != clientV3.NoLease {
+			if ttl, err := f.timeToLive(ctx, lease); err != nil {
+				f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.cliGet", "msg", "cli.TimeToLive", "err", err)
+			} else {
+				result.Lease = int64(lease)
+				result.TTLSeconds = ttl.TTL
+			}
+		}
 		f.sLog.DebugContext(ctx,
 			env.MSG+"EtcdProxyService.cliGet",
 			"msg", fmt.Sprintf("the value: %s", string(got.Kvs[0].Value)),
@@ -251,43 +475,186 @@ func (f *etcdProxyService) cliGet(ctx context.Context, key string) (result dto.R
 	return result, nil
 }
 
-func (f *etcdProxyService) put(ctx context.Context, data dto.KeyValue) error {
+func (f *etcdProxyService) list(ctx context.Context, prefix, from string, limit int64, keysOnly bool) (dto.List[dto.KeyValue], error) {
 
-	cli, err := clientV3.New(f.clientConfig)
+	allow, err := authorizeList(ctx, f.enforcer, prefix)
+
+	if err != nil {
+		return dto.List[dto.KeyValue]{}, err
+	}
+	limit = listLimit(limit)
+	got, err := coalesce(ctx, &f.flight, "etcd_proxy", coalesceList, coalesceListKey(prefix, from, limit, keysOnly),
+		func(ctx context.Context) ([]entity.KeyValue, error) {
+			return entity.ListKeyValue(ctx, f.etcdKeyValueRepo, prefix, from, listFetchLimit(limit), keysOnly)
+		},
+	)
+
+	if err != nil {
+		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.list", "msg", "etcd list failed", "err", err)
+		return dto.List[dto.KeyValue]{}, err
+	}
+	return makeListPage(got, limit, allow, func(unit entity.KeyValue) dto.KeyValue {
+		return dto.KeyValue{Key: unit.Key(), Value: unit.Value(), Version: unit.Version()}
+	}), nil
+}
+
+func (f *etcdProxyService) put(ctx context.Context, data dto.KeyValue, expectedVersion *int64) (dto.Result, error) {
+
+	if err := f.enforcer.Authorize(ctx, rbac.ActionWrite, data.Key); err != nil {
+		return dto.Result{}, err
+	}
+	client, err := f.pool.AcquireClient(ctx)
 
 	if err != nil {
 		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.put", "err", err)
-		return err
+		return dto.Result{}, err
 	}
-	defer func() { _ = cli.Close() }()
+	defer func() { _ = f.pool.ReleaseClient(client) }()
+	lease, ttl, granted, err := f.putLease(ctx, data)
 
-	if resp, err := cli.Put(ctx, data.Key, data.Value); err != nil {
-		f.sLog.ErrorContext(ctx,
-			env.MSG+"EtcdProxyService.put",
-			"msg", "cli.Put", "err", err, "resp", resp,
-		)
-		return err
+	if err != nil {
+		return dto.Result{}, err
+	}
+	var version int64
+
+	if expectedVersion != nil {
+		version, err = f.compareAndSwap(ctx, data, *expectedVersion, lease)
 	} else {
-		f.sLog.DebugContext(ctx,
-			env.MSG+"EtcdProxyService.put",
-			"msg", fmt.Sprintf("cli.Put is done. Metadata is %q\n", resp),
-		)
-		f.keyInvalidate(ctx, cli, data.Key)
-		f.cacheSet(ctx, data)
+		version, err = f.putValue(ctx, client, data, lease)
 	}
-	return nil
+	if err != nil {
+		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.put", "err", err)
+
+		if granted {
+			// Выданная под эту запись аренда больше не нужна.
+			_ = f.leaseRevoke(ctx, int64(lease))
+		}
+		return dto.Result{}, err
+	}
+	result := dto.Result{Value: data.Value, Version: version, TTLSeconds: ttl, Lease: int64(lease)}
+	f.keyInvalidate(ctx, client, data.Key)
+	f.cacheSet(ctx, data.Key, result)
+
+	return result, nil
+}
+
+// compareAndSwap условная запись через репозиторий etcd, который сам
+// шифрует значение, возвращает версию записанного ключа.
+func (f *etcdProxyService) compareAndSwap(
+	ctx context.Context,
+	data dto.KeyValue,
+	expected int64,
+	lease clientV3.LeaseID,
+) (version int64, err error) {
+
+	_, err = f.etcdKeyValueRepo.Do(
+		ctx,
+		entity.MakeKeyValueCompareAndSwap(expected).WithLease(int64(lease)),
+		entity.MakeKeyValue(data.Key, data.Value, 0, entity.DefaultTAttributes()),
+		func(s domain.Scanner) entity.KeyValue {
+			var key, value string
+			var v sql.NullInt64
+
+			if s.Scan(&key, &value, &v) == nil {
+				version = v.Int64
+			}
+			return entity.KeyValue{}
+		})
+	if err != nil {
+		return 0, etcdVersionConflict(data.Key, err)
+	}
+	return version, nil
 }
 
-func (f *etcdProxyService) cacheSet(ctx context.Context, data dto.KeyValue) {
+// putValue безусловная запись, версия ключа после записи вычисляется
+// по предыдущей версии: etcd начинает версию заново с 1 после удаления.
+func (f *etcdProxyService) putValue(
+	ctx context.Context,
+	client clientV3.KV,
+	data dto.KeyValue,
+	lease clientV3.LeaseID,
+) (int64, error) {
+
+	value, err := f.envelope.Encrypt(data.Key, data.Value)
+
+	if err != nil {
+		return 0, err
+	}
+	opts := []clientV3.OpOption{clientV3.WithPrevKV()}
+
+	if lease != clientV3.NoLease {
+		opts = append(opts, clientV3.WithLease(lease))
+	}
+	resp, err := client.Put(ctx, data.Key, value, opts...)
+
+	if err != nil {
+		return 0, err
+	}
+	f.sLog.DebugContext(ctx,
+		env.MSG+"EtcdProxyService.put",
+		"msg", fmt.Sprintf("cli.Put is done. Metadata is %v\n", resp),
+	)
+	if resp.PrevKv == nil {
+		return 1, nil
+	}
+	return resp.PrevKv.Version + 1, nil
+}
+
+func (f *etcdProxyService) txn(ctx context.Context, request dto.TxnRequest) (dto.TxnResult, error) {
+
+	if err := authorizeTxn(ctx, f.enforcer, request); err != nil {
+		return dto.TxnResult{}, err
+	}
+	txn, err := makeTxn(request)
 
-	if err := f.cache.Set(data.Key, []byte(data.Value), f.cacheExpire); err != nil {
+	if err != nil {
+		return dto.TxnResult{}, err
+	}
+	succeeded, err := entity.TransactionKeyValue(ctx, f.etcdKeyValueRepo, txn)
+
+	if err != nil {
+		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.txn", "msg", "etcd transaction failed", "err", err)
+		return dto.TxnResult{}, err
+	}
+	if keys := txnKeys(txn, succeeded); len(keys) > 0 {
+		if client, err := f.pool.AcquireClient(ctx); err != nil {
+			f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.txn", "msg", "acquire client", "err", err)
+		} else {
+			for _, key := range keys {
+				f.keyInvalidate(ctx, client, key)
+			}
+			_ = f.pool.ReleaseClient(client)
+		}
+	}
+	return dto.TxnResult{Succeeded: succeeded}, nil
+}
+
+func (f *etcdProxyService) cacheSet(ctx context.Context, key string, result dto.Result) {
+
+	expire := f.cacheExpire
+
+	if result.Lease != int64(clientV3.NoLease) {
+		if expire = leaseCacheExpire(f.cacheExpire, result.TTLSeconds); expire <= 0 {
+			return
+		}
+	}
+	data, err := json.Marshal(result)
+
+	if err == nil {
+		err = f.cache.Set(key, data, expire)
+	}
+	if err != nil {
 		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.cacheSet", "err", err)
 	}
 }
 
-func (f *etcdProxyService) keyInvalidate(ctx context.Context, cli *clientV3.Client, key string) {
+func (f *etcdProxyService) keyInvalidate(ctx context.Context, client clientV3.KV, key string) {
 
-	if resp, err := cli.Put(ctx, CacheInvalidate, key); err != nil {
+	if err := forgetNotFound(f.negative, key); err != nil {
+		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.keyInvalidate", "msg", "forgetNotFound", "err", err)
+	}
+
+	if resp, err := client.Put(ctx, CacheInvalidate, key); err != nil {
 		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.keyInvalidate", "err", err)
 	} else {
 		f.sLog.DebugContext(ctx,
@@ -297,33 +664,82 @@ func (f *etcdProxyService) keyInvalidate(ctx context.Context, cli *clientV3.Clie
 	}
 }
 
-func (f *etcdProxyService) watch(ctx context.Context, cfg env.Config) {
+// watchKeys подписка на изменения ключей по префиксу: каждое событие
+// передаётся в send, ошибка send или отмена ctx завершают подписку.
+// События ключей, подписка на которые клиенту не разрешена, пропускаются.
+func (f *etcdProxyService) watchKeys(ctx context.Context, request dto.WatchRequest, send func(dto.WatchEvent) error) error {
 
-	cli, err := clientV3.New(*cfg.EtcdClientConfig())
+	if err := f.enforcer.AuthorizePrefix(ctx, rbac.ActionWatch, request.Prefix); err != nil {
+		return err
+	}
+	allow := f.enforcer.Filter(ctx, rbac.ActionWatch)
+	opts, err := watchOptions(request)
 
 	if err != nil {
-		f.sLog.ErrorContext(ctx, "err", err)
+		return err
 	}
-	defer func() { _ = cli.Close() }()
-	rch := cli.Watch(ctx, CacheInvalidate)
+	if f.client == nil {
+		return ErrNoClient
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rch := f.client.Watch(clientV3.WithRequireLeader(ctx), request.Prefix, opts...)
 
 	for watchResp := range rch {
+		if err := watchResp.Err(); err != nil {
+			f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.watchKeys", "msg", "watch response", "err", err)
+			return err
+		}
 		for _, ev := range watchResp.Events {
-			key := string(ev.Kv.Value)
-			if err := f.cache.Delete(key); err != nil {
-				f.sLog.ErrorContext(ctx,
-					env.MSG+"EtcdProxyService.watch",
-					"msg", "cache.Delete",
-					"err", err)
-			} else {
-				f.sLog.DebugContext(ctx,
-					env.MSG+"EtcdProxyService.watch",
-					"msg", fmt.Sprintf("Cache invalidate by key: \"%s\" is OK.", key),
-				)
-				f.hitCounter.Store(0)
+			if string(ev.Kv.Key) == CacheInvalidate || !allow(string(ev.Kv.Key)) {
+				continue
+			}
+			event := makeWatchEvent(ev)
+
+			if event.Value, err = f.envelope.Decrypt(event.Key, event.Value); err != nil {
+				f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.watchKeys", "msg", "decrypt", "err", err)
+				return err
+			}
+			if err := send(event); err != nil {
+				return err
 			}
 		}
 	}
+	return ctx.Err()
+}
+
+func (f *etcdProxyService) watch(ctx context.Context) {
+
+	if f.client == nil {
+		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.watch", "err", ErrNoClient)
+		return
+	}
+	if f.negative != nil && f.negativeWatch {
+		go watchNegativeForget(ctx, f.sLog, f.client, "etcd_proxy", f.negative)
+	}
+	watchCacheInvalidate(ctx, f.sLog, f.client, "etcd_proxy", invalidatedCaches(f.cache, f.negative)...)
+}
+
+func makePbKeyValue(key string, result dto.Result) *pb.KeyValue {
+
+	kv := &pb.KeyValue{Key: key, Value: result.Value, Version: result.Version}
+
+	if result.Lease != int64(clientV3.NoLease) {
+		kv.Lease = &result.Lease
+		kv.TtlSeconds = &result.TTLSeconds
+	}
+	return kv
+}
+
+// makePbConflictKeyValue текущая версия ключа для ответа при конфликте версий.
+func makePbConflictKeyValue(err error) *pb.KeyValue {
+
+	var conflict VersionConflictError
+
+	if errors.As(err, &conflict) {
+		return &pb.KeyValue{Key: conflict.Key, Version: conflict.Current}
+	}
+	return nil
 }
 
 //!-
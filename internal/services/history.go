@@ -0,0 +1,93 @@
+/*
+ * This file was last modified at 2026-10-18 19:50 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * history.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/rbac"
+
+	pb "github.com/victor-skurikhin/etcd-client/v1/proto"
+)
+
+var ErrBadRollbackVersion = fmt.Errorf("rollback version must be positive")
+
+func (k *keyValueDataService) History(ctx context.Context, request *pb.HistoryRequest) (*pb.HistoryResponse, error) {
+
+	k.sLog.InfoContext(ctx, env.MSG+"keyValueDataService.History", "msg", "gRPC", "request", request)
+
+	var response = pb.HistoryResponse{Status: pb.Status_UNKNOWN}
+
+	got, err := k.history(ctx, request.GetKey(), request.GetLimit())
+
+	if err != nil {
+		if denied := permissionDenied(err); denied != nil {
+			return nil, denied
+		}
+		response.Error = err.Error()
+		response.Status = pb.Status_FAIL
+		return &response, nil
+	}
+	response.Revisions = make([]*pb.KeyValueData, 0, len(got))
+
+	for _, item := range got {
+		response.Revisions = append(response.Revisions, makePbKeyValueData(item))
+	}
+	response.Status = pb.Status_OK
+
+	return &response, nil
+}
+
+// history ревизии записи из key_value_history начиная с последней,
+// UpdatedAt ревизии — время изменения записи.
+func (k *keyValueDataService) history(ctx context.Context, key string, limit int64) ([]entity.KeyValue, error) {
+
+	if err := k.enforcer.Authorize(ctx, rbac.ActionRead, key); err != nil {
+		return nil, err
+	}
+	got, err := entity.HistoryKeyValue(ctx, k.postgresRepo, key, listLimit(limit))
+
+	if err != nil {
+		k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.history", "msg", "postgres history failed", "err", err)
+		return nil, err
+	}
+	if len(got) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return got, nil
+}
+
+// rollback запись значения ревизии version как новой версии ключа
+// в etcd и PostgreSQL, история ключа при этом сохраняется.
+func (k *keyValueDataService) rollback(ctx context.Context, key string, version int64) (entity.KeyValue, error) {
+
+	if version < 1 {
+		return entity.KeyValue{}, ErrBadRollbackVersion
+	}
+	if err := k.enforcer.Authorize(ctx, rbac.ActionWrite, key); err != nil {
+		return entity.KeyValue{}, err
+	}
+	revision, found, err := entity.RevisionKeyValue(ctx, k.postgresRepo, key, version)
+
+	if err != nil {
+		return entity.KeyValue{}, err
+	}
+	if !found {
+		return entity.KeyValue{}, fmt.Errorf("%w: %s version %d", ErrNotFound, key, version)
+	}
+	k.sLog.InfoContext(ctx, env.MSG+"keyValueDataService.rollback", "key", key, "version", version)
+
+	return k.put(ctx, MakeKeyValueNow(key, revision.Value()), nil)
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
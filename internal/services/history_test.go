@@ -0,0 +1,133 @@
+/*
+ * This file was last modified at 2026-10-18 19:50 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * history_test.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for method history(context.Context, string, int64)",
+			positiveHistory,
+			func(t *testing.T, i interface{}) bool { return assert.Len(t, i, 2) },
+		},
+		{
+			"test #1 negative for method history(context.Context, string, int64) unknown key",
+			negativeHistoryNotFound,
+			func(t *testing.T, i interface{}) bool { return assert.ErrorIs(t, i.(error), ErrNotFound) },
+		},
+		{
+			"test #2 positive for method rollback(context.Context, string, int64)",
+			positiveRollback,
+			func(t *testing.T, i interface{}) bool {
+				unit := i.(entity.KeyValue)
+				return assert.Equal(t, "value1", unit.Value())
+			},
+		},
+		{
+			"test #3 negative for method rollback(context.Context, string, 0)",
+			func(t *testing.T) (interface{}, error) {
+				srv, _ := newTestKeyValueDataServiceWithOutbox(t)
+				_, err := srv.rollback(context.Background(), "key1", 0)
+				return err, nil
+			},
+			func(t *testing.T, i interface{}) bool { return assert.ErrorIs(t, i.(error), ErrBadRollbackVersion) },
+		},
+		{
+			"test #4 negative for method rollback(context.Context, string, int64) unknown version",
+			negativeRollbackNotFound,
+			func(t *testing.T, i interface{}) bool { return assert.ErrorIs(t, i.(error), ErrNotFound) },
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveHistory(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	mocks.postgres.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueHistory(DefaultListLimit), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{
+			entity.MakeKeyValue("key1", "value2", 2, entity.DefaultTAttributes()),
+			entity.MakeKeyValue("key1", "value1", 1, entity.DefaultTAttributes()),
+		}, nil).
+		Times(1)
+
+	return srv.history(context.Background(), "key1", 0)
+}
+
+func negativeHistoryNotFound(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	mocks.postgres.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueHistory(10), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{}, nil).
+		Times(1)
+	_, err := srv.history(context.Background(), "key1", 10)
+
+	return err, nil
+}
+
+func positiveRollback(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	srv.outboxConfig.Enabled = false
+	mocks.postgres.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueRevision(1), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{entity.MakeKeyValue("key1", "value1", 1, entity.DefaultTAttributes())}, nil).
+		Times(1)
+	mocks.postgres.
+		EXPECT().
+		Do(gomock.Any(), entity.KeyValueUpsert, gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, nil).
+		Times(1)
+	mocks.etcd.
+		EXPECT().
+		Do(gomock.Any(), entity.KeyValueUpsert, gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, nil).
+		Times(1)
+
+	return srv.rollback(context.Background(), "key1", 1)
+}
+
+func negativeRollbackNotFound(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	mocks.postgres.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueRevision(7), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{}, nil).
+		Times(1)
+	_, err := srv.rollback(context.Background(), "key1", 7)
+
+	return err, nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -10,38 +10,71 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/memory"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/repo"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/metrics"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/rbac"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/tracing"
 	"github.com/victor-skurikhin/etcd-client/v1/pool"
 	"github.com/victor-skurikhin/etcd-client/v1/pool/etcd_pool"
+	"github.com/victor-skurikhin/etcd-client/v1/tool"
 	clientV3 "go.etcd.io/etcd/client/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"log/slog"
 	"sync"
-	"sync/atomic"
 	"time"
+
+	pb "github.com/victor-skurikhin/etcd-client/v1/proto"
 )
 
 const CacheInvalidate = "Y2FjaGUtaW52YWxpZGF0ZQo="
 
 type KeyValueDataService interface {
-	Delete(context.Context, string) error
-	Get(context.Context, string) (entity.KeyValue, error)
-	Put(context.Context, entity.KeyValue) error
+	pb.KeyValueDataServiceServer
+	ApiDelete(ctx context.Context, key string, expectedVersion *int64) error
+	ApiDeleted(ctx context.Context, prefix, from string, limit int64) (dto.List[entity.KeyValue], error)
+	ApiGet(context.Context, string) (entity.KeyValue, error)
+	ApiGetAsOf(ctx context.Context, key string, asOf dto.AsOf) (entity.KeyValue, string, error)
+	ApiHistory(ctx context.Context, key string, limit int64) ([]entity.KeyValue, error)
+	ApiList(ctx context.Context, prefix, from string, limit int64, keysOnly bool) (dto.List[entity.KeyValue], error)
+	ApiListAsOf(ctx context.Context, prefix, from string, limit int64, keysOnly bool, asOf dto.AsOf) (dto.List[entity.KeyValue], string, error)
+	ApiPut(ctx context.Context, unit entity.KeyValue, expectedVersion *int64) (entity.KeyValue, error)
+	ApiReconcile(ctx context.Context, dryRun bool) (dto.ReconcileReport, error)
+	ApiRollback(ctx context.Context, key string, version int64) (entity.KeyValue, error)
+	ApiTxn(ctx context.Context, request dto.TxnRequest) (dto.TxnResult, error)
+	ApiUndelete(ctx context.Context, key string) (entity.KeyValue, error)
 }
 
 type keyValueDataService struct {
-	cache        *memory.Storage
-	cacheExpire  time.Duration
-	etcdRepo     domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
-	hitCounter   atomic.Uint64
-	pool         pool.EtcdPool
-	postgresRepo domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
-	sLog         *slog.Logger
+	pb.UnimplementedKeyValueDataServiceServer
+	cache           memory.Cache
+	cacheExpire     time.Duration
+	enforcer        *rbac.Enforcer
+	etcdRepo        domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
+	flight          singleflight.Group
+	negative        memory.Cache
+	negativeExpire  time.Duration
+	negativeWatch   bool
+	outboxConfig    env.OutboxConfig
+	outboxNotify    chan struct{}
+	outboxRepo      domain.Repo[domain.Actioner[*entity.Outbox, entity.Outbox], *entity.Outbox, entity.Outbox]
+	pool            pool.EtcdPool
+	postgresRepo    domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
+	purgeConfig     env.PurgeConfig
+	reconcileConfig env.ReconcileConfig
+	reconcileMu     sync.Mutex
+	sLog            *slog.Logger
 }
 
 var _ KeyValueDataService = (*keyValueDataService)(nil)
@@ -51,44 +84,255 @@ var (
 	keyValueDataServiceInst *keyValueDataService
 )
 
-func (k *keyValueDataService) Delete(ctx context.Context, key string) error {
-	return k.delete(ctx, key)
+func (k *keyValueDataService) ApiDelete(ctx context.Context, key string, expectedVersion *int64) error {
+	return k.delete(ctx, key, expectedVersion)
+}
+
+func (k *keyValueDataService) ApiDeleted(ctx context.Context, prefix, from string, limit int64) (dto.List[entity.KeyValue], error) {
+	return k.deleted(ctx, prefix, from, limit)
 }
 
-func (k *keyValueDataService) Get(ctx context.Context, key string) (entity.KeyValue, error) {
+func (k *keyValueDataService) ApiGet(ctx context.Context, key string) (entity.KeyValue, error) {
 	return k.get(ctx, key)
 }
 
-func (k *keyValueDataService) Put(ctx context.Context, value entity.KeyValue) error {
-	return k.put(ctx, value)
+func (k *keyValueDataService) ApiGetAsOf(ctx context.Context, key string, asOf dto.AsOf) (entity.KeyValue, string, error) {
+	return k.getAsOf(ctx, key, asOf)
 }
 
-func (k *keyValueDataService) delete(ctx context.Context, key string) error {
+func (k *keyValueDataService) ApiHistory(ctx context.Context, key string, limit int64) ([]entity.KeyValue, error) {
+	return k.history(ctx, key, limit)
+}
 
-	_, err := k.postgresRepo.Do(
-		ctx,
-		entity.KeyValueDelete,
-		entity.MakeKeyValue(key, "", 0, entity.DefaultTAttributes()),
-		func(domain.Scanner) entity.KeyValue {
-			return entity.KeyValue{}
-		})
+func (k *keyValueDataService) ApiList(ctx context.Context, prefix, from string, limit int64, keysOnly bool) (dto.List[entity.KeyValue], error) {
+	return k.list(ctx, prefix, from, limit, keysOnly)
+}
+
+func (k *keyValueDataService) ApiListAsOf(
+	ctx context.Context,
+	prefix, from string,
+	limit int64,
+	keysOnly bool,
+	asOf dto.AsOf,
+) (dto.List[entity.KeyValue], string, error) {
+	return k.listAsOf(ctx, prefix, from, limit, keysOnly, asOf)
+}
+
+func (k *keyValueDataService) ApiPut(ctx context.Context, unit entity.KeyValue, expectedVersion *int64) (entity.KeyValue, error) {
+	return k.put(ctx, unit, expectedVersion)
+}
+
+// ApiReconcile сверка доступна клиенту с правами записи и удаления
+// всех ключей с префиксом сверки.
+func (k *keyValueDataService) ApiReconcile(ctx context.Context, dryRun bool) (dto.ReconcileReport, error) {
+
+	for _, action := range []rbac.Action{rbac.ActionWrite, rbac.ActionDelete} {
+		if err := k.enforcer.Authorize(ctx, action, k.reconcileConfig.Prefix); err != nil {
+			return dto.ReconcileReport{}, err
+		}
+	}
+	return k.reconcile(ctx, dryRun)
+}
+
+func (k *keyValueDataService) ApiRollback(ctx context.Context, key string, version int64) (entity.KeyValue, error) {
+	return k.rollback(ctx, key, version)
+}
+
+func (k *keyValueDataService) ApiTxn(ctx context.Context, request dto.TxnRequest) (dto.TxnResult, error) {
+	return k.txn(ctx, request)
+}
+
+func (k *keyValueDataService) ApiUndelete(ctx context.Context, key string) (entity.KeyValue, error) {
+	return k.undelete(ctx, key)
+}
+
+func (k *keyValueDataService) Delete(ctx context.Context, request *pb.KeyValueDataRequest) (*pb.KeyValueDataResponse, error) {
+
+	k.sLog.InfoContext(ctx, env.MSG+"keyValueDataService.Delete", "msg", "gRPC", "request", request)
+
+	var err error
+	var response = pb.KeyValueDataResponse{Status: pb.Status_UNKNOWN}
+
+	switch u := request.Union.(type) {
+	case *pb.KeyValueDataRequest_Key:
+
+		key := u.Key.GetKey()
+
+		if err = k.delete(ctx, key, request.ExpectedVersion); err != nil {
+			if denied := permissionDenied(err); denied != nil {
+				return nil, denied
+			}
+			response.KeyValueData = makePbConflictKeyValueData(err)
+			response.Error = err.Error()
+			response.Status = pb.Status_FAIL
+			err = nil
+		} else {
+			response.Status = pb.Status_OK
+		}
+	default:
+		response.Error = BadOneOfUnionValue
+		response.Status = pb.Status_FAIL
+	}
+	return &response, err
+}
+
+func (k *keyValueDataService) Get(ctx context.Context, request *pb.KeyValueDataRequest) (*pb.KeyValueDataResponse, error) {
+
+	k.sLog.InfoContext(ctx, env.MSG+"keyValueDataService.Get", "msg", "gRPC", "request", request)
+
+	var err error
+	var response = pb.KeyValueDataResponse{Status: pb.Status_UNKNOWN}
+
+	switch u := request.Union.(type) {
+	case *pb.KeyValueDataRequest_Key:
+
+		key := u.Key.GetKey()
+
+		if got, source, err := k.getPoint(ctx, key, makeAsOf(request.GetRevision(), request.GetAt())); err != nil {
+			if denied := permissionDenied(err); denied != nil {
+				return nil, denied
+			}
+			response.Error = err.Error()
+			response.Status = pb.Status_FAIL
+		} else {
+			response.KeyValueData = makePbKeyValueData(got)
+			response.Source = source
+			response.Status = pb.Status_OK
+		}
+	default:
+		response.Error = BadOneOfUnionValue
+		response.Status = pb.Status_FAIL
+	}
+	return &response, err
+}
+
+func (k *keyValueDataService) List(ctx context.Context, request *pb.ListRequest) (*pb.KeyValueDataListResponse, error) {
+
+	k.sLog.InfoContext(ctx, env.MSG+"keyValueDataService.List", "msg", "gRPC", "request", request)
+
+	var err error
+	var got dto.List[entity.KeyValue]
+	var response = pb.KeyValueDataListResponse{Status: pb.Status_UNKNOWN}
+
+	if asOf := makeAsOf(request.GetRevision(), request.GetAt()); asOf.IsZero() {
+		got, err = k.list(ctx, request.GetPrefix(), request.GetFrom(), request.GetLimit(), request.GetKeysOnly())
+	} else {
+		got, response.Source, err = k.listAsOf(
+			ctx, request.GetPrefix(), request.GetFrom(), request.GetLimit(), request.GetKeysOnly(), asOf,
+		)
+	}
 	if err != nil {
+		if denied := permissionDenied(err); denied != nil {
+			return nil, denied
+		}
+		response.Error = err.Error()
+		response.Status = pb.Status_FAIL
+		return &response, nil
+	}
+	response.KeyValueData = make([]*pb.KeyValueData, 0, len(got.Items))
+
+	for _, item := range got.Items {
+		response.KeyValueData = append(response.KeyValueData, makePbKeyValueData(item))
+	}
+	response.Next = got.Next
+	response.More = got.More
+	response.Status = pb.Status_OK
+
+	return &response, nil
+}
+
+func (k *keyValueDataService) Put(ctx context.Context, request *pb.KeyValueDataRequest) (*pb.KeyValueDataResponse, error) {
+
+	k.sLog.InfoContext(ctx, env.MSG+"keyValueDataService.Put", "msg", "gRPC", "request", request)
+
+	var err error
+	var response = pb.KeyValueDataResponse{Status: pb.Status_UNKNOWN}
+
+	switch u := request.Union.(type) {
+	case *pb.KeyValueDataRequest_KeyValue:
+
+		unit := MakeKeyValueNow(u.KeyValue.GetKey(), u.KeyValue.GetValue())
+
+		if got, err := k.put(ctx, unit, request.ExpectedVersion); err != nil {
+			if denied := permissionDenied(err); denied != nil {
+				return nil, denied
+			}
+			response.KeyValueData = makePbConflictKeyValueData(err)
+			response.Error = err.Error()
+			response.Status = pb.Status_FAIL
+		} else {
+			response.KeyValueData = makePbKeyValueData(got)
+			response.Status = pb.Status_OK
+		}
+	default:
+		response.Error = BadOneOfUnionValue
+		response.Status = pb.Status_FAIL
+	}
+	return &response, err
+}
+
+func (k *keyValueDataService) Txn(ctx context.Context, request *pb.TxnRequest) (*pb.TxnResponse, error) {
+
+	k.sLog.InfoContext(ctx, env.MSG+"keyValueDataService.Txn", "msg", "gRPC", "request", request)
+
+	var response = pb.TxnResponse{Status: pb.Status_UNKNOWN}
+
+	if got, err := k.txn(ctx, makeTxnRequest(request)); err != nil {
+		if denied := permissionDenied(err); denied != nil {
+			return nil, denied
+		}
+		response.Error = err.Error()
+		response.Status = pb.Status_FAIL
+	} else {
+		response.Succeeded = got.Succeeded
+		response.Status = pb.Status_OK
+	}
+	return &response, nil
+}
+
+func (k *keyValueDataService) delete(ctx context.Context, key string, expectedVersion *int64) error {
+
+	if err := k.enforcer.Authorize(ctx, rbac.ActionDelete, key); err != nil {
 		return err
 	}
-	_, err = k.etcdRepo.Do(
-		ctx,
-		entity.KeyValueDelete,
-		entity.MakeKeyValue(key, "", 0, entity.DefaultTAttributes()),
-		func(domain.Scanner) entity.KeyValue {
-			return entity.KeyValue{}
-		})
-	if err == nil {
-		k.keyInvalidate(ctx, key)
+	if k.outboxConfig.Enabled {
+		return k.deleteOutbox(ctx, key, expectedVersion)
+	}
+	var err error
+	var revision int64
+	unit := MakeKeyValueNow(key, "")
+
+	if expectedVersion != nil {
+		if err = unit.CompareAndDelete(ctx, k.postgresRepo, *expectedVersion); err != nil {
+			return k.versionConflict(ctx, key, err)
+		}
+		// Удаление в PostgreSQL зафиксировано: конфликт клиенту уже не
+		// возвращается, неудавшееся удаление из etcd доставляет outbox.
+		if revision, err = k.deleteEtcd(ctx, key); err != nil {
+			k.sLog.WarnContext(ctx, env.MSG+"keyValueDataService.delete", "msg", "etcd delete failed", "key", key, "err", err)
+			k.outboxEnqueue(ctx, []string{key})
+			k.keyInvalidate(ctx, key)
+			return nil
+		}
+	} else {
+		if err = unit.Delete(ctx, k.postgresRepo); err != nil {
+			return err
+		}
+		if revision, err = k.deleteEtcd(ctx, key); err != nil {
+			return err
+		}
 	}
-	return err
+	k.markRevision(ctx, unit, revision)
+	k.keyInvalidate(ctx, key)
+
+	return nil
 }
 
-const cntKeyValueDataServiceGetJobs = 2
+const (
+	cntKeyValueDataServiceGetJobs = 2
+	msgEtcd                       = "Etcd"
+	msgPostgres                   = "Postgres"
+)
 
 type msgKeyValue struct {
 	err   error
@@ -96,12 +340,21 @@ type msgKeyValue struct {
 	value entity.KeyValue
 }
 
-func (k *keyValueDataService) get(ctx context.Context, key string) (entity.KeyValue, error) {
+func (k *keyValueDataService) get(ctx context.Context, key string) (value entity.KeyValue, err error) {
 
+	ctx, span := tracing.Start(ctx, "keyValueDataService.get", trace.WithAttributes(tracing.KeyAttribute.String(key)))
+	defer func() { tracing.End(span, err) }()
+
+	if err = k.enforcer.Authorize(ctx, rbac.ActionRead, key); err != nil {
+		return entity.KeyValue{}, err
+	}
+	_, cacheSpan := tracing.Start(ctx, "cache get", trace.WithAttributes(tracing.KeyAttribute.String(key)))
 	data, err := k.cache.Get(key)
+	cacheSpan.SetAttributes(attribute.Bool("cache.hit", err == nil && data != nil))
+	cacheSpan.End()
 
 	if err == nil && data != nil {
-		k.incrementHitCounter(ctx)
+		k.logCacheHit(ctx)
 		var result entity.KeyValue
 		if er0 := result.FromJSON(data); er0 == nil {
 			return result, nil
@@ -111,10 +364,23 @@ func (k *keyValueDataService) get(ctx context.Context, key string) (entity.KeyVa
 	} else {
 		k.sLog.DebugContext(ctx, env.MSG+"keyValueDataService.get", "err", err)
 	}
+	if cachedNotFound(k.negative, key) {
+		return entity.KeyValue{}, ErrNotFound
+	}
+	return coalesce(ctx, &k.flight, "key_value_data", coalesceGet, key, func(ctx context.Context) (entity.KeyValue, error) {
+		return k.fetch(ctx, key)
+	})
+}
+
+// fetch одновременное чтение ключа из etcd и PostgreSQL при промахе кэша.
+// Ключ, не найденный ни в одном хранилище, записывается в кэш отсутствующих
+// ключей, только если хотя бы одно из них подтвердило его отсутствие,
+// а не было недоступно.
+func (k *keyValueDataService) fetch(ctx context.Context, key string) (entity.KeyValue, error) {
+
 	var wg sync.WaitGroup
 
 	wg.Add(cntKeyValueDataServiceGetJobs)
-	quit := make(chan struct{})
 	results := make(chan msgKeyValue, cntKeyValueDataServiceGetJobs)
 
 	go func() {
@@ -128,80 +394,267 @@ func (k *keyValueDataService) get(ctx context.Context, key string) (entity.KeyVa
 	go func() {
 		wg.Wait()
 		close(results)
-		close(quit)
 	}()
-	for {
-		select {
-		case result := <-results:
-			if result.err == nil {
-				return result.value, nil
+	// Postgres хранит полные метаданные записи (created_at, updated_at, deleted),
+	// поэтому его ответ предпочтительнее, etcd используется как запасной вариант.
+	var found *msgKeyValue
+	var missing bool
+
+	for result := range results {
+		if result.err != nil {
+			missing = missing || isNotFound(result.err)
+			k.sLog.DebugContext(ctx,
+				env.MSG+"keyValueDataService.get",
+				"msg", result.name, "err", result.err,
+			)
+			continue
+		}
+		if found == nil || result.name == msgPostgres {
+			r := result
+			found = &r
+		}
+	}
+	if found == nil {
+		if missing {
+			if err := cacheNotFoundRecheck(k.negative, key, k.negativeExpire, func() (bool, error) {
+				return k.existsEtcd(ctx, key)
+			}); err != nil {
+				k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.get", "msg", "cacheNotFound", "err", err)
 			}
-		case <-quit:
-			return entity.KeyValue{}, fmt.Errorf("ERROR") // TODO
 		}
+		return entity.KeyValue{}, ErrNotFound
 	}
+	k.cacheSet(ctx, found.value)
+
+	return found.value, nil
 }
 
 func (k *keyValueDataService) getEtcd(ctx context.Context, key string) msgKeyValue {
 
-	result, err := k.etcdRepo.Do(ctx,
-		entity.KeyValueDelete,
-		makeKetValueWithKeyOnly(key),
-		func(domain.Scanner) entity.KeyValue {
-			return entity.KeyValue{}
-		})
-	return msgKeyValue{err: err, name: "Etcd", value: result}
+	result, err := entity.GetKeyValue(ctx, k.etcdRepo, key)
+
+	return msgKeyValue{err: err, name: msgEtcd, value: result}
+}
+
+// existsEtcd ключ есть в etcd.
+func (k *keyValueDataService) existsEtcd(ctx context.Context, key string) (bool, error) {
+
+	if _, err := entity.GetKeyValue(ctx, k.etcdRepo, key); isNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func (k *keyValueDataService) getPostgres(ctx context.Context, key string) msgKeyValue {
 
-	result, err := k.postgresRepo.Do(ctx,
-		entity.KeyValueDelete,
-		makeKetValueWithKeyOnly(key),
-		func(domain.Scanner) entity.KeyValue {
-			return entity.KeyValue{}
-		})
-	return msgKeyValue{err: err, name: "Postgres", value: result}
+	result, err := entity.GetKeyValue(ctx, k.postgresRepo, key)
+
+	return msgKeyValue{err: err, name: msgPostgres, value: result}
+}
+
+func (k *keyValueDataService) list(ctx context.Context, prefix, from string, limit int64, keysOnly bool) (dto.List[entity.KeyValue], error) {
+
+	allow, err := authorizeList(ctx, k.enforcer, prefix)
+
+	if err != nil {
+		return dto.List[entity.KeyValue]{}, err
+	}
+	limit = listLimit(limit)
+	got, err := coalesce(ctx, &k.flight, "key_value_data", coalesceList, coalesceListKey(prefix, from, limit, keysOnly),
+		func(ctx context.Context) ([]entity.KeyValue, error) {
+			return entity.ListKeyValue(ctx, k.postgresRepo, prefix, from, listFetchLimit(limit), keysOnly)
+		},
+	)
+
+	if err != nil {
+		k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.list", "msg", "postgres list failed", "err", err)
+		return dto.List[entity.KeyValue]{}, err
+	}
+	return makeListPage(got, limit, allow, listItem(keysOnly)), nil
 }
 
-func (k *keyValueDataService) put(ctx context.Context, unit entity.KeyValue) error {
+func (k *keyValueDataService) put(ctx context.Context, unit entity.KeyValue, expectedVersion *int64) (entity.KeyValue, error) {
 
+	if err := k.enforcer.Authorize(ctx, rbac.ActionWrite, unit.Key()); err != nil {
+		return unit, err
+	}
+	if k.outboxConfig.Enabled {
+		return k.putOutbox(ctx, unit, expectedVersion)
+	}
+	result := unit
+
+	if expectedVersion != nil {
+		// Версию записи ведёт PostgreSQL, поэтому в etcd значение
+		// пишется только после успешной условной записи. Запись в
+		// PostgreSQL уже зафиксирована: в etcd она повторяется без
+		// условия, а неудавшаяся запись доставляется через outbox.
+		if err := result.CompareAndSwap(ctx, k.postgresRepo, *expectedVersion); err != nil {
+			return unit, k.versionConflict(ctx, unit.Key(), err)
+		}
+		revision, err := k.putEtcd(ctx, unit)
+
+		if err == nil {
+			k.markRevision(ctx, result, revision)
+		} else {
+			k.sLog.WarnContext(ctx, env.MSG+"keyValueDataService.put", "msg", "etcd put failed", "key", unit.Key(), "err", err)
+			k.outboxEnqueue(ctx, []string{unit.Key()})
+		}
+		k.keyInvalidate(ctx, unit.Key())
+
+		return result, nil
+	}
+	var revision int64
 	g, c := errgroup.WithContext(ctx)
-	g.Go(func() error {
-		return k.putEtcd(c, unit)
+	g.Go(func() (err error) {
+		revision, err = k.putEtcd(c, unit)
+		return err
 	})
 	g.Go(func() error {
-		return k.putPostgres(c, unit)
+		return result.Upsert(c, k.postgresRepo)
 	})
+	err := g.Wait()
+
+	if err == nil {
+		// Ревизия сопоставляется после записи в оба хранилища, чтобы
+		// попасть в ревизию key_value_history, созданную этой записью.
+		k.markRevision(ctx, result, revision)
+	}
 	k.keyInvalidate(ctx, unit.Key())
-	return g.Wait()
+
+	return result, err
 }
 
-func (k *keyValueDataService) putEtcd(ctx context.Context, unit entity.KeyValue) error {
-	_, err := k.etcdRepo.Do(ctx, entity.KeyValueUpsert, unit, func(domain.Scanner) entity.KeyValue {
-		return entity.KeyValue{}
-	})
-	return err
+// txn условия транзакции проверяются в PostgreSQL, выполненная ветвь
+// операций затем одной транзакцией повторяется в etcd; неудавшийся
+// повтор доставляется в etcd через outbox.
+func (k *keyValueDataService) txn(ctx context.Context, request dto.TxnRequest) (dto.TxnResult, error) {
+
+	if err := authorizeTxn(ctx, k.enforcer, request); err != nil {
+		return dto.TxnResult{}, err
+	}
+	txn, err := makeTxn(request)
+
+	if err != nil {
+		return dto.TxnResult{}, err
+	}
+	if k.outboxConfig.Enabled {
+		return k.txnOutbox(ctx, txn)
+	}
+	succeeded, err := entity.TransactionKeyValue(ctx, k.postgresRepo, txn)
+
+	if err != nil {
+		k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.txn", "msg", "postgres transaction failed", "err", err)
+		return dto.TxnResult{}, err
+	}
+	ops := txn.Success
+
+	if !succeeded {
+		ops = txn.Failure
+	}
+	if len(ops) > 0 {
+		keys := txnKeys(txn, succeeded)
+
+		if _, err = entity.TransactionKeyValue(ctx, k.etcdRepo, domain.Txn[entity.KeyValue]{Success: ops}); err != nil {
+			// PostgreSQL транзакцию уже зафиксировал: ключи доставляются
+			// в etcd через outbox, клиенту возвращается её результат.
+			k.sLog.WarnContext(ctx, env.MSG+"keyValueDataService.txn", "msg", "etcd transaction failed", "err", err)
+			k.outboxEnqueue(ctx, keys)
+		}
+		for _, key := range keys {
+			k.keyInvalidate(ctx, key)
+		}
+	}
+	return dto.TxnResult{Succeeded: succeeded}, nil
+}
+
+// versionConflict дополняет ErrVersionMismatch текущей версией записи.
+func (k *keyValueDataService) versionConflict(ctx context.Context, key string, err error) error {
+
+	if !errors.Is(err, domain.ErrVersionMismatch) {
+		return err
+	}
+	var current int64
+
+	if got, er0 := entity.GetKeyValue(ctx, k.postgresRepo, key); er0 == nil && !got.Deleted() {
+		current = got.Version()
+	}
+	return VersionConflictError{Key: key, Current: current}
+}
+
+// compareAndDeleteEtcd удаление ключа из etcd при текущей версии expected,
+// возвращает ревизию удаления.
+func (k *keyValueDataService) compareAndDeleteEtcd(ctx context.Context, key string, expected int64) (revision int64, err error) {
+	_, err = k.etcdRepo.Do(
+		ctx,
+		entity.MakeKeyValueCompareAndDelete(expected),
+		entity.MakeKeyValue(key, "", 0, entity.DefaultTAttributes()),
+		scanRevision(&revision),
+	)
+	return revision, err
+}
+
+// compareAndSwapEtcd запись в etcd при текущей версии ключа expected,
+// возвращает ревизию записи.
+func (k *keyValueDataService) compareAndSwapEtcd(ctx context.Context, unit entity.KeyValue, expected int64) (revision int64, err error) {
+	_, err = k.etcdRepo.Do(ctx, entity.MakeKeyValueCompareAndSwap(expected), unit, scanRevision(&revision))
+	return revision, err
+}
+
+// deleteEtcd удаление ключа из etcd, возвращает ревизию удаления.
+func (k *keyValueDataService) deleteEtcd(ctx context.Context, key string) (revision int64, err error) {
+	_, err = k.etcdRepo.Do(
+		ctx,
+		entity.KeyValueDelete,
+		entity.MakeKeyValue(key, "", 0, entity.DefaultTAttributes()),
+		scanRevision(&revision),
+	)
+	return revision, err
+}
+
+// putEtcd запись в etcd, возвращает ревизию записи.
+func (k *keyValueDataService) putEtcd(ctx context.Context, unit entity.KeyValue) (revision int64, err error) {
+	_, err = k.etcdRepo.Do(ctx, entity.KeyValueUpsert, unit, scanRevision(&revision))
+	return revision, err
 }
 
-func (k *keyValueDataService) putPostgres(ctx context.Context, unit entity.KeyValue) error {
-	_, err := k.postgresRepo.Do(ctx, entity.KeyValueUpsert, unit, func(domain.Scanner) entity.KeyValue {
+func scanRevision(revision *int64) func(domain.Scanner) entity.KeyValue {
+	return func(s domain.Scanner) entity.KeyValue {
+		if revisioner, ok := s.(domain.Revisioner); ok {
+			*revision = revisioner.Revision()
+		}
 		return entity.KeyValue{}
-	})
-	return err
+	}
+}
+
+func (k *keyValueDataService) cacheSet(ctx context.Context, unit entity.KeyValue) {
+
+	data, err := unit.ToJSON()
+
+	if err != nil {
+		k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.cacheSet", "err", err)
+		return
+	}
+	if err = k.cache.Set(unit.Key(), data, k.cacheExpire); err != nil {
+		k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.cacheSet", "err", err)
+	}
 }
 
-func (k *keyValueDataService) incrementHitCounter(ctx context.Context) {
-	counter := k.hitCounter.Add(1)
+func (k *keyValueDataService) logCacheHit(ctx context.Context) {
 	k.sLog.DebugContext(ctx,
 		env.MSG+"keyValueDataService.get", "msg",
-		fmt.Sprintf("cache hit count: %d", counter),
+		fmt.Sprintf("cache hit count: %d", k.cache.Stats().Hits),
 	)
 }
 
 func (k *keyValueDataService) keyInvalidate(ctx context.Context, key string) {
 
-	client, err := k.pool.AcquireClient()
+	if err := forgetNotFound(k.negative, key); err != nil {
+		k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.keyInvalidate", "msg", "forgetNotFound", "err", err)
+	}
+
+	client, err := k.pool.AcquireClient(ctx)
 
 	if err != nil {
 		k.sLog.DebugContext(ctx,
@@ -227,6 +680,8 @@ func (k *keyValueDataService) keyInvalidate(ctx context.Context, key string) {
 	}
 }
 
+// watch подписки на инвалидацию кэшей через отдельного долгоживущего
+// клиента, чтобы не занимать клиента пула на всё время работы сервиса.
 func (k *keyValueDataService) watch(ctx context.Context, cfg env.Config) {
 
 	cli, err := clientV3.New(*cfg.EtcdClientConfig())
@@ -236,48 +691,86 @@ func (k *keyValueDataService) watch(ctx context.Context, cfg env.Config) {
 			env.MSG+"keyValueDataService.watch",
 			"msg", "new client", "err", err,
 		)
+		return
 	}
 	defer func() { _ = cli.Close() }()
-	rch := cli.Watch(ctx, CacheInvalidate)
-
-	for watchResp := range rch {
-		for _, ev := range watchResp.Events {
-			key := string(ev.Kv.Value)
-			if err := k.cache.Delete(key); err != nil {
-				k.sLog.ErrorContext(ctx,
-					env.MSG+"keyValueDataService.watch",
-					"msg", "cache.Delete",
-					"err", err)
-			} else {
-				k.sLog.DebugContext(ctx,
-					env.MSG+"keyValueDataService.watch",
-					"msg", fmt.Sprintf("Cache invalidate by key: \"%s\" is OK.", key),
-				)
-				k.hitCounter.Store(0)
-			}
-		}
+
+	if k.negative != nil && k.negativeWatch {
+		go watchNegativeForget(ctx, k.sLog, cli, "key_value_data", k.negative)
 	}
+	watchCacheInvalidate(ctx, k.sLog, cli, "key_value_data", invalidatedCaches(k.cache, k.negative)...)
 }
 
 func GetKeyValueDataService(ctx context.Context, cfg env.Config) KeyValueDataService {
 
 	onceKeyValueDataService.Do(func() {
 		keyValueDataServiceInst = new(keyValueDataService)
-		keyValueDataServiceInst.cache = memory.New(memory.Config{
-			GCInterval: cfg.CacheGCInterval(),
-		})
+		keyValueDataServiceInst.cache = newCache(cfg)
 		keyValueDataServiceInst.cacheExpire = cfg.CacheExpire()
+		keyValueDataServiceInst.enforcer = rbac.GetEnforcer(ctx, cfg)
 		keyValueDataServiceInst.etcdRepo = repo.GetKeyValueEtcdRepo(cfg)
-		keyValueDataServiceInst.pool = etcd_pool.GetEtcdPool(cfg)
+		keyValueDataServiceInst.negative = newNegativeCache(cfg)
+		keyValueDataServiceInst.negativeExpire = cfg.CacheConfig().NegativeExpire
+		keyValueDataServiceInst.negativeWatch = cfg.CacheConfig().NegativeWatch
+		keyValueDataServiceInst.outboxConfig = cfg.OutboxConfig()
+		keyValueDataServiceInst.outboxNotify = make(chan struct{}, 1)
+		keyValueDataServiceInst.outboxRepo = repo.GetOutboxPostgresRepo(cfg)
+		keyValueDataServiceInst.pool = etcd_pool.GetPool(cfg)
 		keyValueDataServiceInst.postgresRepo = repo.GetKeyValuePostgresRepo(cfg)
+		keyValueDataServiceInst.purgeConfig = cfg.PurgeConfig()
+		keyValueDataServiceInst.reconcileConfig = cfg.ReconcileConfig()
 		keyValueDataServiceInst.sLog = cfg.Logger()
+		if err := metrics.RegisterCache("key_value_data", keyValueDataServiceInst.cache.Stats); err != nil {
+			keyValueDataServiceInst.sLog.ErrorContext(ctx, env.MSG+"GetKeyValueDataService", "msg", "metrics", "err", err)
+		}
+		if keyValueDataServiceInst.negative != nil {
+			if err := metrics.RegisterCache("key_value_data_negative", keyValueDataServiceInst.negative.Stats); err != nil {
+				keyValueDataServiceInst.sLog.ErrorContext(ctx, env.MSG+"GetKeyValueDataService", "msg", "metrics", "err", err)
+			}
+		}
 		go func() {
 			keyValueDataServiceInst.watch(ctx, cfg)
 		}()
+		go keyValueDataServiceInst.outboxLoop(ctx)
+		if keyValueDataServiceInst.purgeConfig.Enabled {
+			go keyValueDataServiceInst.purgeLoop(ctx)
+		}
+		if keyValueDataServiceInst.reconcileConfig.Enabled {
+			go keyValueDataServiceInst.reconcileLoop(ctx)
+		}
 	})
 	return keyValueDataServiceInst
 }
 
-func makeKetValueWithKeyOnly(key string) entity.KeyValue {
-	return entity.MakeKeyValue(key, "", 0, entity.DefaultTAttributes())
+// MakeKeyValueNow создание записи с текущим временем изменения.
+func MakeKeyValueNow(key, value string) entity.KeyValue {
+	now := time.Now()
+	return entity.MakeKeyValue(key, value, 0, entity.MakeTAttributes(
+		sql.NullBool{Bool: false, Valid: true}, now, sql.NullTime{Time: now, Valid: true},
+	))
+}
+
+func makePbConflictKeyValueData(err error) *pb.KeyValueData {
+
+	var conflict VersionConflictError
+
+	if errors.As(err, &conflict) {
+		return &pb.KeyValueData{Key: conflict.Key, Version: conflict.Current}
+	}
+	return nil
+}
+
+func makePbKeyValueData(unit entity.KeyValue) *pb.KeyValueData {
+
+	result := pb.KeyValueData{
+		Key:       unit.Key(),
+		Value:     unit.Value(),
+		Version:   unit.Version(),
+		Deleted:   unit.Deleted(),
+		CreatedAt: timestamppb.New(unit.CreatedAt()),
+	}
+	if updatedAt := tool.ConvertNullTimeToTimePointer(unit.UpdatedAt()); updatedAt != nil {
+		result.UpdatedAt = timestamppb.New(*updatedAt)
+	}
+	return &result
 }
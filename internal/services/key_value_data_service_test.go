@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/memory"
@@ -15,6 +18,8 @@ import (
 	"go.uber.org/mock/gomock"
 	"log/slog"
 	"testing"
+
+	pb "github.com/victor-skurikhin/etcd-client/v1/proto"
 )
 
 func TestKeyValueDataService(t *testing.T) {
@@ -43,6 +48,71 @@ func TestKeyValueDataService(t *testing.T) {
 			positivePostgresPut1,
 			positivePostgresPut1Check,
 		},
+		{
+			"test #4 positive for struct KeyValueDataService method Delete(context.Context, *pb.KeyValueDataRequest)",
+			positiveGRPCDelete,
+			positiveGRPCStatusOkCheck,
+		},
+		{
+			"test #5 positive for struct KeyValueDataService method Get(context.Context, *pb.KeyValueDataRequest)",
+			positiveGRPCGet,
+			positiveGRPCGetCheck,
+		},
+		{
+			"test #6 positive for struct KeyValueDataService method Put(context.Context, *pb.KeyValueDataRequest)",
+			positiveGRPCPut,
+			positiveGRPCStatusOkCheck,
+		},
+		{
+			"test #7 negative for struct KeyValueDataService method Get(context.Context, *pb.KeyValueDataRequest)",
+			negativeGRPCGet,
+			negativeGRPCGetCheck,
+		},
+		{
+			"test #8 positive for struct KeyValueDataService method ApiList(context.Context, string, string, int64, bool)",
+			positivePostgresList,
+			positivePostgresListCheck,
+		},
+		{
+			"test #9 positive for struct KeyValueDataService method List(context.Context, *pb.ListRequest)",
+			positiveGRPCList,
+			positiveGRPCListCheck,
+		},
+		{
+			"test #10 negative for struct KeyValueDataService method ApiPut(context.Context, entity.KeyValue, *int64)",
+			negativePostgresCompareAndSwap,
+			negativePostgresCompareAndSwapCheck,
+		},
+		{
+			"test #11 negative for struct KeyValueDataService method Put(context.Context, *pb.KeyValueDataRequest) with expected version",
+			negativeGRPCCompareAndSwap,
+			negativeGRPCCompareAndSwapCheck,
+		},
+		{
+			"test #12 positive for struct KeyValueDataService method ApiTxn(context.Context, dto.TxnRequest)",
+			positivePostgresTxn,
+			positivePostgresTxnCheck,
+		},
+		{
+			"test #13 negative for struct KeyValueDataService method Txn(context.Context, *pb.TxnRequest)",
+			negativeGRPCTxn,
+			negativeGRPCTxnCheck,
+		},
+		{
+			"test #14 positive for struct KeyValueDataService method ApiList(context.Context, string, string, int64, bool) cursor",
+			positivePostgresListCursor,
+			positivePostgresListCursorCheck,
+		},
+		{
+			"test #15 positive for struct KeyValueDataService method ApiPut(context.Context, entity.KeyValue, *int64) etcd unavailable",
+			positiveEtcdCompareAndSwapFailed,
+			positiveEtcdCompareAndSwapFailedCheck,
+		},
+		{
+			"test #16 positive for struct KeyValueDataService method ApiDelete(context.Context, string, *int64) etcd unavailable",
+			positiveEtcdCompareAndDeleteFailed,
+			positiveEtcdCompareAndSwapFailedCheck,
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			got, err := test.fRun(t)
@@ -92,7 +162,7 @@ func positivePostgresDelete1(t *testing.T) (interface{}, error) {
 		AnyTimes()
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 	etcdPoolMock.
@@ -108,7 +178,7 @@ func positivePostgresDelete1(t *testing.T) (interface{}, error) {
 
 	srv := newTestKeyValueDataService(cfg2, etcdRepo, etcdPoolMock, postgresRepo)
 
-	return srv, srv.Delete(context.Background(), "key1")
+	return srv, srv.ApiDelete(context.Background(), "key1", nil)
 }
 
 func positivePostgresDelete1Check(_ *testing.T, i interface{}) bool {
@@ -137,7 +207,7 @@ func positivePostgresGet1(t *testing.T) (interface{}, error) {
 		AnyTimes()
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 	etcdPoolMock.
@@ -152,7 +222,7 @@ func positivePostgresGet1(t *testing.T) (interface{}, error) {
 		AnyTimes()
 
 	srv := newTestKeyValueDataService(cfg2, etcdRepo, etcdPoolMock, postgresRepo)
-	unit, err := srv.Get(context.Background(), "key1")
+	unit, err := srv.ApiGet(context.Background(), "key1")
 
 	return unit, err
 }
@@ -183,7 +253,7 @@ func positivePostgresPut1(t *testing.T) (interface{}, error) {
 		AnyTimes()
 	etcdPoolMock.
 		EXPECT().
-		AcquireClient().
+		AcquireClient(gomock.Any()).
 		Return(&kvTestStub{}, nil).
 		AnyTimes()
 	etcdPoolMock.
@@ -199,7 +269,9 @@ func positivePostgresPut1(t *testing.T) (interface{}, error) {
 
 	srv := newTestKeyValueDataService(cfg2, etcdRepo, etcdPoolMock, postgresRepo)
 
-	return srv, srv.Put(context.Background(), entity.KeyValue{})
+	_, err := srv.ApiPut(context.Background(), entity.KeyValue{}, nil)
+
+	return srv, err
 }
 
 func positivePostgresPut1Check(t *testing.T, i interface{}) bool {
@@ -207,6 +279,315 @@ func positivePostgresPut1Check(t *testing.T, i interface{}) bool {
 	return ok
 }
 
+func positiveGRPCDelete(t *testing.T) (interface{}, error) {
+	srv := newTestKeyValueDataServiceWithMocks(t)
+	return srv.Delete(context.Background(), &pb.KeyValueDataRequest{
+		Union: &pb.KeyValueDataRequest_Key{Key: &pb.Key{Key: "key1"}},
+	})
+}
+
+func positiveGRPCGet(t *testing.T) (interface{}, error) {
+	srv := newTestKeyValueDataServiceWithMocks(t)
+	return srv.Get(context.Background(), &pb.KeyValueDataRequest{
+		Union: &pb.KeyValueDataRequest_Key{Key: &pb.Key{Key: "key1"}},
+	})
+}
+
+func positiveGRPCGetCheck(t *testing.T, i interface{}) bool {
+	if response, ok := i.(*pb.KeyValueDataResponse); ok {
+		return response.Status == pb.Status_OK && response.KeyValueData != nil
+	}
+	return false
+}
+
+func positiveGRPCPut(t *testing.T) (interface{}, error) {
+	srv := newTestKeyValueDataServiceWithMocks(t)
+	return srv.Put(context.Background(), &pb.KeyValueDataRequest{
+		Union: &pb.KeyValueDataRequest_KeyValue{KeyValue: &pb.KeyValue{Key: "key1", Value: "value1"}},
+	})
+}
+
+func positiveGRPCStatusOkCheck(t *testing.T, i interface{}) bool {
+	if response, ok := i.(*pb.KeyValueDataResponse); ok {
+		return response.Status == pb.Status_OK
+	}
+	return false
+}
+
+func negativeGRPCGet(t *testing.T) (interface{}, error) {
+	srv := newTestKeyValueDataServiceWithMocks(t)
+	return srv.Get(context.Background(), &pb.KeyValueDataRequest{
+		Union: &pb.KeyValueDataRequest_KeyValue{KeyValue: &pb.KeyValue{Key: "key1", Value: "value1"}},
+	})
+}
+
+func negativeGRPCGetCheck(t *testing.T, i interface{}) bool {
+	if response, ok := i.(*pb.KeyValueDataResponse); ok {
+		return response.Status == pb.Status_FAIL && response.Error == BadOneOfUnionValue
+	}
+	return false
+}
+
+func positivePostgresList(t *testing.T) (interface{}, error) {
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+	return newTestKeyValueDataServiceWithList(t).ApiList(context.Background(), "key", "", 2, false)
+}
+
+func positivePostgresListCheck(t *testing.T, i interface{}) bool {
+	if result, ok := i.(dto.List[entity.KeyValue]); ok {
+		return assert.Len(t, result.Items, 2) &&
+			assert.Equal(t, "key1", result.Items[0].Key()) &&
+			assert.Equal(t, "key3", result.Items[1].Key()) &&
+			assert.False(t, result.More)
+	}
+	return false
+}
+
+func positivePostgresListCursor(t *testing.T) (interface{}, error) {
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+	return newTestKeyValueDataServiceWithList(t).ApiList(context.Background(), "key", "", 1, false)
+}
+
+func positivePostgresListCursorCheck(t *testing.T, i interface{}) bool {
+	if result, ok := i.(dto.List[entity.KeyValue]); ok {
+		return assert.Len(t, result.Items, 1) &&
+			assert.Equal(t, "key1", result.Items[0].Key()) &&
+			assert.True(t, result.More) &&
+			assert.Equal(t, "key3", result.Next)
+	}
+	return false
+}
+
+func positiveGRPCList(t *testing.T) (interface{}, error) {
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+	return newTestKeyValueDataServiceWithList(t).List(context.Background(), &pb.ListRequest{
+		Prefix: "key", Limit: 5, KeysOnly: true,
+	})
+}
+
+func positiveGRPCListCheck(t *testing.T, i interface{}) bool {
+	if response, ok := i.(*pb.KeyValueDataListResponse); ok {
+		return assert.Equal(t, pb.Status_OK, response.Status) &&
+			assert.Len(t, response.KeyValueData, 2) &&
+			assert.Equal(t, "", response.KeyValueData[1].Value) &&
+			assert.False(t, response.More)
+	}
+	return false
+}
+
+func negativePostgresCompareAndSwap(t *testing.T) (interface{}, error) {
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+	expected := int64(3)
+	_, err := newTestKeyValueDataServiceWithConflict(t).
+		ApiPut(context.Background(), MakeKeyValueNow("key1", "value1"), &expected)
+	return err, nil
+}
+
+func negativePostgresCompareAndSwapCheck(t *testing.T, i interface{}) bool {
+	var conflict VersionConflictError
+	if err, ok := i.(error); ok && errors.As(err, &conflict) {
+		return assert.ErrorIs(t, err, domain.ErrVersionMismatch) &&
+			assert.Equal(t, "key1", conflict.Key)
+	}
+	return false
+}
+
+func negativeGRPCCompareAndSwap(t *testing.T) (interface{}, error) {
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+	expected := int64(3)
+	return newTestKeyValueDataServiceWithConflict(t).Put(context.Background(), &pb.KeyValueDataRequest{
+		Union:           &pb.KeyValueDataRequest_KeyValue{KeyValue: &pb.KeyValue{Key: "key1", Value: "value1"}},
+		ExpectedVersion: &expected,
+	})
+}
+
+func negativeGRPCCompareAndSwapCheck(t *testing.T, i interface{}) bool {
+	if response, ok := i.(*pb.KeyValueDataResponse); ok {
+		return assert.Equal(t, pb.Status_FAIL, response.Status) &&
+			assert.NotNil(t, response.KeyValueData) &&
+			assert.Equal(t, "key1", response.KeyValueData.Key)
+	}
+	return false
+}
+
+func positiveEtcdCompareAndSwapFailed(t *testing.T) (interface{}, error) {
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+	expected := int64(3)
+	srv := newTestKeyValueDataServiceWithEtcdFailed(t)
+	if _, err := srv.ApiPut(context.Background(), MakeKeyValueNow("key1", "value1"), &expected); err != nil {
+		return nil, err
+	}
+	return len(srv.outboxNotify), nil
+}
+
+func positiveEtcdCompareAndDeleteFailed(t *testing.T) (interface{}, error) {
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+	expected := int64(3)
+	srv := newTestKeyValueDataServiceWithEtcdFailed(t)
+	if err := srv.ApiDelete(context.Background(), "key1", &expected); err != nil {
+		return nil, err
+	}
+	return len(srv.outboxNotify), nil
+}
+
+func positiveEtcdCompareAndSwapFailedCheck(t *testing.T, i interface{}) bool {
+	return assert.Equal(t, 1, i)
+}
+
+func positivePostgresTxn(t *testing.T) (interface{}, error) {
+	srv := newTestKeyValueDataServiceWithMocks(t)
+	return srv.ApiTxn(context.Background(), dto.TxnRequest{
+		Compare: []dto.TxnCompare{{Key: "key1", Target: "version", Result: "equal", Version: 1}},
+		Success: []dto.TxnOp{{Type: "put", Key: "key1", Value: "value2"}, {Type: "delete", Key: "key2"}},
+	})
+}
+
+func positivePostgresTxnCheck(t *testing.T, i interface{}) bool {
+	if result, ok := i.(dto.TxnResult); ok {
+		return assert.True(t, result.Succeeded)
+	}
+	return false
+}
+
+func negativeGRPCTxn(t *testing.T) (interface{}, error) {
+	srv := newTestKeyValueDataServiceWithMocks(t)
+	return srv.Txn(context.Background(), &pb.TxnRequest{
+		Success: []*pb.TxnOp{{Type: pb.EventType(7), KeyValue: &pb.KeyValue{Key: "key1"}}},
+	})
+}
+
+func negativeGRPCTxnCheck(t *testing.T, i interface{}) bool {
+	if response, ok := i.(*pb.TxnResponse); ok {
+		return assert.Equal(t, pb.Status_FAIL, response.Status) &&
+			assert.Contains(t, response.Error, ErrBadTxnOp.Error())
+	}
+	return false
+}
+
+func newTestKeyValueDataServiceWithConflict(t *testing.T) *keyValueDataService {
+
+	cfg := env.GetConfig()
+	ctrl := gomock.NewController(t)
+	etcdRepo := NewMockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](ctrl)
+	postgresRepo := NewMockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](ctrl)
+	postgresRepo.
+		EXPECT().
+		Do(gomock.Any(), entity.MakeKeyValueCompareAndSwap(3), gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, domain.ErrVersionMismatch).
+		Times(1)
+	postgresRepo.
+		EXPECT().
+		Do(gomock.Any(), entity.KeyValueSelect, gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, fmt.Errorf("no rows")).
+		Times(1)
+
+	return newTestKeyValueDataService(cfg, etcdRepo, NewMockEtcdPool(ctrl), postgresRepo)
+}
+
+// newTestKeyValueDataServiceWithEtcdFailed условная запись в PostgreSQL
+// проходит, а etcd недоступен: ключ ставится в outbox.
+func newTestKeyValueDataServiceWithEtcdFailed(t *testing.T) *keyValueDataService {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	srv.outboxConfig.Enabled = false
+	mocks.postgres.
+		EXPECT().
+		Do(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, nil).
+		AnyTimes()
+	mocks.etcd.
+		EXPECT().
+		Do(gomock.Any(), entity.KeyValueUpsert, gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, fmt.Errorf("etcd unavailable")).
+		AnyTimes()
+	mocks.etcd.
+		EXPECT().
+		Do(gomock.Any(), entity.KeyValueDelete, gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, fmt.Errorf("etcd unavailable")).
+		AnyTimes()
+	mocks.outbox.
+		EXPECT().
+		Do(gomock.Any(), entity.OutboxEnqueue, entity.MakeOutbox(0, "key1"), gomock.Any()).
+		Return(entity.Outbox{}, nil).
+		Times(1)
+
+	return srv
+}
+
+func newTestKeyValueDataServiceWithList(t *testing.T) *keyValueDataService {
+
+	cfg := env.GetConfig()
+	ctrl := gomock.NewController(t)
+	etcdRepo := NewMockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](ctrl)
+	postgresRepo := NewMockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](ctrl)
+	postgresRepo.
+		EXPECT().
+		Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{
+			MakeKeyValueNow("key1", "value1"),
+			MakeKeyValueNow(CacheInvalidate, "key0"),
+			MakeKeyValueNow("key3", "value3"),
+		}, nil).
+		Times(1)
+
+	return newTestKeyValueDataService(cfg, etcdRepo, NewMockEtcdPool(ctrl), postgresRepo)
+}
+
+func newTestKeyValueDataServiceWithMocks(t *testing.T) *keyValueDataService {
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+
+	cfg0 := env.GetConfig()
+	cfg1 := cfg0.(env.TestConfig)
+	cfg2 := cfg1.GetTestConfig(
+		env.WithTestDBPool("", nil),
+		env.WithEtcdClientConfig(clientV3.Config{Endpoints: []string{"localhost:0"}}),
+	)
+	ctrl := gomock.NewController(t)
+	etcdRepo := NewMockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](ctrl)
+	etcdPoolMock := NewMockEtcdPool(ctrl)
+	postgresRepo := NewMockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](ctrl)
+	etcdRepo.
+		EXPECT().
+		Do(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, nil).
+		AnyTimes()
+	etcdPoolMock.
+		EXPECT().
+		AcquireClient(gomock.Any()).
+		Return(&kvTestStub{}, nil).
+		AnyTimes()
+	etcdPoolMock.
+		EXPECT().
+		ReleaseClient(gomock.Any()).
+		Return(nil).
+		AnyTimes()
+	postgresRepo.
+		EXPECT().
+		Do(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, nil).
+		AnyTimes()
+	etcdRepo.
+		EXPECT().
+		Transaction(gomock.Any(), entity.KeyValueTransaction, gomock.Any()).
+		Return(true, nil).
+		AnyTimes()
+	postgresRepo.
+		EXPECT().
+		Transaction(gomock.Any(), entity.KeyValueTransaction, gomock.Any()).
+		Return(true, nil).
+		AnyTimes()
+
+	return newTestKeyValueDataService(cfg2, etcdRepo, etcdPoolMock, postgresRepo)
+}
+
 func newTestKeyValueDataService(
 	cfg env.Config,
 	etcdRepo domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue],
@@ -229,6 +610,7 @@ func newTestKeyValueDataService(
 var _ clientV3.KV = (*kvTestStub)(nil)
 
 type kvTestStub struct {
+	lease int64
 }
 
 func (k *kvTestStub) Put(_ context.Context, _, _ string, _ ...clientV3.OpOption) (*clientV3.PutResponse, error) {
@@ -237,7 +619,7 @@ func (k *kvTestStub) Put(_ context.Context, _, _ string, _ ...clientV3.OpOption)
 
 func (k *kvTestStub) Get(_ context.Context, _ string, _ ...clientV3.OpOption) (*clientV3.GetResponse, error) {
 	kvs := make([]*mvccpb.KeyValue, 0)
-	kvs = append(kvs, &mvccpb.KeyValue{Key: []byte("key1"), Value: []byte("value1"), Version: 1})
+	kvs = append(kvs, &mvccpb.KeyValue{Key: []byte("key1"), Value: []byte("value1"), Version: 1, Lease: k.lease})
 	return &clientV3.GetResponse{
 		Header: &etcdserverpb.ResponseHeader{},
 		Kvs:    kvs,
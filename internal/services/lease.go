@@ -0,0 +1,217 @@
+/*
+ * This file was last modified at 2026-10-18 14:00 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * lease.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/rbac"
+	"time"
+
+	pb "github.com/victor-skurikhin/etcd-client/v1/proto"
+	clientV3 "go.etcd.io/etcd/client/v3"
+)
+
+// leaseCacheMargin запас на округление оставшегося TTL аренды etcd до секунд.
+const leaseCacheMargin = time.Second
+
+var ErrLeaseExpired = fmt.Errorf("lease expired or not found")
+
+func (f *etcdProxyService) LeaseGrant(ctx context.Context, request *pb.LeaseGrantRequest) (*pb.LeaseResponse, error) {
+
+	f.sLog.InfoContext(ctx, env.MSG+"EtcdProxyService.LeaseGrant", "msg", "gRPC", "request", request)
+
+	got, err := f.leaseGrant(ctx, request.GetTtlSeconds())
+
+	if denied := permissionDenied(err); denied != nil {
+		return nil, denied
+	}
+	return makePbLeaseResponse(got, err), nil
+}
+
+func (f *etcdProxyService) LeaseKeepAlive(ctx context.Context, request *pb.LeaseRequest) (*pb.LeaseResponse, error) {
+
+	f.sLog.InfoContext(ctx, env.MSG+"EtcdProxyService.LeaseKeepAlive", "msg", "gRPC", "request", request)
+
+	got, err := f.leaseKeepAlive(ctx, request.GetId())
+
+	if denied := permissionDenied(err); denied != nil {
+		return nil, denied
+	}
+	return makePbLeaseResponse(got, err), nil
+}
+
+func (f *etcdProxyService) LeaseRevoke(ctx context.Context, request *pb.LeaseRequest) (*pb.LeaseResponse, error) {
+
+	f.sLog.InfoContext(ctx, env.MSG+"EtcdProxyService.LeaseRevoke", "msg", "gRPC", "request", request)
+
+	err := f.leaseRevoke(ctx, request.GetId())
+
+	if denied := permissionDenied(err); denied != nil {
+		return nil, denied
+	}
+	return makePbLeaseResponse(dto.Lease{ID: request.GetId()}, err), nil
+}
+
+func (f *etcdProxyService) leaseGrant(ctx context.Context, ttlSeconds int64) (dto.Lease, error) {
+
+	if err := f.enforcer.AuthorizeAny(ctx, rbac.ActionWrite); err != nil {
+		return dto.Lease{}, err
+	}
+	if f.client == nil {
+		return dto.Lease{}, ErrNoClient
+	}
+	resp, err := f.client.Grant(ctx, ttlSeconds)
+
+	if err != nil {
+		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.leaseGrant", "msg", "client.Grant", "err", err)
+		return dto.Lease{}, err
+	}
+	return dto.Lease{ID: int64(resp.ID), TTLSeconds: resp.TTL}, nil
+}
+
+func (f *etcdProxyService) leaseKeepAlive(ctx context.Context, id int64) (dto.Lease, error) {
+
+	if _, err := f.leaseAuthorize(ctx, clientV3.LeaseID(id)); err != nil {
+		return dto.Lease{}, err
+	}
+	resp, err := f.client.KeepAliveOnce(ctx, clientV3.LeaseID(id))
+
+	if err != nil {
+		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.leaseKeepAlive", "msg", "client.KeepAliveOnce", "err", err)
+		return dto.Lease{}, err
+	}
+	return dto.Lease{ID: int64(resp.ID), TTLSeconds: resp.TTL}, nil
+}
+
+// leaseRevoke отзыв аренды удаляет привязанные к ней ключи, поэтому они
+// инвалидируются в кэшах так же, как при удалении.
+func (f *etcdProxyService) leaseRevoke(ctx context.Context, id int64) error {
+
+	keys, err := f.leaseAuthorize(ctx, clientV3.LeaseID(id))
+
+	if err != nil {
+		return err
+	}
+	if _, err = f.client.Revoke(ctx, clientV3.LeaseID(id)); err != nil {
+		f.sLog.ErrorContext(ctx, env.MSG+"EtcdProxyService.leaseRevoke", "msg", "client.Revoke", "err", err)
+		return err
+	}
+	f.leaseKeysInvalidate(ctx, f.client, keys)
+
+	return nil
+}
+
+func (f *etcdProxyService) leaseKeysInvalidate(ctx context.Context, client clientV3.KV, keys [][]byte) {
+	for _, key := range keys {
+		f.keyInvalidate(ctx, client, string(key))
+	}
+}
+
+func (f *etcdProxyService) timeToLive(
+	ctx context.Context,
+	lease clientV3.LeaseID,
+	opts ...clientV3.LeaseOption,
+) (*clientV3.LeaseTimeToLiveResponse, error) {
+
+	if f.client == nil {
+		return nil, ErrNoClient
+	}
+	return f.client.TimeToLive(ctx, lease, opts...)
+}
+
+// leaseAuthorize права на продление и отзыв аренды: отзыв удаляет все
+// привязанные к ней ключи, поэтому клиенту нужна запись в каждый из них.
+// Возвращает привязанные к аренде ключи.
+func (f *etcdProxyService) leaseAuthorize(ctx context.Context, lease clientV3.LeaseID) ([][]byte, error) {
+
+	if err := f.enforcer.AuthorizeAny(ctx, rbac.ActionWrite); err != nil {
+		return nil, err
+	}
+	resp, err := f.timeToLive(ctx, lease, clientV3.WithAttachedKeys())
+
+	if err != nil {
+		return nil, err
+	}
+	return resp.Keys, f.leaseKeysAuthorize(ctx, resp.Keys)
+}
+
+// leaseKeysAuthorize ErrPermissionDenied, если хотя бы один из ключей
+// аренды клиенту не разрешено записывать: аренда принадлежит другому клиенту.
+func (f *etcdProxyService) leaseKeysAuthorize(ctx context.Context, keys [][]byte) error {
+
+	for _, key := range keys {
+		if err := f.enforcer.Authorize(ctx, rbac.ActionWrite, string(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putLease аренда для записи: переданная в data.Lease или новая на
+// data.TTLSeconds секунд; granted — аренда выдана под эту запись.
+// Чужая аренда, с ключами вне прав клиента на запись, отклоняется.
+func (f *etcdProxyService) putLease(
+	ctx context.Context,
+	data dto.KeyValue,
+) (lease clientV3.LeaseID, ttl int64, granted bool, err error) {
+
+	if lease = clientV3.LeaseID(data.Lease); lease != clientV3.NoLease {
+		resp, err := f.timeToLive(ctx, lease, clientV3.WithAttachedKeys())
+
+		if err != nil {
+			return clientV3.NoLease, 0, false, err
+		}
+		if resp.TTL <= 0 {
+			return clientV3.NoLease, 0, false, fmt.Errorf("%w, id: %d", ErrLeaseExpired, data.Lease)
+		}
+		if err = f.leaseKeysAuthorize(ctx, resp.Keys); err != nil {
+			return clientV3.NoLease, 0, false, err
+		}
+		return lease, resp.TTL, false, nil
+	}
+	if data.TTLSeconds > 0 {
+		got, err := f.leaseGrant(ctx, data.TTLSeconds)
+
+		if err != nil {
+			return clientV3.NoLease, 0, false, err
+		}
+		return clientV3.LeaseID(got.ID), got.TTLSeconds, true, nil
+	}
+	return clientV3.NoLease, 0, false, nil
+}
+
+// leaseCacheExpire срок хранения в кэше записи с арендой: не дольше
+// оставшегося TTL аренды, чтобы кэш не отдавал истёкший ключ.
+func leaseCacheExpire(expire time.Duration, ttlSeconds int64) time.Duration {
+
+	ttl := time.Duration(ttlSeconds)*time.Second - leaseCacheMargin
+
+	if ttl <= 0 {
+		return 0
+	}
+	if expire <= 0 || ttl < expire {
+		return ttl
+	}
+	return expire
+}
+
+func makePbLeaseResponse(lease dto.Lease, err error) *pb.LeaseResponse {
+
+	if err != nil {
+		return &pb.LeaseResponse{Id: lease.ID, Error: err.Error(), Status: pb.Status_FAIL}
+	}
+	return &pb.LeaseResponse{Id: lease.ID, TtlSeconds: lease.TTLSeconds, Status: pb.Status_OK}
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
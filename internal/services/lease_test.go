@@ -0,0 +1,194 @@
+/*
+ * This file was last modified at 2026-10-18 14:00 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * lease_test.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/memory"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/rbac"
+	"log/slog"
+	"testing"
+	"time"
+
+	pb "github.com/victor-skurikhin/etcd-client/v1/proto"
+)
+
+func TestLease(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for function leaseCacheExpire(time.Duration, int64) capped by lease TTL",
+			positiveLeaseCacheExpireTTL,
+			positiveLeaseCacheExpireTTLCheck,
+		},
+		{
+			"test #1 positive for function leaseCacheExpire(time.Duration, int64) capped by cache expire",
+			positiveLeaseCacheExpireCache,
+			positiveLeaseCacheExpireCacheCheck,
+		},
+		{
+			"test #2 negative for function leaseCacheExpire(time.Duration, int64) lease about to expire",
+			negativeLeaseCacheExpire,
+			negativeLeaseCacheExpireCheck,
+		},
+		{
+			"test #3 positive for function makePbLeaseResponse(dto.Lease, error)",
+			positiveMakePbLeaseResponse,
+			positiveMakePbLeaseResponseCheck,
+		},
+		{
+			"test #4 negative for function makePbLeaseResponse(dto.Lease, error)",
+			negativeMakePbLeaseResponse,
+			negativeMakePbLeaseResponseCheck,
+		},
+		{
+			"test #5 positive for method leaseKeysAuthorize(context.Context, [][]byte) own lease",
+			positiveLeaseKeysAuthorize,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, true, i) },
+		},
+		{
+			"test #6 negative for method leaseKeysAuthorize(context.Context, [][]byte) foreign lease",
+			negativeLeaseKeysAuthorize,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, true, i) },
+		},
+		{
+			"test #7 positive for method leaseKeysInvalidate(context.Context, clientV3.KV, [][]byte)",
+			positiveLeaseKeysInvalidate,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, []bool{false, false, true}, i) },
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveLeaseCacheExpireTTL(_ *testing.T) (interface{}, error) {
+	return leaseCacheExpire(time.Minute, 10), nil
+}
+
+func positiveLeaseCacheExpireTTLCheck(t *testing.T, i interface{}) bool {
+	return assert.Equal(t, 10*time.Second-leaseCacheMargin, i)
+}
+
+func positiveLeaseCacheExpireCache(_ *testing.T) (interface{}, error) {
+	return leaseCacheExpire(5*time.Second, 3600), nil
+}
+
+func positiveLeaseCacheExpireCacheCheck(t *testing.T, i interface{}) bool {
+	return assert.Equal(t, 5*time.Second, i)
+}
+
+func negativeLeaseCacheExpire(_ *testing.T) (interface{}, error) {
+	return leaseCacheExpire(time.Minute, 1), nil
+}
+
+func negativeLeaseCacheExpireCheck(t *testing.T, i interface{}) bool {
+	return assert.Equal(t, time.Duration(0), i)
+}
+
+func positiveMakePbLeaseResponse(_ *testing.T) (interface{}, error) {
+	return makePbLeaseResponse(dto.Lease{ID: 7, TTLSeconds: 30}, nil), nil
+}
+
+func positiveMakePbLeaseResponseCheck(t *testing.T, i interface{}) bool {
+	if r, ok := i.(*pb.LeaseResponse); ok {
+		return assert.Equal(t, int64(7), r.Id) &&
+			assert.Equal(t, int64(30), r.TtlSeconds) &&
+			assert.Equal(t, pb.Status_OK, r.Status)
+	}
+	return false
+}
+
+func negativeMakePbLeaseResponse(_ *testing.T) (interface{}, error) {
+	return makePbLeaseResponse(dto.Lease{ID: 7}, errors.New("lease not found")), nil
+}
+
+func negativeMakePbLeaseResponseCheck(t *testing.T, i interface{}) bool {
+	if r, ok := i.(*pb.LeaseResponse); ok {
+		return assert.Equal(t, "lease not found", r.Error) &&
+			assert.Equal(t, pb.Status_FAIL, r.Status)
+	}
+	return false
+}
+
+func positiveLeaseKeysAuthorize(_ *testing.T) (interface{}, error) {
+
+	srv, err := newTestLeaseService()
+
+	if err != nil {
+		return nil, err
+	}
+	err = srv.leaseKeysAuthorize(newTestPrincipalContext(), [][]byte{[]byte("key1"), []byte("key10")})
+
+	return err == nil, nil
+}
+
+func negativeLeaseKeysAuthorize(_ *testing.T) (interface{}, error) {
+
+	srv, err := newTestLeaseService()
+
+	if err != nil {
+		return nil, err
+	}
+	err = srv.leaseKeysAuthorize(newTestPrincipalContext(), [][]byte{[]byte("key1"), []byte("key2")})
+
+	return errors.Is(err, rbac.ErrPermissionDenied), nil
+}
+
+func positiveLeaseKeysInvalidate(_ *testing.T) (interface{}, error) {
+
+	srv, err := newTestLeaseService()
+
+	if err != nil {
+		return nil, err
+	}
+	srv.negative = memory.NewLRU(memory.Config{MaxEntries: 10})
+	defer func() { _ = srv.negative.Close() }()
+
+	for _, key := range []string{"key1", "key10", "key2"} {
+		if err = cacheNotFound(srv.negative, key, time.Minute); err != nil {
+			return nil, err
+		}
+	}
+	srv.leaseKeysInvalidate(context.Background(), &kvTestStub{}, [][]byte{[]byte("key1"), []byte("key10")})
+
+	return []bool{
+		cachedNotFound(srv.negative, "key1"),
+		cachedNotFound(srv.negative, "key10"),
+		cachedNotFound(srv.negative, "key2"),
+	}, nil
+}
+
+func newTestLeaseService() (*etcdProxyService, error) {
+
+	enforcer, err := rbac.NewEnforcer(env.RBACConfig{
+		Bindings: map[string][]string{"api_key:test": {"writer"}},
+		Enabled:  true,
+		Roles: map[string][]env.RBACRule{
+			"writer": {{Actions: []string{"write"}, Prefix: "key1"}},
+		},
+	}, slog.Default())
+
+	return &etcdProxyService{enforcer: enforcer, sLog: slog.Default()}, err
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
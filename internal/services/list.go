@@ -0,0 +1,84 @@
+/*
+ * This file was last modified at 2026-10-18 10:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * list.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+)
+
+const (
+	DefaultListLimit int64 = 100
+	MaxListLimit     int64 = 1000
+)
+
+// listLimit размер страницы с учётом значения по умолчанию и верхней границы.
+func listLimit(limit int64) int64 {
+	if limit < 1 {
+		return DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		return MaxListLimit
+	}
+	return limit
+}
+
+// listItem элемент страницы, при keysOnly без значения записи.
+func listItem(keysOnly bool) func(entity.KeyValue) entity.KeyValue {
+	return func(unit entity.KeyValue) entity.KeyValue {
+		if keysOnly {
+			return entity.MakeKeyValue(unit.Key(), "", unit.Version(), unit.TAttributes)
+		}
+		return unit
+	}
+}
+
+// listFetchLimit размер выборки для страницы размером limit: лишняя запись
+// служит курсором следующей страницы, ещё одна восполняет служебный ключ
+// CacheInvalidate, если он попал в выборку.
+func listFetchLimit(limit int64) int64 {
+	return limit + 2
+}
+
+// makeListPage формирует страницу из выборки размером listFetchLimit(limit):
+// служебный ключ CacheInvalidate отбрасывается до подсчёта записей, первая
+// запись сверх limit служит курсором следующей страницы. Записи, ключи
+// которых не прошли allow, в страницу не попадают, но курсор от них не зависит.
+func makeListPage[T any](
+	units []entity.KeyValue,
+	limit int64,
+	allow func(string) bool,
+	convert func(entity.KeyValue) T,
+) dto.List[T] {
+
+	result := dto.List[T]{Items: make([]T, 0, len(units))}
+	var count int64
+
+	for _, unit := range units {
+		if unit.Key() == CacheInvalidate {
+			continue
+		}
+		if count == limit {
+			result.More = true
+			result.Next = unit.Key()
+			break
+		}
+		count++
+
+		if !allow(unit.Key()) {
+			continue
+		}
+		result.Items = append(result.Items, convert(unit))
+	}
+	return result
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,237 @@
+/*
+ * This file was last modified at 2026-10-18 19:20 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * outbox.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"sort"
+	"time"
+)
+
+const (
+	defaultOutboxBatchSize = 100
+	defaultOutboxInterval  = time.Second
+	defaultOutboxLease     = 30 * time.Second
+)
+
+// putOutbox запись в PostgreSQL вместе с событием outbox в одной транзакции,
+// в etcd запись доставляется outboxLoop, поэтому недоступность etcd
+// не приводит к ошибке записи.
+func (k *keyValueDataService) putOutbox(ctx context.Context, unit entity.KeyValue, expectedVersion *int64) (entity.KeyValue, error) {
+
+	result := unit
+
+	if expectedVersion != nil {
+		if err := result.CompareAndSwapOutbox(ctx, k.postgresRepo, *expectedVersion); err != nil {
+			return unit, k.versionConflict(ctx, unit.Key(), err)
+		}
+	} else if err := result.UpsertOutbox(ctx, k.postgresRepo); err != nil {
+		return unit, err
+	}
+	k.outboxWake()
+
+	return result, nil
+}
+
+func (k *keyValueDataService) deleteOutbox(ctx context.Context, key string, expectedVersion *int64) error {
+
+	if expectedVersion != nil {
+		unit := MakeKeyValueNow(key, "")
+		if err := unit.CompareAndDeleteOutbox(ctx, k.postgresRepo, *expectedVersion); err != nil {
+			return k.versionConflict(ctx, key, err)
+		}
+	} else if _, err := k.postgresRepo.Do(
+		ctx,
+		entity.KeyValueDeleteOutbox,
+		MakeKeyValueNow(key, ""),
+		func(domain.Scanner) entity.KeyValue {
+			return entity.KeyValue{}
+		}); err != nil {
+		return err
+	}
+	k.outboxWake()
+
+	return nil
+}
+
+func (k *keyValueDataService) txnOutbox(ctx context.Context, txn domain.Txn[entity.KeyValue]) (dto.TxnResult, error) {
+
+	succeeded, err := entity.TransactionKeyValueOutbox(ctx, k.postgresRepo, txn)
+
+	if err != nil {
+		k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.txnOutbox", "msg", "postgres transaction failed", "err", err)
+		return dto.TxnResult{}, err
+	}
+	k.outboxWake()
+
+	return dto.TxnResult{Succeeded: succeeded}, nil
+}
+
+// outboxEnqueue события outbox для ключей, изменённых в PostgreSQL, но не
+// записанных в etcd; не добавленные события исправляет сверка (reconcile).
+func (k *keyValueDataService) outboxEnqueue(ctx context.Context, keys []string) {
+
+	for _, key := range keys {
+		event := entity.MakeOutbox(0, key)
+
+		if err := event.Enqueue(ctx, k.outboxRepo); err != nil {
+			k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.outboxEnqueue", "key", key, "err", err)
+		}
+	}
+	k.outboxWake()
+}
+
+// outboxWake внеочередной проход outboxLoop после записи.
+func (k *keyValueDataService) outboxWake() {
+	select {
+	case k.outboxNotify <- struct{}{}:
+	default:
+	}
+}
+
+// outboxLoop доставка событий outbox в etcd с интервалом из настроек
+// и после каждой записи; недоставленные события повторяются. При
+// выключенном outbox события добавляет только txn, поэтому доставка
+// начинается с первого такого события.
+func (k *keyValueDataService) outboxLoop(ctx context.Context) {
+
+	if !k.outboxConfig.Enabled {
+		select {
+		case <-ctx.Done():
+			return
+		case <-k.outboxNotify:
+		}
+	}
+
+	interval := k.outboxConfig.Interval
+
+	if interval <= 0 {
+		interval = defaultOutboxInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-k.outboxNotify:
+		}
+		for {
+			delivered, err := k.outboxRelay(ctx)
+
+			if err != nil {
+				k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.outboxLoop", "err", err)
+			}
+			if err != nil || delivered < k.outboxBatchSize() {
+				break
+			}
+		}
+	}
+}
+
+// outboxRelay один проход доставки: закреплённые события применяются
+// по порядку, каждый ключ — не более одного раза за проход.
+func (k *keyValueDataService) outboxRelay(ctx context.Context) (int, error) {
+
+	lease := k.outboxConfig.Lease
+
+	if lease <= 0 {
+		lease = defaultOutboxLease
+	}
+	events, err := entity.ClaimOutbox(ctx, k.outboxRepo, int64(k.outboxBatchSize()), lease)
+
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID() < events[j].ID() })
+	applied := make(map[string]error, len(events))
+	delivered := 0
+
+	for i := range events {
+		event := &events[i]
+		err, ok := applied[event.Key()]
+
+		if !ok {
+			err = k.outboxApply(ctx, event.Key())
+			applied[event.Key()] = err
+		}
+		if err != nil {
+			k.sLog.WarnContext(ctx, env.MSG+"keyValueDataService.outboxRelay",
+				"msg", "delivery failed", "id", event.ID(), "key", event.Key(), "attempts", event.Attempts(), "err", err,
+			)
+			if er0 := event.Fail(ctx, k.outboxRepo, err); er0 != nil {
+				k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.outboxRelay", "msg", "mark failed", "err", er0)
+			}
+			continue
+		}
+		if er0 := event.Done(ctx, k.outboxRepo); er0 != nil {
+			k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.outboxRelay", "msg", "mark done", "err", er0)
+			continue
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// outboxApply запись в etcd текущего состояния ключа в PostgreSQL:
+// удалённый или отсутствующий ключ удаляется из etcd. Версия ключа в etcd
+// читается до состояния в PostgreSQL, и запись выполняется, только если
+// ключ в etcd с тех пор не изменился: иначе параллельная доставка того же
+// ключа другим экземпляром прокси могла записать более новое состояние,
+// и событие с ErrVersionMismatch доставляется повторно. Ревизия удаления
+// сопоставляется строке истории удалённой записи PostgreSQL.
+func (k *keyValueDataService) outboxApply(ctx context.Context, key string) error {
+
+	current, _, err := reconcileRead(ctx, k.etcdRepo, key)
+
+	if err != nil {
+		return err
+	}
+	unit, _, err := entity.RowKeyValue(ctx, k.postgresRepo, key)
+
+	if err != nil {
+		return err
+	}
+	var revision int64
+
+	switch {
+	case unit.Key() == key && !unit.Deleted():
+		revision, err = k.compareAndSwapEtcd(ctx, unit, current.Version())
+	case current.Version() == 0:
+		// Ключа нет ни в PostgreSQL, ни в etcd.
+		return nil
+	default:
+		revision, err = k.compareAndDeleteEtcd(ctx, key, current.Version())
+	}
+	if err != nil {
+		return err
+	}
+	k.markRevision(ctx, unit, revision)
+	k.keyInvalidate(ctx, key)
+
+	return nil
+}
+
+func (k *keyValueDataService) outboxBatchSize() int {
+
+	if k.outboxConfig.BatchSize > 0 {
+		return k.outboxConfig.BatchSize
+	}
+	return defaultOutboxBatchSize
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,373 @@
+/*
+ * This file was last modified at 2026-10-18 19:20 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * outbox_test.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestOutbox(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for method put(context.Context, entity.KeyValue, nil) with outbox",
+			positiveOutboxPut,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(bool)) },
+		},
+		{
+			"test #1 positive for method outboxRelay(context.Context)",
+			positiveOutboxRelay,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, 3, i) },
+		},
+		{
+			"test #2 negative for method outboxRelay(context.Context) etcd unavailable",
+			negativeOutboxRelay,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, 0, i) },
+		},
+		{
+			"test #3 positive for method outboxRelay(context.Context) two relays on one key",
+			positiveOutboxRelayConcurrent,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, []interface{}{"value2", 0, 1}, i) },
+		},
+		{
+			"test #4 positive for method txn(context.Context, dto.TxnRequest) etcd unavailable",
+			positiveOutboxTxnEtcdFailed,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(dto.TxnResult).Succeeded) },
+		},
+		{
+			"test #5 positive for method outboxRelay(context.Context) key deleted in postgres",
+			positiveOutboxRelayTombstone,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, 1, i) },
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+// positiveOutboxPut запись не обращается к etcd и будит outboxLoop.
+func positiveOutboxPut(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	unit := MakeKeyValueNow("key1", "value1")
+	mocks.postgres.
+		EXPECT().
+		Do(gomock.Any(), entity.KeyValueUpsertOutbox, unit, gomock.Any()).
+		Return(unit, nil).
+		Times(1)
+
+	if _, err := srv.put(context.Background(), unit, nil); err != nil {
+		return nil, err
+	}
+	select {
+	case <-srv.outboxNotify:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func positiveOutboxRelay(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	unit := MakeKeyValueNow("key1", "value1")
+	mocks.outbox.
+		EXPECT().
+		Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]entity.Outbox{
+			entity.MakeOutbox(2, "key1"),
+			entity.MakeOutbox(1, "key1"),
+			entity.MakeOutbox(3, "key2"),
+		}, nil).
+		Times(1)
+	mocks.postgres.
+		EXPECT().
+		Get(gomock.Any(), entity.KeyValueRow, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ domain.Actioner[*entity.KeyValue, entity.KeyValue], e entity.KeyValue, _ func(domain.Scanner) entity.KeyValue) ([]entity.KeyValue, error) {
+			if e.Key() == "key1" {
+				return []entity.KeyValue{unit}, nil
+			}
+			return []entity.KeyValue{}, nil
+		}).
+		Times(2)
+	mocks.etcd.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key1", 1, false), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{}, nil).
+		Times(1)
+	mocks.etcd.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key2", 1, false), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{entity.MakeKeyValue("key2", "value2", 4, entity.DefaultTAttributes())}, nil).
+		Times(1)
+	mocks.etcd.
+		EXPECT().
+		Do(gomock.Any(), entity.MakeKeyValueCompareAndSwap(0), unit, gomock.Any()).
+		Return(entity.KeyValue{}, nil).
+		Times(1)
+	mocks.etcd.
+		EXPECT().
+		Do(gomock.Any(), entity.MakeKeyValueCompareAndDelete(4), gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, nil).
+		Times(1)
+	mocks.outbox.
+		EXPECT().
+		Do(gomock.Any(), entity.OutboxDone, gomock.Any(), gomock.Any()).
+		Return(entity.Outbox{}, nil).
+		Times(3)
+
+	return srv.outboxRelay(context.Background())
+}
+
+func negativeOutboxRelay(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	errEtcd := fmt.Errorf("etcd unavailable")
+	mocks.outbox.
+		EXPECT().
+		Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]entity.Outbox{entity.MakeOutbox(1, "key1")}, nil).
+		Times(1)
+	mocks.etcd.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key1", 1, false), gomock.Any(), gomock.Any()).
+		Return(nil, errEtcd).
+		Times(1)
+	mocks.outbox.
+		EXPECT().
+		Do(gomock.Any(), entity.MakeOutboxFail(errEtcd), gomock.Any(), gomock.Any()).
+		Return(entity.Outbox{}, nil).
+		Times(1)
+
+	return srv.outboxRelay(context.Background())
+}
+
+// positiveOutboxRelayConcurrent два экземпляра прокси доставляют события
+// одного ключа: пока первый читает из PostgreSQL уже устаревшее состояние,
+// второй доставляет новое. Условная запись первого не проходит, событие
+// отмечается неудачным, а в etcd остаётся новое значение.
+func positiveOutboxRelayConcurrent(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	ctx := context.Background()
+	stale := MakeKeyValueNow("key1", "value1")
+	fresh := MakeKeyValueNow("key1", "value2")
+	var etcdValue string
+	var etcdVersion int64
+	mocks.etcd.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key1", 1, false), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, domain.Actioner[*entity.KeyValue, entity.KeyValue], entity.KeyValue, func(domain.Scanner) entity.KeyValue) ([]entity.KeyValue, error) {
+			if etcdVersion == 0 {
+				return []entity.KeyValue{}, nil
+			}
+			return []entity.KeyValue{entity.MakeKeyValue("key1", etcdValue, etcdVersion, entity.DefaultTAttributes())}, nil
+		}).
+		Times(2)
+	mocks.etcd.
+		EXPECT().
+		Do(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, action domain.Actioner[*entity.KeyValue, entity.KeyValue], unit entity.KeyValue, _ func(domain.Scanner) entity.KeyValue) (entity.KeyValue, error) {
+			if action.(domain.Comparer).ExpectedVersion() != etcdVersion {
+				return unit, domain.ErrVersionMismatch
+			}
+			etcdValue, etcdVersion = unit.Value(), etcdVersion+1
+			return unit, nil
+		}).
+		Times(2)
+	mocks.outbox.
+		EXPECT().
+		Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]entity.Outbox{entity.MakeOutbox(1, "key1")}, nil).
+		Times(1)
+	mocks.outbox.
+		EXPECT().
+		Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]entity.Outbox{entity.MakeOutbox(2, "key1")}, nil).
+		Times(1)
+	var delivered int
+	var err error
+	reads := 0
+	mocks.postgres.
+		EXPECT().
+		Get(gomock.Any(), entity.KeyValueRow, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, domain.Actioner[*entity.KeyValue, entity.KeyValue], entity.KeyValue, func(domain.Scanner) entity.KeyValue) ([]entity.KeyValue, error) {
+			if reads++; reads > 1 {
+				return []entity.KeyValue{fresh}, nil
+			}
+			// Второй экземпляр доставляет событие 2, пока первый
+			// доставляет событие 1.
+			delivered, err = srv.outboxRelay(ctx)
+			return []entity.KeyValue{stale}, nil
+		}).
+		Times(2)
+	mocks.outbox.
+		EXPECT().
+		Do(gomock.Any(), entity.OutboxDone, entity.MakeOutbox(2, "key1"), gomock.Any()).
+		Return(entity.Outbox{}, nil).
+		Times(1)
+	mocks.outbox.
+		EXPECT().
+		Do(gomock.Any(), entity.MakeOutboxFail(domain.ErrVersionMismatch), entity.MakeOutbox(1, "key1"), gomock.Any()).
+		Return(entity.Outbox{}, nil).
+		Times(1)
+
+	first, er0 := srv.outboxRelay(ctx)
+
+	if er0 != nil {
+		return nil, er0
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{etcdValue, first, delivered}, nil
+}
+
+// positiveOutboxTxnEtcdFailed транзакция зафиксирована в PostgreSQL, а
+// неудавшийся повтор в etcd ставится в outbox без ошибки для клиента.
+func positiveOutboxTxnEtcdFailed(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	srv.outboxConfig.Enabled = false
+	mocks.postgres.
+		EXPECT().
+		Transaction(gomock.Any(), entity.KeyValueTransaction, gomock.Any()).
+		Return(true, nil).
+		Times(1)
+	mocks.etcd.
+		EXPECT().
+		Transaction(gomock.Any(), entity.KeyValueTransaction, gomock.Any()).
+		Return(false, fmt.Errorf("etcd unavailable")).
+		Times(1)
+	mocks.outbox.
+		EXPECT().
+		Do(gomock.Any(), entity.OutboxEnqueue, entity.MakeOutbox(0, "key1"), gomock.Any()).
+		Return(entity.Outbox{}, nil).
+		Times(1)
+	mocks.outbox.
+		EXPECT().
+		Do(gomock.Any(), entity.OutboxEnqueue, entity.MakeOutbox(0, "key2"), gomock.Any()).
+		Return(entity.Outbox{}, nil).
+		Times(1)
+
+	result, err := srv.txn(context.Background(), dto.TxnRequest{
+		Compare: []dto.TxnCompare{{Key: "key1", Target: "version", Result: "equal", Version: 1}},
+		Success: []dto.TxnOp{{Type: "put", Key: "key1", Value: "value2"}, {Type: "delete", Key: "key2"}},
+	})
+	if err == nil && len(srv.outboxNotify) != 1 {
+		err = fmt.Errorf("outboxLoop is not woken")
+	}
+	return result, err
+}
+
+// positiveOutboxRelayTombstone удалённая в PostgreSQL запись удаляется из
+// etcd, и ревизия удаления отмечается на строке истории удалённой записи.
+func positiveOutboxRelayTombstone(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	tombstone := entity.MakeKeyValue("key1", "value1", 2, entity.MakeTAttributes(
+		sql.NullBool{Bool: true, Valid: true}, time.Now(), sql.NullTime{},
+	))
+	mocks.outbox.
+		EXPECT().
+		Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]entity.Outbox{entity.MakeOutbox(1, "key1")}, nil).
+		Times(1)
+	mocks.etcd.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key1", 1, false), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{entity.MakeKeyValue("key1", "value1", 4, entity.DefaultTAttributes())}, nil).
+		Times(1)
+	mocks.postgres.
+		EXPECT().
+		Get(gomock.Any(), entity.KeyValueRow, gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{tombstone}, nil).
+		Times(1)
+	mocks.etcd.
+		EXPECT().
+		Do(gomock.Any(), entity.MakeKeyValueCompareAndDelete(4), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ domain.Actioner[*entity.KeyValue, entity.KeyValue],
+			unit entity.KeyValue,
+			scan func(domain.Scanner) entity.KeyValue,
+		) (entity.KeyValue, error) {
+			return scan(revisionTestScanner{revision: 9}), nil
+		}).
+		Times(1)
+	mocks.postgres.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueRevisionMark(9), tombstone, gomock.Any()).
+		Return([]entity.KeyValue{tombstone}, nil).
+		Times(1)
+	mocks.outbox.
+		EXPECT().
+		Do(gomock.Any(), entity.OutboxDone, gomock.Any(), gomock.Any()).
+		Return(entity.Outbox{}, nil).
+		Times(1)
+
+	return srv.outboxRelay(context.Background())
+}
+
+type outboxTestMocks struct {
+	etcd     *MockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
+	outbox   *MockRepo[domain.Actioner[*entity.Outbox, entity.Outbox], *entity.Outbox, entity.Outbox]
+	postgres *MockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
+}
+
+func newTestKeyValueDataServiceWithOutbox(t *testing.T) (*keyValueDataService, outboxTestMocks) {
+
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+
+	ctrl := gomock.NewController(t)
+	mocks := outboxTestMocks{
+		etcd:     NewMockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](ctrl),
+		outbox:   NewMockRepo[domain.Actioner[*entity.Outbox, entity.Outbox], *entity.Outbox, entity.Outbox](ctrl),
+		postgres: NewMockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](ctrl),
+	}
+	etcdPoolMock := NewMockEtcdPool(ctrl)
+	etcdPoolMock.
+		EXPECT().
+		AcquireClient(gomock.Any()).
+		Return(&kvTestStub{}, nil).
+		AnyTimes()
+	etcdPoolMock.
+		EXPECT().
+		ReleaseClient(gomock.Any()).
+		Return(nil).
+		AnyTimes()
+	srv := newTestKeyValueDataService(env.GetConfig(), mocks.etcd, etcdPoolMock, mocks.postgres)
+	srv.outboxConfig = env.OutboxConfig{BatchSize: 10, Enabled: true}
+	srv.outboxNotify = make(chan struct{}, 1)
+	srv.outboxRepo = mocks.outbox
+
+	return srv, mocks
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
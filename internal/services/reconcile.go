@@ -0,0 +1,256 @@
+/*
+ * This file was last modified at 2026-10-18 18:50 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * reconcile.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"sort"
+	"strings"
+	"time"
+
+	clientV3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	SourceOfTruthEtcd     = "etcd"
+	SourceOfTruthPostgres = "postgres"
+
+	defaultReconcileInterval = 5 * time.Minute
+)
+
+var (
+	ErrReconcileInProgress = fmt.Errorf("reconcile already in progress")
+	ErrReconcileNoPrefix   = fmt.Errorf("reconcile repair requires prefix")
+)
+
+type kvRepo = domain.Repo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue]
+
+// reconcile сверка (anti-entropy) записей etcd и PostgreSQL: обход обоих
+// хранилищ, поиск ключей отсутствующих в одном из них и различающихся
+// значений. Без dryRun расхождения исправляются по хранилищу sourceOfTruth,
+// для чего должен быть задан префикс сверки.
+func (k *keyValueDataService) reconcile(ctx context.Context, dryRun bool) (dto.ReconcileReport, error) {
+
+	if !dryRun && k.reconcileConfig.Prefix == "" {
+		return dto.ReconcileReport{}, ErrReconcileNoPrefix
+	}
+	if !k.reconcileMu.TryLock() {
+		return dto.ReconcileReport{}, ErrReconcileInProgress
+	}
+	defer k.reconcileMu.Unlock()
+
+	report := dto.ReconcileReport{
+		DryRun:            dryRun,
+		SourceOfTruth:     k.sourceOfTruth(),
+		MissingInEtcd:     make([]string, 0),
+		MissingInPostgres: make([]string, 0),
+		ValueMismatch:     make([]string, 0),
+	}
+	postgres, err := k.reconcileSnapshot(ctx, k.postgresRepo)
+
+	if err != nil {
+		return report, fmt.Errorf("postgres snapshot: %w", err)
+	}
+	etcd, err := k.reconcileSnapshot(ctx, k.etcdRepo)
+
+	if err != nil {
+		return report, fmt.Errorf("etcd snapshot: %w", err)
+	}
+	for key, value := range postgres {
+		if other, ok := etcd[key]; !ok {
+			report.MissingInEtcd = append(report.MissingInEtcd, key)
+		} else if other != value {
+			report.ValueMismatch = append(report.ValueMismatch, key)
+		}
+	}
+	for key := range etcd {
+		if _, ok := postgres[key]; !ok {
+			report.MissingInPostgres = append(report.MissingInPostgres, key)
+		}
+	}
+	report.Checked = len(postgres) + len(report.MissingInPostgres)
+	sort.Strings(report.MissingInEtcd)
+	sort.Strings(report.MissingInPostgres)
+	sort.Strings(report.ValueMismatch)
+
+	if dryRun {
+		return report, nil
+	}
+	for _, keys := range [][]string{report.MissingInEtcd, report.MissingInPostgres, report.ValueMismatch} {
+		for _, key := range keys {
+			repaired, err := k.reconcileKey(ctx, key)
+
+			if err != nil {
+				k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.reconcile", "key", key, "err", err)
+				report.Failed = append(report.Failed, key)
+			} else if repaired {
+				report.Repaired++
+			}
+		}
+	}
+	return report, nil
+}
+
+// reconcileSnapshot живые записи хранилища: ключ — значение.
+func (k *keyValueDataService) reconcileSnapshot(ctx context.Context, repo kvRepo) (map[string]string, error) {
+
+	units, err := entity.GetAllKeyValue(ctx, repo)
+
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(units))
+
+	for _, unit := range units {
+		if unit.Deleted() || unit.Key() == CacheInvalidate || !strings.HasPrefix(unit.Key(), k.reconcileConfig.Prefix) {
+			continue
+		}
+		result[unit.Key()] = unit.Value()
+	}
+	return result, nil
+}
+
+// reconcileKey исправление расхождения по ключу. Оба хранилища
+// перечитываются непосредственно перед исправлением, чтобы не затереть
+// запись изменённую после снимка; совпадающие записи и ключи etcd
+// с арендой не изменяются. При источнике истины PostgreSQL ключ etcd
+// удаляется, только если в PostgreSQL он помечен удалённым: ключ без
+// записи в PostgreSQL мог быть записан через etcd-proxy и только
+// отражается в отчёте (MissingInPostgres).
+func (k *keyValueDataService) reconcileKey(ctx context.Context, key string) (bool, error) {
+
+	// По умолчанию источник истины PostgreSQL, исправляется etcd.
+	toEtcd := k.sourceOfTruth() == SourceOfTruthPostgres
+	source, target := k.postgresRepo, k.etcdRepo
+
+	if !toEtcd {
+		source, target = k.etcdRepo, k.postgresRepo
+	}
+	want, wantFound, err := reconcileRead(ctx, source, key)
+
+	if err != nil {
+		return false, err
+	}
+	got, gotFound, err := reconcileRead(ctx, target, key)
+
+	if err != nil {
+		return false, err
+	}
+	if wantFound == gotFound && want.Value() == got.Value() {
+		return false, nil
+	}
+	// Ключи с арендой пишутся в etcd в обход PostgreSQL (etcd-proxy).
+	if leased, err := k.etcdLeased(ctx, key); err != nil || leased {
+		return false, err
+	}
+	var revision int64
+	written := want
+
+	switch {
+	case toEtcd && wantFound:
+		revision, err = k.putEtcd(ctx, want)
+	case toEtcd:
+		var tombstone bool
+
+		if written, tombstone, err = entity.RowKeyValue(ctx, k.postgresRepo, key); err != nil || !tombstone {
+			return false, err
+		}
+		// Ревизия удаления сопоставляется удалённой записи PostgreSQL.
+		revision, err = k.deleteEtcd(ctx, key)
+	case wantFound:
+		unit := MakeKeyValueNow(key, want.Value())
+		err = unit.Upsert(ctx, k.postgresRepo)
+	default:
+		unit := MakeKeyValueNow(key, "")
+		err = unit.Delete(ctx, k.postgresRepo)
+	}
+	if err != nil {
+		return false, err
+	}
+	k.markRevision(ctx, written, revision)
+	k.keyInvalidate(ctx, key)
+
+	return true, nil
+}
+
+// reconcileLoop фоновая сверка с интервалом из настроек, при выключенном
+// reconcile.repair расхождения только записываются в журнал.
+func (k *keyValueDataService) reconcileLoop(ctx context.Context) {
+
+	interval := k.reconcileConfig.Interval
+
+	if interval <= 0 {
+		interval = defaultReconcileInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := k.reconcile(ctx, !k.reconcileConfig.Repair)
+
+			if err != nil {
+				k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.reconcileLoop", "err", err)
+			} else if report.Drift() > 0 {
+				k.sLog.WarnContext(ctx, env.MSG+"keyValueDataService.reconcileLoop", "msg", "drift", "report", report)
+			}
+		}
+	}
+}
+
+func (k *keyValueDataService) sourceOfTruth() string {
+
+	if k.reconcileConfig.SourceOfTruth == SourceOfTruthEtcd {
+		return SourceOfTruthEtcd
+	}
+	return SourceOfTruthPostgres
+}
+
+// etcdLeased ключ etcd привязан к аренде.
+func (k *keyValueDataService) etcdLeased(ctx context.Context, key string) (bool, error) {
+
+	client, err := k.pool.AcquireClient(ctx)
+
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = k.pool.ReleaseClient(client) }()
+	resp, err := client.Get(ctx, key, clientV3.WithKeysOnly())
+
+	if err != nil {
+		return false, err
+	}
+	return len(resp.Kvs) > 0 && resp.Kvs[0].Lease != 0, nil
+}
+
+// reconcileRead текущая живая запись ключа, found = false — ключ отсутствует.
+func reconcileRead(ctx context.Context, repo kvRepo, key string) (unit entity.KeyValue, found bool, err error) {
+
+	got, err := entity.ListKeyValue(ctx, repo, key, key, 1, false)
+
+	if err != nil {
+		return unit, false, err
+	}
+	if len(got) > 0 && got[0].Key() == key && !got[0].Deleted() {
+		return got[0], true, nil
+	}
+	return unit, false, nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
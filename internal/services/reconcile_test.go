@@ -0,0 +1,348 @@
+/*
+ * This file was last modified at 2026-10-18 18:50 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * reconcile_test.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestReconcile(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for method reconcile(context.Context, true) dry-run report",
+			positiveReconcileDryRun,
+			positiveReconcileDryRunCheck,
+		},
+		{
+			"test #1 positive for method reconcile(context.Context, false) repair etcd from postgres",
+			positiveReconcileRepair,
+			positiveReconcileRepairCheck,
+		},
+		{
+			"test #2 negative for method reconcile(context.Context, bool) already in progress",
+			negativeReconcileInProgress,
+			negativeReconcileInProgressCheck,
+		},
+		{
+			"test #3 negative for method reconcile(context.Context, false) repair without prefix",
+			negativeReconcileNoPrefix,
+			func(t *testing.T, i interface{}) bool { return assert.ErrorIs(t, i.(error), ErrReconcileNoPrefix) },
+		},
+		{
+			"test #4 positive for method reconcile(context.Context, false) leased etcd key is kept",
+			positiveReconcileLeased,
+			func(t *testing.T, i interface{}) bool {
+				report, ok := i.(dto.ReconcileReport)
+				return assert.True(t, ok) &&
+					assert.Equal(t, []string{"key4"}, report.MissingInPostgres) &&
+					assert.Equal(t, 0, report.Repaired) &&
+					assert.Empty(t, report.Failed)
+			},
+		},
+		{
+			"test #5 positive for method reconcile(context.Context, false) etcd-only proxy key is kept",
+			positiveReconcileProxyKey,
+			func(t *testing.T, i interface{}) bool {
+				report, ok := i.(dto.ReconcileReport)
+				return assert.True(t, ok) &&
+					assert.Equal(t, []string{"key6"}, report.MissingInPostgres) &&
+					assert.Equal(t, 0, report.Repaired) &&
+					assert.Empty(t, report.Failed)
+			},
+		},
+		{
+			"test #6 positive for method reconcile(context.Context, false) key deleted in postgres",
+			positiveReconcileTombstone,
+			func(t *testing.T, i interface{}) bool {
+				report, ok := i.(dto.ReconcileReport)
+				return assert.True(t, ok) &&
+					assert.Equal(t, []string{"key3"}, report.MissingInPostgres) &&
+					assert.Equal(t, 1, report.Repaired) &&
+					assert.Empty(t, report.Failed)
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveReconcileDryRun(t *testing.T) (interface{}, error) {
+
+	deleted := entity.MakeKeyValue("key3", "value3", 2, entity.MakeTAttributes(
+		sql.NullBool{Bool: true, Valid: true}, time.Now(), sql.NullTime{},
+	))
+	srv := newTestKeyValueDataServiceWithSnapshots(t,
+		[]entity.KeyValue{
+			MakeKeyValueNow("key1", "value1"),
+			MakeKeyValueNow("key2", "value2"),
+			deleted,
+			MakeKeyValueNow("key5", "value5"),
+		},
+		[]entity.KeyValue{
+			entity.MakeKeyValue("key1", "value1", 1, entity.DefaultTAttributes()),
+			entity.MakeKeyValue("key2", "other", 1, entity.DefaultTAttributes()),
+			entity.MakeKeyValue("key4", "value4", 1, entity.DefaultTAttributes()),
+			entity.MakeKeyValue(CacheInvalidate, "key1", 1, entity.DefaultTAttributes()),
+		},
+	)
+	return srv.reconcile(context.Background(), true)
+}
+
+func positiveReconcileDryRunCheck(t *testing.T, i interface{}) bool {
+	return assert.Equal(t, dto.ReconcileReport{
+		DryRun:            true,
+		SourceOfTruth:     SourceOfTruthPostgres,
+		Checked:           4,
+		MissingInEtcd:     []string{"key5"},
+		MissingInPostgres: []string{"key4"},
+		ValueMismatch:     []string{"key2"},
+	}, i)
+}
+
+func positiveReconcileRepair(t *testing.T) (interface{}, error) {
+
+	unit := MakeKeyValueNow("key5", "value5")
+	srv := newTestKeyValueDataServiceWithSnapshots(t, []entity.KeyValue{unit}, []entity.KeyValue{})
+	srv.reconcileConfig.Prefix = "key"
+	postgresRepo := srv.postgresRepo.(*MockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue])
+	etcdRepo := srv.etcdRepo.(*MockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue])
+	postgresRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key5", 1, false), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{unit}, nil).
+		Times(1)
+	etcdRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key5", 1, false), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{}, nil).
+		Times(1)
+	etcdRepo.
+		EXPECT().
+		Do(gomock.Any(), entity.KeyValueUpsert, unit, gomock.Any()).
+		Return(entity.KeyValue{}, nil).
+		Times(1)
+
+	return srv.reconcile(context.Background(), false)
+}
+
+func positiveReconcileRepairCheck(t *testing.T, i interface{}) bool {
+
+	report, ok := i.(dto.ReconcileReport)
+
+	return assert.True(t, ok) &&
+		assert.False(t, report.DryRun) &&
+		assert.Equal(t, []string{"key5"}, report.MissingInEtcd) &&
+		assert.Equal(t, 1, report.Repaired) &&
+		assert.Empty(t, report.Failed)
+}
+
+func negativeReconcileInProgress(t *testing.T) (interface{}, error) {
+
+	srv := newTestKeyValueDataService(reconcileTestConfig(t), nil, nil, nil)
+	srv.reconcileMu.Lock()
+	defer srv.reconcileMu.Unlock()
+	_, err := srv.reconcile(context.Background(), true)
+
+	return err, nil
+}
+
+func negativeReconcileInProgressCheck(t *testing.T, i interface{}) bool {
+	return assert.ErrorIs(t, i.(error), ErrReconcileInProgress)
+}
+
+func negativeReconcileNoPrefix(t *testing.T) (interface{}, error) {
+
+	srv := newTestKeyValueDataService(reconcileTestConfig(t), nil, nil, nil)
+	_, err := srv.reconcile(context.Background(), false)
+
+	return err, nil
+}
+
+// positiveReconcileLeased ключ с арендой есть только в etcd: при источнике
+// истины PostgreSQL он не удаляется.
+func positiveReconcileLeased(t *testing.T) (interface{}, error) {
+
+	leased := entity.MakeKeyValue("key4", "value4", 1, entity.DefaultTAttributes())
+	srv := newTestKeyValueDataServiceWithSnapshots(t, []entity.KeyValue{}, []entity.KeyValue{leased})
+	srv.reconcileConfig.Prefix = "key"
+	postgresRepo := srv.postgresRepo.(*MockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue])
+	etcdRepo := srv.etcdRepo.(*MockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue])
+	postgresRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key4", 1, false), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{}, nil).
+		Times(1)
+	etcdRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key4", 1, false), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{leased}, nil).
+		Times(1)
+	etcdPoolMock := NewMockEtcdPool(gomock.NewController(t))
+	etcdPoolMock.
+		EXPECT().
+		AcquireClient(gomock.Any()).
+		Return(&kvTestStub{lease: 7}, nil).
+		Times(1)
+	etcdPoolMock.
+		EXPECT().
+		ReleaseClient(gomock.Any()).
+		Return(nil).
+		Times(1)
+	srv.pool = etcdPoolMock
+
+	return srv.reconcile(context.Background(), false)
+}
+
+// positiveReconcileProxyKey ключ записан в etcd через etcd-proxy без аренды
+// и в PostgreSQL записи не имеет: исправление его не удаляет.
+func positiveReconcileProxyKey(t *testing.T) (interface{}, error) {
+
+	proxied := entity.MakeKeyValue("key6", "value6", 1, entity.DefaultTAttributes())
+	srv := newTestKeyValueDataServiceWithSnapshots(t, []entity.KeyValue{}, []entity.KeyValue{proxied})
+	srv.reconcileConfig.Prefix = "key"
+	postgresRepo := srv.postgresRepo.(*MockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue])
+	etcdRepo := srv.etcdRepo.(*MockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue])
+	postgresRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key6", 1, false), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{}, nil).
+		Times(1)
+	postgresRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.KeyValueRow, gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{}, nil).
+		Times(1)
+	etcdRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key6", 1, false), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{proxied}, nil).
+		Times(1)
+
+	return srv.reconcile(context.Background(), false)
+}
+
+// positiveReconcileTombstone ключ помечен удалённым в PostgreSQL: удаление
+// из etcd сопоставляется строке истории удалённой записи.
+func positiveReconcileTombstone(t *testing.T) (interface{}, error) {
+
+	live := entity.MakeKeyValue("key3", "value3", 1, entity.DefaultTAttributes())
+	tombstone := entity.MakeKeyValue("key3", "value3", 2, entity.MakeTAttributes(
+		sql.NullBool{Bool: true, Valid: true}, time.Now(), sql.NullTime{},
+	))
+	srv := newTestKeyValueDataServiceWithSnapshots(t, []entity.KeyValue{tombstone}, []entity.KeyValue{live})
+	srv.reconcileConfig.Prefix = "key"
+	postgresRepo := srv.postgresRepo.(*MockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue])
+	etcdRepo := srv.etcdRepo.(*MockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue])
+	postgresRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key3", 1, false), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{}, nil).
+		Times(1)
+	postgresRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.KeyValueRow, gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{tombstone}, nil).
+		Times(1)
+	postgresRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueRevisionMark(9), tombstone, gomock.Any()).
+		Return([]entity.KeyValue{tombstone}, nil).
+		Times(1)
+	etcdRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueList("key3", 1, false), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{live}, nil).
+		Times(1)
+	etcdRepo.
+		EXPECT().
+		Do(gomock.Any(), entity.KeyValueDelete, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ domain.Actioner[*entity.KeyValue, entity.KeyValue],
+			unit entity.KeyValue,
+			scan func(domain.Scanner) entity.KeyValue,
+		) (entity.KeyValue, error) {
+			return scan(revisionTestScanner{revision: 9}), nil
+		}).
+		Times(1)
+
+	return srv.reconcile(context.Background(), false)
+}
+
+// revisionTestScanner ответ etcd с ревизией записи revision.
+type revisionTestScanner struct {
+	revision int64
+}
+
+func (s revisionTestScanner) Revision() int64 {
+	return s.revision
+}
+
+func (s revisionTestScanner) Scan(_ ...any) error {
+	return nil
+}
+
+func newTestKeyValueDataServiceWithSnapshots(t *testing.T, postgres, etcd []entity.KeyValue) *keyValueDataService {
+
+	ctrl := gomock.NewController(t)
+	etcdRepo := NewMockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](ctrl)
+	etcdPoolMock := NewMockEtcdPool(ctrl)
+	postgresRepo := NewMockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](ctrl)
+	postgresRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.KeyValueGetAll, gomock.Any(), gomock.Any()).
+		Return(postgres, nil).
+		Times(1)
+	etcdRepo.
+		EXPECT().
+		Get(gomock.Any(), entity.KeyValueGetAll, gomock.Any(), gomock.Any()).
+		Return(etcd, nil).
+		Times(1)
+	etcdPoolMock.
+		EXPECT().
+		AcquireClient(gomock.Any()).
+		Return(&kvTestStub{}, nil).
+		AnyTimes()
+	etcdPoolMock.
+		EXPECT().
+		ReleaseClient(gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	return newTestKeyValueDataService(reconcileTestConfig(t), etcdRepo, etcdPoolMock, postgresRepo)
+}
+
+func reconcileTestConfig(t *testing.T) env.Config {
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+
+	return env.GetConfig()
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -69,3 +69,18 @@ func (mr *MockRepoMockRecorder[A, T, U]) Get(ctx, action, unit, scan any) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepo[A, T, U])(nil).Get), ctx, action, unit, scan)
 }
+
+// Transaction mocks base method.
+func (m *MockRepo[A, T, U]) Transaction(ctx context.Context, action domain.TransactionalAction, txn domain.Txn[U]) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", ctx, action, txn)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockRepoMockRecorder[A, T, U]) Transaction(ctx, action, txn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockRepo[A, T, U])(nil).Transaction), ctx, action, txn)
+}
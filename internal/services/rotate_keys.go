@@ -0,0 +1,230 @@
+/*
+ * This file was last modified at 2026-10-18 23:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * rotate_keys.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/repo"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/envelope"
+	"github.com/victor-skurikhin/etcd-client/v1/pool/etcd_pool"
+
+	clientV3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	rotateBatchSize    int64 = 100
+	rotateStoreHistory       = "postgres_history"
+)
+
+var ErrEncryptionDisabled = fmt.Errorf("encryption disabled")
+
+// RotateResult итог перешифрования значений в хранилище Store: Rotated —
+// перешифровано, Skipped — изменено параллельно и уже зашифровано текущим ключом.
+type RotateResult struct {
+	Rotated int
+	Skipped int
+	Store   string
+}
+
+// RotateKeys перешифрование текущим мастер-ключом значений ключей
+// из настроенных префиксов, зашифрованных предыдущими ключами или
+// записанных до включения шифрования, сначала в PostgreSQL, затем в etcd.
+// В PostgreSQL значение заменяется на месте без смены версии, если запись
+// не изменилась параллельно; затем перешифровываются значения в истории
+// и удалённых записях, после чего предыдущий ключ можно удалить из
+// настроек. В etcd запись заменяется только при неизменной версии, аренда
+// ключа сохраняется, но версия ключа в etcd увеличивается.
+func RotateKeys(ctx context.Context, cfg env.Config) ([]RotateResult, error) {
+
+	config := cfg.EncryptionConfig()
+
+	if !config.Enabled {
+		return nil, ErrEncryptionDisabled
+	}
+	e := envelope.New(config)
+	results := make([]RotateResult, 0, 3)
+
+	if cfg.DBPool() != nil {
+		kv := repo.GetKeyValuePostgresRepo(cfg)
+		result, err := rotateKeysRepo(ctx, e, kv, config.Prefixes)
+		result.Store = SourceOfTruthPostgres
+		results = append(results, result)
+
+		if err != nil {
+			return results, err
+		}
+		result, err = rotateKeysStored(ctx, e, kv, config.Prefixes)
+		result.Store = rotateStoreHistory
+		results = append(results, result)
+
+		if err != nil {
+			return results, err
+		}
+	}
+	etcdPool := etcd_pool.GetPool(cfg)
+	client, err := etcdPool.AcquireClient(ctx)
+
+	if err != nil {
+		return results, err
+	}
+	defer func() { _ = etcdPool.ReleaseClient(client) }()
+	result, err := rotateKeysEtcd(ctx, e, client, config.Prefixes)
+	result.Store = SourceOfTruthEtcd
+
+	return append(results, result), err
+}
+
+// rotateKeysRepo перешифрование в репозитории kv: записи читаются без
+// расшифровки, и хранимое значение заменяется на месте, так что версия,
+// по которой клиенты выполняют условную запись, не меняется.
+func rotateKeysRepo(ctx context.Context, e *envelope.Envelope, kv kvRepo, prefixes []string) (RotateResult, error) {
+
+	var result RotateResult
+	raw := repo.Unencrypted(kv)
+
+	for _, prefix := range prefixes {
+		for from, more := "", true; more; {
+			units, err := entity.ListKeyValue(ctx, raw, prefix, from, rotateBatchSize+1, false)
+
+			if err != nil {
+				return result, err
+			}
+			if more = int64(len(units)) > rotateBatchSize; more {
+				from = units[rotateBatchSize].Key()
+				units = units[:rotateBatchSize]
+			}
+			for _, unit := range units {
+				if !e.Stale(unit.Key(), unit.Value()) {
+					continue
+				}
+				value, err := e.Decrypt(unit.Key(), unit.Value())
+
+				if err != nil {
+					return result, err
+				}
+				if value, err = e.Encrypt(unit.Key(), value); err != nil {
+					return result, err
+				}
+				rows, err := entity.RewriteKeyValue(ctx, raw, unit.WithValue(value), unit.Value())
+
+				if err != nil {
+					return result, err
+				}
+				if len(rows) > 0 {
+					result.Rotated++
+				} else {
+					result.Skipped++
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// rotateKeysStored перешифрование значений истории и удалённых записей:
+// каждое устаревшее хранимое значение ключа заменяется во всех строках
+// сразу, версии не меняются. Строки, изменённые параллельно, не считаются.
+func rotateKeysStored(ctx context.Context, e *envelope.Envelope, kv kvRepo, prefixes []string) (RotateResult, error) {
+
+	var result RotateResult
+	raw := repo.Unencrypted(kv)
+
+	for _, prefix := range prefixes {
+		for fromKey, fromValue, more := "", "", true; more; {
+			units, err := entity.StoredKeyValue(ctx, raw, prefix, fromKey, fromValue, rotateBatchSize)
+
+			if err != nil {
+				return result, err
+			}
+			if more = int64(len(units)) == rotateBatchSize; more {
+				fromKey, fromValue = units[len(units)-1].Key(), units[len(units)-1].Value()
+			}
+			for _, unit := range units {
+				if !e.Stale(unit.Key(), unit.Value()) {
+					continue
+				}
+				value, err := e.Decrypt(unit.Key(), unit.Value())
+
+				if err != nil {
+					return result, err
+				}
+				if value, err = e.Encrypt(unit.Key(), value); err != nil {
+					return result, err
+				}
+				rows, err := entity.RewriteKeyValue(ctx, raw, unit.WithValue(value), unit.Value())
+
+				if err != nil {
+					return result, err
+				}
+				if len(rows) > 0 {
+					result.Rotated += len(rows)
+				} else {
+					result.Skipped++
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// rotateKeysEtcd перешифрование в etcd напрямую через клиента, чтобы
+// сохранить аренду ключа (clientV3.WithIgnoreLease).
+func rotateKeysEtcd(ctx context.Context, e *envelope.Envelope, client clientV3.KV, prefixes []string) (RotateResult, error) {
+
+	var result RotateResult
+
+	for _, prefix := range prefixes {
+		end := clientV3.GetPrefixRangeEnd(prefix)
+
+		for from, more := prefix, true; more; {
+			resp, err := client.Get(ctx, from, clientV3.WithRange(end), clientV3.WithLimit(rotateBatchSize))
+
+			if err != nil {
+				return result, err
+			}
+			for _, kv := range resp.Kvs {
+				key, value := string(kv.Key), string(kv.Value)
+
+				if !e.Stale(key, value) {
+					continue
+				}
+				if value, err = e.Decrypt(key, value); err != nil {
+					return result, err
+				}
+				if value, err = e.Encrypt(key, value); err != nil {
+					return result, err
+				}
+				txn, err := client.Txn(ctx).
+					If(clientV3.Compare(clientV3.Version(key), "=", kv.Version)).
+					Then(clientV3.OpPut(key, value, clientV3.WithIgnoreLease())).
+					Commit()
+
+				if err != nil {
+					return result, err
+				}
+				if txn.Succeeded {
+					result.Rotated++
+				} else {
+					result.Skipped++
+				}
+			}
+			if more = resp.More && len(resp.Kvs) > 0; more {
+				from = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+			}
+		}
+	}
+	return result, nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,244 @@
+/*
+ * This file was last modified at 2026-10-18 23:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * rotate_keys_test.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/repo"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/envelope"
+	"github.com/victor-skurikhin/etcd-client/v1/tool"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestRotateKeys(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for function rotateKeysRepo(...)",
+			positiveRotateKeysRepo,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, RotateResult{Rotated: 1}, i)
+			},
+		},
+		{
+			"test #1 positive for function rotateKeysRepo(...) changed concurrently",
+			positiveRotateKeysRepoSkipped,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, RotateResult{Skipped: 1}, i)
+			},
+		},
+		{
+			"test #2 negative for function RotateKeys(...) encryption disabled",
+			negativeRotateKeysDisabled,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, i.(bool)) },
+		},
+		{
+			"test #3 positive for function rotateKeysStored(...) history and undelete after previous key retired",
+			positiveRotateKeysStored,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []interface{}{RotateResult{Rotated: 2}, "plain", "plain"}, i)
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveRotateKeysRepo(t *testing.T) (interface{}, error) {
+
+	e, mock, err := newTestRotateKeysRepo(t)
+
+	if err != nil {
+		return nil, err
+	}
+	// Значение заменяется на месте, без условной записи по версии.
+	mock.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueRewrite("plain"), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ domain.Actioner[*entity.KeyValue, entity.KeyValue],
+			unit entity.KeyValue,
+			_ func(domain.Scanner) entity.KeyValue,
+		) ([]entity.KeyValue, error) {
+			value, err := e.Decrypt(unit.Key(), unit.Value())
+			assert.True(t, envelope.IsEncrypted(unit.Value()))
+			assert.Nil(t, err)
+			assert.Equal(t, "plain", value)
+			return []entity.KeyValue{unit}, nil
+		})
+	return rotateKeysRepo(context.Background(), e, repo.NewEncrypted(mock, e), []string{"/secrets/"})
+}
+
+func positiveRotateKeysRepoSkipped(t *testing.T) (interface{}, error) {
+
+	e, mock, err := newTestRotateKeysRepo(t)
+
+	if err != nil {
+		return nil, err
+	}
+	mock.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueRewrite("plain"), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{}, nil)
+
+	return rotateKeysRepo(context.Background(), e, repo.NewEncrypted(mock, e), []string{"/secrets/"})
+}
+
+func negativeRotateKeysDisabled(t *testing.T) (interface{}, error) {
+
+	t.Setenv("GO_FAVORITES_SKIP_LOAD_CONFIG", "True")
+	t.Setenv("DATABASE_DSN", "")
+	_, err := RotateKeys(context.Background(), env.GetConfig())
+
+	return errors.Is(err, ErrEncryptionDisabled), nil
+}
+
+func positiveRotateKeysStored(t *testing.T) (interface{}, error) {
+
+	previous := tool.LoadPrivateKey("../../tool/test_private-key.pem")
+	current, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		return nil, err
+	}
+	stored, err := envelope.New(newTestEncryptionConfig(previous)).Encrypt("/secrets/a", "plain")
+
+	if err != nil {
+		return nil, err
+	}
+	rewritten := stored
+	mock := NewMockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](gomock.NewController(t))
+	mock.
+		EXPECT().
+		Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			action domain.Actioner[*entity.KeyValue, entity.KeyValue],
+			unit entity.KeyValue,
+			scan func(domain.Scanner) entity.KeyValue,
+		) ([]entity.KeyValue, error) {
+			switch action.Name() {
+			case domain.StoredAction:
+				return []entity.KeyValue{entity.MakeKeyValue("/secrets/a", stored, 1, entity.TAttributes{})}, nil
+			case domain.RewriteAction:
+				rewritten = unit.Value()
+				return []entity.KeyValue{unit, unit}, nil
+			}
+			return []entity.KeyValue{scan(rotateTestScanner{key: "/secrets/a", value: rewritten})}, nil
+		}).
+		AnyTimes()
+	mock.
+		EXPECT().
+		Do(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ domain.Actioner[*entity.KeyValue, entity.KeyValue],
+			_ entity.KeyValue,
+			scan func(domain.Scanner) entity.KeyValue,
+		) (entity.KeyValue, error) {
+			return scan(rotateTestScanner{key: "/secrets/a", value: rewritten}), nil
+		})
+	result, err := rotateKeysStored(
+		context.Background(),
+		envelope.New(newTestEncryptionConfig(current, previous)),
+		mock,
+		[]string{"/secrets/"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	// Предыдущий ключ удалён из настроек.
+	retired := repo.NewEncrypted(mock, envelope.New(newTestEncryptionConfig(current)))
+	history, err := entity.HistoryKeyValue(context.Background(), retired, "/secrets/a", 10)
+
+	if err != nil {
+		return nil, err
+	}
+	unit := MakeKeyValueNow("/secrets/a", "")
+
+	if err = unit.Undelete(context.Background(), retired); err != nil {
+		return nil, err
+	}
+	return []interface{}{result, history[0].Value(), unit.Value()}, nil
+}
+
+// newTestRotateKeysRepo репозиторий с двумя записями: открытым значением,
+// которое надо зашифровать, и значением, уже зашифрованным текущим ключом.
+func newTestRotateKeysRepo(t *testing.T) (*envelope.Envelope, *MockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue], error) {
+
+	key := tool.LoadPrivateKey("../../tool/test_private-key.pem")
+	e := envelope.New(env.EncryptionConfig{
+		Enabled:     true,
+		Prefixes:    []string{"/secrets/"},
+		PrivateKeys: []*rsa.PrivateKey{key},
+		PublicKey:   &key.PublicKey,
+	})
+	encrypted, err := e.Encrypt("/secrets/b", "current")
+
+	if err != nil {
+		return nil, nil, err
+	}
+	mock := NewMockRepo[domain.Actioner[*entity.KeyValue, entity.KeyValue], *entity.KeyValue, entity.KeyValue](gomock.NewController(t))
+	mock.
+		EXPECT().
+		Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{
+			entity.MakeKeyValue("/secrets/a", "plain", 1, entity.TAttributes{}),
+			entity.MakeKeyValue("/secrets/b", encrypted, 1, entity.TAttributes{}),
+		}, nil)
+
+	return e, mock, nil
+}
+
+func newTestEncryptionConfig(keys ...*rsa.PrivateKey) env.EncryptionConfig {
+	return env.EncryptionConfig{
+		Enabled:     true,
+		Prefixes:    []string{"/secrets/"},
+		PrivateKeys: keys,
+		PublicKey:   &keys[0].PublicKey,
+	}
+}
+
+// rotateTestScanner строка (key, value, ...) в том виде, в каком хранится.
+type rotateTestScanner struct {
+	key   string
+	value string
+}
+
+func (s rotateTestScanner) Scan(dest ...any) error {
+
+	if pKey, ok := dest[0].(*string); ok {
+		*pKey = s.key
+	}
+	if pValue, ok := dest[1].(*string); ok {
+		*pValue = s.value
+	}
+	return nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
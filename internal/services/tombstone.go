@@ -0,0 +1,147 @@
+/*
+ * This file was last modified at 2026-10-18 21:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * tombstone.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/rbac"
+	"time"
+)
+
+// Удалённая запись (tombstone) в PostgreSQL помечается deleted = true и
+// может быть восстановлена undelete, пока её не удалит purgeLoop; в etcd
+// ключ удаляется сразу, поэтому для чтения запись отсутствует в обоих
+// хранилищах.
+
+const (
+	defaultPurgeBatchSize = 500
+	defaultPurgeInterval  = time.Hour
+	defaultPurgeRetention = 30 * 24 * time.Hour
+)
+
+// deleted страница записей помеченных удалёнными.
+func (k *keyValueDataService) deleted(ctx context.Context, prefix, from string, limit int64) (dto.List[entity.KeyValue], error) {
+
+	allow, err := authorizeList(ctx, k.enforcer, prefix)
+
+	if err != nil {
+		return dto.List[entity.KeyValue]{}, err
+	}
+	limit = listLimit(limit)
+	got, err := entity.ListDeletedKeyValue(ctx, k.postgresRepo, prefix, from, listFetchLimit(limit))
+
+	if err != nil {
+		k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.deleted", "msg", "postgres list failed", "err", err)
+		return dto.List[entity.KeyValue]{}, err
+	}
+	return makeListPage(got, limit, allow, listItem(false)), nil
+}
+
+// undelete восстановление записи помеченной удалённой: значение
+// из PostgreSQL снова записывается в etcd.
+func (k *keyValueDataService) undelete(ctx context.Context, key string) (entity.KeyValue, error) {
+
+	if err := k.enforcer.Authorize(ctx, rbac.ActionWrite, key); err != nil {
+		return entity.KeyValue{}, err
+	}
+	unit := MakeKeyValueNow(key, "")
+
+	if k.outboxConfig.Enabled {
+		if err := unit.UndeleteOutbox(ctx, k.postgresRepo); err != nil {
+			return entity.KeyValue{}, undeleteError(key, err)
+		}
+		k.outboxWake()
+
+		return unit, nil
+	}
+	if err := unit.Undelete(ctx, k.postgresRepo); err != nil {
+		return entity.KeyValue{}, undeleteError(key, err)
+	}
+	revision, err := k.putEtcd(ctx, unit)
+
+	if err == nil {
+		k.markRevision(ctx, unit, revision)
+	}
+	k.keyInvalidate(ctx, key)
+
+	return unit, err
+}
+
+// purge окончательное удаление записей помеченных удалёнными ранее
+// срока хранения, возвращает количество удалённых записей.
+func (k *keyValueDataService) purge(ctx context.Context) (int, error) {
+
+	retention := k.purgeConfig.Retention
+
+	if retention <= 0 {
+		retention = defaultPurgeRetention
+	}
+	batchSize := k.purgeConfig.BatchSize
+
+	if batchSize <= 0 {
+		batchSize = defaultPurgeBatchSize
+	}
+	before := time.Now().Add(-retention)
+	purged := 0
+
+	for {
+		got, err := entity.PurgeKeyValue(ctx, k.postgresRepo, before, int64(batchSize))
+		purged += len(got)
+
+		if err != nil || len(got) < batchSize {
+			return purged, err
+		}
+	}
+}
+
+// purgeLoop фоновое удаление записей помеченных удалёнными с интервалом
+// из настроек.
+func (k *keyValueDataService) purgeLoop(ctx context.Context) {
+
+	interval := k.purgeConfig.Interval
+
+	if interval <= 0 {
+		interval = defaultPurgeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		purged, err := k.purge(ctx)
+
+		if err != nil {
+			k.sLog.ErrorContext(ctx, env.MSG+"keyValueDataService.purgeLoop", "purged", purged, "err", err)
+		} else if purged > 0 {
+			k.sLog.InfoContext(ctx, env.MSG+"keyValueDataService.purgeLoop", "purged", purged)
+		}
+	}
+}
+
+func undeleteError(key string, err error) error {
+
+	if errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return err
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,130 @@
+/*
+ * This file was last modified at 2026-10-18 21:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * tombstone_test.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestTombstone(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for method undelete(context.Context, string)",
+			positiveUndelete,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, "key1", i) },
+		},
+		{
+			"test #1 negative for method undelete(context.Context, string) no tombstone",
+			negativeUndelete,
+			func(t *testing.T, i interface{}) bool { return assert.True(t, errors.Is(i.(error), ErrNotFound)) },
+		},
+		{
+			"test #2 positive for method purge(context.Context)",
+			positivePurge,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, 3, i) },
+		},
+		{
+			"test #3 positive for method deleted(context.Context, string, string, int64)",
+			positiveDeleted,
+			func(t *testing.T, i interface{}) bool { return assert.Equal(t, "key2", i) },
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveUndelete(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	srv.outboxConfig.Enabled = false
+	mocks.postgres.
+		EXPECT().
+		Do(gomock.Any(), entity.KeyValueUndelete, gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, nil).
+		Times(1)
+	mocks.etcd.
+		EXPECT().
+		Do(gomock.Any(), entity.KeyValueUpsert, gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, nil).
+		Times(1)
+
+	got, err := srv.undelete(context.Background(), "key1")
+
+	return got.Key(), err
+}
+
+// negativeUndelete ключ не помечен удалённым, etcd не изменяется.
+func negativeUndelete(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	srv.outboxConfig.Enabled = false
+	mocks.postgres.
+		EXPECT().
+		Do(gomock.Any(), entity.KeyValueUndelete, gomock.Any(), gomock.Any()).
+		Return(entity.KeyValue{}, domain.ErrNotFound).
+		Times(1)
+
+	_, err := srv.undelete(context.Background(), "key1")
+
+	return err, nil
+}
+
+func positivePurge(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	srv.purgeConfig = env.PurgeConfig{BatchSize: 2, Enabled: true}
+	gomock.InOrder(
+		mocks.postgres.
+			EXPECT().
+			Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]entity.KeyValue{MakeKeyValueNow("key1", ""), MakeKeyValueNow("key2", "")}, nil),
+		mocks.postgres.
+			EXPECT().
+			Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]entity.KeyValue{MakeKeyValueNow("key3", "")}, nil),
+	)
+	return srv.purge(context.Background())
+}
+
+func positiveDeleted(t *testing.T) (interface{}, error) {
+
+	srv, mocks := newTestKeyValueDataServiceWithOutbox(t)
+	mocks.postgres.
+		EXPECT().
+		Get(gomock.Any(), entity.MakeKeyValueDeleted("", listFetchLimit(1)), gomock.Any(), gomock.Any()).
+		Return([]entity.KeyValue{MakeKeyValueNow("key1", ""), MakeKeyValueNow("key2", "")}, nil).
+		Times(1)
+
+	got, err := srv.deleted(context.Background(), "key", "", 1)
+
+	if err != nil || !got.More {
+		return nil, err
+	}
+	return got.Next, nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,122 @@
+/*
+ * This file was last modified at 2026-10-18 13:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * txn.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/entity"
+	"strings"
+
+	pb "github.com/victor-skurikhin/etcd-client/v1/proto"
+)
+
+var ErrBadTxnOp = fmt.Errorf("bad transaction operation")
+
+// makeTxn транзакция домена из запроса API.
+func makeTxn(request dto.TxnRequest) (domain.Txn[entity.KeyValue], error) {
+
+	var err error
+	txn := domain.Txn[entity.KeyValue]{Compare: make([]domain.TxCompare, 0, len(request.Compare))}
+
+	for _, compare := range request.Compare {
+		txn.Compare = append(txn.Compare, domain.TxCompare{
+			Key:     compare.Key,
+			Result:  strings.ToLower(compare.Result),
+			Target:  strings.ToLower(compare.Target),
+			Value:   compare.Value,
+			Version: compare.Version,
+		})
+	}
+	if txn.Success, err = makeTxOps(request.Success); err != nil {
+		return txn, err
+	}
+	if txn.Failure, err = makeTxOps(request.Failure); err != nil {
+		return txn, err
+	}
+	return txn, nil
+}
+
+func makeTxOps(ops []dto.TxnOp) ([]domain.TxOp[entity.KeyValue], error) {
+
+	result := make([]domain.TxOp[entity.KeyValue], 0, len(ops))
+
+	for _, op := range ops {
+		switch strings.ToUpper(op.Type) {
+		case dto.EventDelete:
+			result = append(result, domain.TxOp[entity.KeyValue]{
+				Name: domain.DeleteAction,
+				Unit: MakeKeyValueNow(op.Key, ""),
+			})
+		case dto.EventPut:
+			result = append(result, domain.TxOp[entity.KeyValue]{
+				Name: domain.UpsertAction,
+				Unit: MakeKeyValueNow(op.Key, op.Value),
+			})
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrBadTxnOp, op.Type)
+		}
+	}
+	return result, nil
+}
+
+// makeTxnRequest запрос API из запроса gRPC.
+func makeTxnRequest(request *pb.TxnRequest) dto.TxnRequest {
+
+	result := dto.TxnRequest{
+		Compare: make([]dto.TxnCompare, 0, len(request.GetCompare())),
+		Failure: makeTxnOps(request.GetFailure()),
+		Success: makeTxnOps(request.GetSuccess()),
+	}
+	for _, compare := range request.GetCompare() {
+		result.Compare = append(result.Compare, dto.TxnCompare{
+			Key:     compare.GetKey(),
+			Result:  compare.GetResult().String(),
+			Target:  compare.GetTarget().String(),
+			Value:   compare.GetValue(),
+			Version: compare.GetVersion(),
+		})
+	}
+	return result
+}
+
+func makeTxnOps(ops []*pb.TxnOp) []dto.TxnOp {
+
+	result := make([]dto.TxnOp, 0, len(ops))
+
+	for _, op := range ops {
+		result = append(result, dto.TxnOp{
+			Type:  op.GetType().String(),
+			Key:   op.GetKeyValue().GetKey(),
+			Value: op.GetKeyValue().GetValue(),
+		})
+	}
+	return result
+}
+
+// txnKeys ключи, изменённые выполненной ветвью транзакции.
+func txnKeys(txn domain.Txn[entity.KeyValue], succeeded bool) []string {
+
+	ops := txn.Success
+
+	if !succeeded {
+		ops = txn.Failure
+	}
+	result := make([]string, 0, len(ops))
+
+	for _, op := range ops {
+		result = append(result, op.Unit.Key())
+	}
+	return result
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,50 @@
+/*
+ * This file was last modified at 2026-10-18 12:05 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * version_conflict.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/repo"
+)
+
+// VersionConflictError — ожидаемая версия записи не совпала с текущей.
+type VersionConflictError struct {
+	Key     string
+	Current int64
+}
+
+func (e VersionConflictError) Error() string {
+	return fmt.Sprintf("%s, key: %s, current version: %d", domain.ErrVersionMismatch, e.Key, e.Current)
+}
+
+func (e VersionConflictError) Unwrap() error {
+	return domain.ErrVersionMismatch
+}
+
+// etcdVersionConflict дополняет ErrVersionMismatch условной записи в etcd
+// текущей версией ключа, которую вернула транзакция.
+func etcdVersionConflict(key string, err error) error {
+
+	if !errors.Is(err, domain.ErrVersionMismatch) {
+		return err
+	}
+	var current int64
+	var etcdError repo.EtcdError
+
+	if errors.As(err, &etcdError) {
+		current, _ = etcdError.Info().(int64)
+	}
+	return VersionConflictError{Key: key, Current: current}
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,235 @@
+/*
+ * This file was last modified at 2026-10-18 11:20 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * watch.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/memory"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/metrics"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"log/slog"
+	"strings"
+	"time"
+
+	pb "github.com/victor-skurikhin/etcd-client/v1/proto"
+	clientV3 "go.etcd.io/etcd/client/v3"
+)
+
+const watchRetryDelay = time.Second
+
+var ErrBadEventType = fmt.Errorf("bad event type")
+
+// watchCacheInvalidate цикл подписки на ключ CacheInvalidate: удаляет из кэшей
+// инвалидированные ключи. При разрыве подписки кэши сбрасываются целиком,
+// так как события за время разрыва потеряны, и подписка возобновляется.
+func watchCacheInvalidate(
+	ctx context.Context,
+	sLog *slog.Logger,
+	watcher clientV3.Watcher,
+	name string,
+	caches ...memory.Cache,
+) {
+	watchReconnect(ctx, sLog, name, caches, func() {
+		watchInvalidate(ctx, sLog, watcher, name, caches...)
+	})
+}
+
+// watchNegativeForget цикл подписки на запись любых ключей: удаляет их из
+// кэша отсутствующих ключей negative, в том числе созданные в etcd в обход
+// этого экземпляра. При разрыве подписки кэш сбрасывается целиком.
+// Подписка получает значения всех записываемых в etcd ключей, поэтому
+// включается настройкой cache.negative_watch.
+func watchNegativeForget(
+	ctx context.Context,
+	sLog *slog.Logger,
+	watcher clientV3.Watcher,
+	name string,
+	negative memory.Cache,
+) {
+	name += "_negative"
+	watchReconnect(ctx, sLog, name, []memory.Cache{negative}, func() {
+		watchForget(ctx, sLog, watcher, name, negative)
+	})
+}
+
+// watchReconnect повтор подписки watch до отмены ctx со сбросом кэшей
+// caches после каждого разрыва.
+func watchReconnect(ctx context.Context, sLog *slog.Logger, name string, caches []memory.Cache, watch func()) {
+
+	for {
+		watch()
+
+		if ctx.Err() != nil {
+			return
+		}
+		metrics.WatchReconnect(name)
+		sLog.WarnContext(ctx, env.MSG+"watchReconnect", "msg", "reconnect", "watcher", name)
+
+		for _, cache := range caches {
+			if err := cache.Invalidate(); err != nil {
+				sLog.ErrorContext(ctx, env.MSG+"watchReconnect", "msg", "cache.Invalidate", "err", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryDelay):
+		}
+	}
+}
+
+func watchInvalidate(
+	ctx context.Context,
+	sLog *slog.Logger,
+	watcher clientV3.Watcher,
+	name string,
+	caches ...memory.Cache,
+) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rch := watcher.Watch(clientV3.WithRequireLeader(ctx), CacheInvalidate)
+
+	for watchResp := range rch {
+		if err := watchResp.Err(); err != nil {
+			sLog.ErrorContext(ctx, env.MSG+"watchCacheInvalidate", "msg", "watch response", "watcher", name, "err", err)
+			return
+		}
+		for _, ev := range watchResp.Events {
+			key := string(ev.Kv.Value)
+			var err error
+			for _, cache := range caches {
+				err = errors.Join(err, cache.Delete(key))
+			}
+			if err != nil {
+				sLog.ErrorContext(ctx,
+					env.MSG+"watchCacheInvalidate",
+					"msg", "cache.Delete",
+					"watcher", name,
+					"err", err)
+			} else {
+				sLog.DebugContext(ctx,
+					env.MSG+"watchCacheInvalidate",
+					"msg", fmt.Sprintf("Cache invalidate by key: \"%s\" is OK.", key),
+					"watcher", name,
+				)
+			}
+		}
+	}
+}
+
+func watchForget(
+	ctx context.Context,
+	sLog *slog.Logger,
+	watcher clientV3.Watcher,
+	name string,
+	negative memory.Cache,
+) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rch := watcher.Watch(clientV3.WithRequireLeader(ctx), "", clientV3.WithPrefix(), clientV3.WithFilterDelete())
+
+	for watchResp := range rch {
+		if err := watchResp.Err(); err != nil {
+			sLog.ErrorContext(ctx, env.MSG+"watchNegativeForget", "msg", "watch response", "watcher", name, "err", err)
+			return
+		}
+		for _, ev := range watchResp.Events {
+			if key := string(ev.Kv.Key); key != CacheInvalidate {
+				if err := forgetNotFound(negative, key); err != nil {
+					sLog.ErrorContext(ctx, env.MSG+"watchNegativeForget", "msg", "forgetNotFound", "watcher", name, "err", err)
+				}
+			}
+		}
+	}
+}
+
+// watchOptions опции подписки etcd: префикс, стартовая ревизия и фильтр типов
+// событий (пустой список типов — все события).
+func watchOptions(request dto.WatchRequest) ([]clientV3.OpOption, error) {
+
+	opts := []clientV3.OpOption{clientV3.WithPrefix()}
+
+	if request.StartRevision > 0 {
+		opts = append(opts, clientV3.WithRev(request.StartRevision))
+	}
+	if len(request.EventTypes) == 0 {
+		return opts, nil
+	}
+	var put, del bool
+
+	for _, eventType := range request.EventTypes {
+		switch strings.ToUpper(eventType) {
+		case dto.EventPut:
+			put = true
+		case dto.EventDelete:
+			del = true
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrBadEventType, eventType)
+		}
+	}
+	if !put {
+		opts = append(opts, clientV3.WithFilterPut())
+	}
+	if !del {
+		opts = append(opts, clientV3.WithFilterDelete())
+	}
+	return opts, nil
+}
+
+func makeWatchEvent(ev *clientV3.Event) dto.WatchEvent {
+
+	event := dto.WatchEvent{
+		Key:      string(ev.Kv.Key),
+		Revision: ev.Kv.ModRevision,
+		Type:     dto.EventPut,
+	}
+	if ev.Type == mvccpb.DELETE {
+		event.Type = dto.EventDelete
+	} else {
+		event.Value = string(ev.Kv.Value)
+		event.Version = ev.Kv.Version
+	}
+	return event
+}
+
+func makePbWatchEvent(event dto.WatchEvent) *pb.WatchEvent {
+
+	eventType := pb.EventType_PUT
+
+	if event.Type == dto.EventDelete {
+		eventType = pb.EventType_DELETE
+	}
+	return &pb.WatchEvent{
+		Type:     eventType,
+		KeyValue: &pb.KeyValue{Key: event.Key, Value: event.Value, Version: event.Version},
+		Revision: event.Revision,
+	}
+}
+
+func makeWatchRequest(request *pb.WatchRequest) dto.WatchRequest {
+
+	eventTypes := make([]string, 0, len(request.GetEventTypes()))
+
+	for _, eventType := range request.GetEventTypes() {
+		eventTypes = append(eventTypes, eventType.String())
+	}
+	return dto.WatchRequest{
+		Prefix:        request.GetPrefix(),
+		StartRevision: request.GetStartRevision(),
+		EventTypes:    eventTypes,
+	}
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
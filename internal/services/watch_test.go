@@ -0,0 +1,205 @@
+/*
+ * This file was last modified at 2026-10-18 11:20 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * watch_test.go
+ * $Id$
+ */
+//!+
+
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/controllers/dto"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/domain/memory"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"log/slog"
+	"testing"
+	"time"
+
+	pb "github.com/victor-skurikhin/etcd-client/v1/proto"
+	clientV3 "go.etcd.io/etcd/client/v3"
+)
+
+func TestWatch(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for function watchOptions(dto.WatchRequest) all event types",
+			positiveWatchOptionsAll,
+			positiveWatchOptionsAllCheck,
+		},
+		{
+			"test #1 positive for function watchOptions(dto.WatchRequest) with filter",
+			positiveWatchOptionsFilter,
+			positiveWatchOptionsFilterCheck,
+		},
+		{
+			"test #2 negative for function watchOptions(dto.WatchRequest)",
+			negativeWatchOptions,
+			negativeWatchOptionsCheck,
+		},
+		{
+			"test #3 positive for function makeWatchEvent(*clientV3.Event) PUT",
+			positiveMakeWatchEventPut,
+			positiveMakeWatchEventPutCheck,
+		},
+		{
+			"test #4 positive for function makeWatchEvent(*clientV3.Event) DELETE",
+			positiveMakeWatchEventDelete,
+			positiveMakeWatchEventDeleteCheck,
+		},
+		{
+			"test #5 positive for functions makeWatchRequest and makePbWatchEvent",
+			positiveMakePbWatch,
+			positiveMakePbWatchCheck,
+		},
+		{
+			"test #6 positive for function watchForget(...) key created outside this proxy",
+			positiveWatchForget,
+			func(t *testing.T, i interface{}) bool {
+				return assert.Equal(t, []bool{false, true}, i)
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveWatchOptionsAll(_ *testing.T) (interface{}, error) {
+	return watchOptions(dto.WatchRequest{Prefix: "key", StartRevision: 7})
+}
+
+func positiveWatchOptionsAllCheck(t *testing.T, i interface{}) bool {
+	if opts, ok := i.([]clientV3.OpOption); ok {
+		return assert.Len(t, opts, 2)
+	}
+	return false
+}
+
+func positiveWatchOptionsFilter(_ *testing.T) (interface{}, error) {
+	return watchOptions(dto.WatchRequest{Prefix: "key", EventTypes: []string{"delete"}})
+}
+
+func positiveWatchOptionsFilterCheck(t *testing.T, i interface{}) bool {
+	if opts, ok := i.([]clientV3.OpOption); ok {
+		return assert.Len(t, opts, 2)
+	}
+	return false
+}
+
+func negativeWatchOptions(_ *testing.T) (interface{}, error) {
+	_, err := watchOptions(dto.WatchRequest{EventTypes: []string{"PATCH"}})
+	return err, nil
+}
+
+func negativeWatchOptionsCheck(_ *testing.T, i interface{}) bool {
+	if err, ok := i.(error); ok {
+		return errors.Is(err, ErrBadEventType)
+	}
+	return false
+}
+
+func positiveMakeWatchEventPut(_ *testing.T) (interface{}, error) {
+	return makeWatchEvent(&clientV3.Event{
+		Type: mvccpb.PUT,
+		Kv:   &mvccpb.KeyValue{Key: []byte("key1"), Value: []byte("value1"), Version: 2, ModRevision: 11},
+	}), nil
+}
+
+func positiveMakeWatchEventPutCheck(t *testing.T, i interface{}) bool {
+	return assert.Equal(t, dto.WatchEvent{Type: dto.EventPut, Key: "key1", Value: "value1", Version: 2, Revision: 11}, i)
+}
+
+func positiveMakeWatchEventDelete(_ *testing.T) (interface{}, error) {
+	return makeWatchEvent(&clientV3.Event{
+		Type: mvccpb.DELETE,
+		Kv:   &mvccpb.KeyValue{Key: []byte("key1"), ModRevision: 12},
+	}), nil
+}
+
+func positiveMakeWatchEventDeleteCheck(t *testing.T, i interface{}) bool {
+	return assert.Equal(t, dto.WatchEvent{Type: dto.EventDelete, Key: "key1", Revision: 12}, i)
+}
+
+func positiveMakePbWatch(_ *testing.T) (interface{}, error) {
+
+	request := makeWatchRequest(&pb.WatchRequest{
+		Prefix:     "key",
+		EventTypes: []pb.EventType{pb.EventType_DELETE},
+	})
+	if _, err := watchOptions(request); err != nil {
+		return nil, err
+	}
+	return makePbWatchEvent(dto.WatchEvent{Type: request.EventTypes[0], Key: "key1", Revision: 12}), nil
+}
+
+func positiveMakePbWatchCheck(t *testing.T, i interface{}) bool {
+	if event, ok := i.(*pb.WatchEvent); ok {
+		return assert.Equal(t, pb.EventType_DELETE, event.Type) &&
+			assert.Equal(t, "key1", event.KeyValue.Key) &&
+			assert.Equal(t, int64(12), event.Revision)
+	}
+	return false
+}
+
+func positiveWatchForget(_ *testing.T) (interface{}, error) {
+
+	negative := memory.NewLRU(memory.Config{MaxEntries: 10})
+	defer func() { _ = negative.Close() }()
+
+	for _, key := range []string{"/created", "/missing"} {
+		if err := cacheNotFound(negative, key, time.Minute); err != nil {
+			return nil, err
+		}
+	}
+	// Ключ /created записан в etcd другим клиентом, не через этот экземпляр.
+	watcher := watchTestWatcher{responses: []clientV3.WatchResponse{{
+		Events: []*clientV3.Event{{
+			Type: mvccpb.PUT,
+			Kv:   &mvccpb.KeyValue{Key: []byte("/created"), Value: []byte("value1"), Version: 1},
+		}},
+	}}}
+	watchForget(context.Background(), slog.Default(), watcher, "test", negative)
+
+	return []bool{cachedNotFound(negative, "/created"), cachedNotFound(negative, "/missing")}, nil
+}
+
+// watchTestWatcher подписка, отдающая responses и закрывающая канал.
+type watchTestWatcher struct {
+	responses []clientV3.WatchResponse
+}
+
+func (w watchTestWatcher) Watch(_ context.Context, _ string, _ ...clientV3.OpOption) clientV3.WatchChan {
+
+	rch := make(chan clientV3.WatchResponse, len(w.responses))
+
+	for _, resp := range w.responses {
+		rch <- resp
+	}
+	close(rch)
+
+	return rch
+}
+
+func (w watchTestWatcher) RequestProgress(_ context.Context) error {
+	return nil
+}
+
+func (w watchTestWatcher) Close() error {
+	return nil
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
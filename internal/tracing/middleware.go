@@ -0,0 +1,77 @@
+/*
+ * This file was last modified at 2026-10-18 17:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * middleware.go
+ * $Id$
+ */
+//!+
+
+package tracing
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"strings"
+
+	semConv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// New — промежуточный обработчик Fiber: серверный спан на запрос,
+// продолжающий трассировку из заголовков traceparent и baggage.
+// Контекст со спаном доступен обработчикам через UserContext.
+func New() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+
+		carrier := propagation.MapCarrier{}
+		c.Request().Header.VisitAll(func(key, value []byte) {
+			carrier.Set(strings.ToLower(string(key)), string(value))
+		})
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), carrier)
+		ctx, span := Start(ctx, c.Method()+" "+c.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semConv.HTTPRequestMethodKey.String(c.Method()),
+				semConv.URLPath(c.Path()),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		code := c.Response().StatusCode()
+
+		if err != nil {
+			code = fiber.StatusInternalServerError
+			var fe *fiber.Error
+
+			if errors.As(err, &fe) {
+				code = fe.Code
+			}
+			span.RecordError(err)
+		}
+		route := c.Route().Path
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(semConv.HTTPRoute(route), semConv.HTTPResponseStatusCode(code))
+
+		if code >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, utils.StatusMessage(code))
+		}
+		return err
+	}
+}
+
+// ServerOption — серверные спаны gRPC с извлечением контекста трассировки из метаданных.
+func ServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler())
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
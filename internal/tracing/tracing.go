@@ -0,0 +1,120 @@
+/*
+ * This file was last modified at 2026-10-18 17:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * tracing.go
+ * $Id$
+ */
+//!+
+
+// Package tracing трассировка OpenTelemetry: экспорт по OTLP/gRPC,
+// спаны HTTP, gRPC, etcd и PostgreSQL.
+package tracing
+
+import (
+	"context"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	semConv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	defaultServiceName  = "etcd-proxy"
+	instrumentationName = "github.com/victor-skurikhin/etcd-client/v1"
+)
+
+// Атрибуты спанов обращений к хранилищам.
+var (
+	DBSystemEtcd     = semConv.DBSystemKey.String("etcd")
+	DBSystemPostgres = semConv.DBSystemPostgreSQL
+	KeyAttribute     = attribute.Key("etcd_proxy.key")
+)
+
+// Setup — настройка глобального провайдера трассировки и распространителя
+// контекста (W3C Trace Context и Baggage). При выключенной трассировке
+// спаны не экспортируются, но контекст входящих запросов распространяется.
+// Возвращает функцию выгрузки накопленных спанов при остановке сервиса.
+func Setup(ctx context.Context, cfg env.TracingConfig) (func(context.Context) error, error) {
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+
+	if err != nil {
+		return nil, err
+	}
+	serviceName := cfg.ServiceName
+
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	provider := sdkTrace.NewTracerProvider(
+		sdkTrace.WithBatcher(exporter),
+		sdkTrace.WithResource(resource.NewWithAttributes(
+			semConv.SchemaURL,
+			semConv.ServiceName(serviceName),
+		)),
+		sdkTrace.WithSampler(sdkTrace.ParentBased(sdkTrace.TraceIDRatioBased(sampleRatio(cfg.SampleRatio)))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start — начало спана с именем name, ctx результата содержит новый спан.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// StartDB — клиентский спан операции operation хранилища system.
+func StartDB(
+	ctx context.Context,
+	system attribute.KeyValue,
+	operation string,
+	attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	return Start(ctx, system.Value.AsString()+" "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append([]attribute.KeyValue{system, semConv.DBOperationName(operation)}, attrs...)...),
+	)
+}
+
+// End — завершение спана, ошибка записывается в спан и меняет его статус.
+func End(span trace.Span, err error) {
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// sampleRatio доля трассируемых запросов, не заданная в конфигурации — все запросы.
+func sampleRatio(ratio float64) float64 {
+
+	if ratio <= 0 || ratio > 1 {
+		return 1
+	}
+	return ratio
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -0,0 +1,149 @@
+/*
+ * This file was last modified at 2026-10-18 17:40 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * tracing_test.go
+ * $Id$
+ */
+//!+
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"net/http/httptest"
+	"testing"
+
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+func TestTracing(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for function New() continues trace from traceparent",
+			positiveMiddleware,
+			positiveMiddlewareCheck,
+		},
+		{
+			"test #1 positive for functions StartDB and End with error",
+			positiveStartDBEnd,
+			positiveStartDBEndCheck,
+		},
+		{
+			"test #2 positive for function Setup with disabled tracing",
+			positiveSetupDisabled,
+			positiveSetupDisabledCheck,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func positiveMiddleware(t *testing.T) (interface{}, error) {
+
+	recorder := setRecorder(t)
+
+	if _, err := Setup(context.Background(), env.TracingConfig{}); err != nil {
+		return nil, err
+	}
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/get/:name", func(c *fiber.Ctx) error {
+		_, span := StartDB(c.UserContext(), DBSystemEtcd, "select", KeyAttribute.String(c.Params("name")))
+		span.End()
+		return c.SendString("ok")
+	})
+	req := httptest.NewRequest(fiber.MethodGet, "/get/key1", nil)
+	req.Header.Set("traceparent", "00-"+testTraceID+"-00f067aa0ba902b7-01")
+
+	if _, err := app.Test(req); err != nil {
+		return nil, err
+	}
+	return recorder.Ended(), nil
+}
+
+func positiveMiddlewareCheck(t *testing.T, i interface{}) bool {
+
+	spans := i.([]sdkTrace.ReadOnlySpan)
+
+	if !assert.Len(t, spans, 2) {
+		return false
+	}
+	db, server := spans[0], spans[1]
+
+	return assert.Equal(t, "etcd select", db.Name()) &&
+		assert.Equal(t, trace.SpanKindClient, db.SpanKind()) &&
+		assert.Equal(t, server.SpanContext().SpanID(), db.Parent().SpanID()) &&
+		assert.Equal(t, "GET /get/:name", server.Name()) &&
+		assert.Equal(t, trace.SpanKindServer, server.SpanKind()) &&
+		assert.Equal(t, testTraceID, server.SpanContext().TraceID().String()) &&
+		assert.True(t, server.Parent().IsRemote())
+}
+
+func positiveStartDBEnd(t *testing.T) (interface{}, error) {
+
+	recorder := setRecorder(t)
+	_, span := StartDB(context.Background(), DBSystemPostgres, "upsert")
+	End(span, errors.New("test error"))
+
+	return recorder.Ended(), nil
+}
+
+func positiveStartDBEndCheck(t *testing.T, i interface{}) bool {
+
+	spans := i.([]sdkTrace.ReadOnlySpan)
+
+	if !assert.Len(t, spans, 1) {
+		return false
+	}
+	return assert.Equal(t, "postgresql upsert", spans[0].Name()) &&
+		assert.Equal(t, codes.Error, spans[0].Status().Code) &&
+		assert.Equal(t, "test error", spans[0].Status().Description) &&
+		assert.Len(t, spans[0].Events(), 1)
+}
+
+func positiveSetupDisabled(_ *testing.T) (interface{}, error) {
+
+	shutdown, err := Setup(context.Background(), env.TracingConfig{Enabled: false})
+
+	if err != nil {
+		return nil, err
+	}
+	return otel.GetTextMapPropagator().Fields(), shutdown(context.Background())
+}
+
+func positiveSetupDisabledCheck(t *testing.T, i interface{}) bool {
+	return assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, i)
+}
+
+func setRecorder(t *testing.T) *tracetest.SpanRecorder {
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	return recorder
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
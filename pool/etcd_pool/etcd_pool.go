@@ -1,16 +1,17 @@
 /*
- * This file was last modified at 2024-08-05 23:27 by Victor N. Skurikhin.
+ * This file was last modified at 2026-10-18 15:10 by Victor N. Skurikhin.
  * This is free and unencumbered software released into the public domain.
  * For more information, please refer to <http://unlicense.org>
- * mongo_pool.go
+ * etcd_pool.go
  * $Id$
  */
 //!+
 
-// Package etcd_pool TODO.
+// Package etcd_pool реализации пула клиентов etcd.
 package etcd_pool
 
 import (
+	"context"
 	"fmt"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
 	"github.com/victor-skurikhin/etcd-client/v1/pool"
@@ -22,6 +23,8 @@ import (
 	"time"
 )
 
+const defaultHealthInterval = 10 * time.Second
+
 var _ pool.EtcdPool = (*etcdPool)(nil)
 
 var (
@@ -29,104 +32,389 @@ var (
 	etcdPoolInst *etcdPool
 )
 
+var (
+	ErrPoolClosed    = fmt.Errorf("etcd pool closed")
+	ErrForeignClient = fmt.Errorf("client does not belong to etcd pool")
+)
+
 type etcdPool struct {
-	clientConfig clientV3.Config
-	connections  int
-	pool         chan clientV3.KV
-	poolSize     int
-	sLog         *slog.Logger
-	timeout      time.Duration
+	clientConfig   clientV3.Config
+	closed         bool
+	done           chan struct{}
+	healthInterval time.Duration
+	idle           []*clientV3.Client
+	inUse          int
+	maxIdle        int
+	maxOpen        int
+	minIdle        int
+	mu             sync.Mutex
+	numOpen        int
+	sLog           *slog.Logger
+	waitCount      int64
+	waitDuration   time.Duration
+	waiters        []chan *clientV3.Client
 }
 
+// GetPool — пул клиентов etcd, реализация выбирается настройкой
+// etcd.pool.enabled: пул переиспользуемых клиентов или новый клиент на каждый запрос.
+func GetPool(cfg env.Config) pool.EtcdPool {
+
+	if cfg.EtcdPoolConfig().Enabled {
+		return GetEtcdPool(cfg)
+	}
+	return GetSingleFabricEtcdClient(cfg)
+}
+
+// GetEtcdPool — потокобезопасное (thread-safe) создание пула клиентов etcd.
 func GetEtcdPool(cfg env.Config) pool.EtcdPool {
 
 	onceEtcdPool.Do(func() {
-		etcdPoolInst = new(etcdPool)
-		etcdPoolInst.clientConfig = *cfg.EtcdClientConfig()
-		etcdPoolInst.pool = make(chan clientV3.KV, 50*runtime.NumCPU())
-		etcdPoolInst.poolSize = 50 * runtime.NumCPU()
-		etcdPoolInst.sLog = cfg.Logger()
-		etcdPoolInst.timeout = 500 * time.Millisecond
+		etcdPoolInst = newEtcdPool(*cfg.EtcdClientConfig(), cfg.EtcdPoolConfig(), cfg.Logger())
 	})
 	return etcdPoolInst
 }
 
-func (e *etcdPool) AcquireClient() (clientV3.KV, error) {
+// New — создание пула клиентов etcd вне синглтона.
+func New(clientConfig clientV3.Config, poolConfig env.PoolConfig, sLog *slog.Logger) pool.EtcdPool {
+	return newEtcdPool(clientConfig, poolConfig, sLog)
+}
+
+func newEtcdPool(clientConfig clientV3.Config, poolConfig env.PoolConfig, sLog *slog.Logger) *etcdPool {
+
+	e := new(etcdPool)
+	e.clientConfig = clientConfig
+	e.done = make(chan struct{})
+	e.healthInterval = poolConfig.HealthInterval
+	e.maxIdle = poolConfig.MaxIdle
+	e.maxOpen = poolConfig.MaxOpen
+	e.minIdle = poolConfig.MinIdle
+	e.sLog = sLog
+
+	if e.maxOpen <= 0 {
+		e.maxOpen = 50 * runtime.NumCPU()
+	}
+	if e.maxIdle <= 0 || e.maxIdle > e.maxOpen {
+		e.maxIdle = e.maxOpen
+	}
+	if e.minIdle > e.maxIdle {
+		e.minIdle = e.maxIdle
+	}
+	if e.healthInterval <= 0 {
+		e.healthInterval = defaultHealthInterval
+	}
+	go e.healthProbe()
+
+	return e
+}
+
+// AcquireClient — получение клиента из пула. Если все клиенты заняты
+// и открыто максимальное количество, ждёт освобождения клиента
+// или отмены контекста.
+func (e *etcdPool) AcquireClient(ctx context.Context) (clientV3.KV, error) {
+
 	for {
-		select {
-		case client := <-e.pool:
-			switch getStateActiveConn(client) {
-			case connectivity.Idle:
-				fallthrough
-			case connectivity.Ready:
-				fallthrough
-			case connectivity.Connecting:
-				return client, nil
-			case connectivity.Shutdown:
-				return nil, fmt.Errorf("connectivity state: %s", getStateActiveConn(client).String())
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		e.mu.Lock()
+
+		if e.closed {
+			e.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+		if n := len(e.idle); n > 0 {
+			client := e.idle[n-1]
+			e.idle = e.idle[:n-1]
+
+			if !isHealthy(client) {
+				e.numOpen--
+				e.mu.Unlock()
+				e.closeClient(client)
+				continue
 			}
-		default:
-			if e.connections < e.poolSize {
-				e.createClientToChan()
+			e.inUse++
+			e.mu.Unlock()
+			return client, nil
+		}
+		if e.numOpen < e.maxOpen {
+			e.numOpen++
+			e.inUse++
+			e.mu.Unlock()
+			client, err := clientV3.New(e.clientConfig)
+
+			if err != nil {
+				e.sLog.Error(env.MSG+"etcdPool: Create the client failed", "err", err)
+				e.mu.Lock()
+				e.numOpen--
+				e.inUse--
+				e.notifyWaiter()
+				e.mu.Unlock()
+				return nil, err
 			}
+			return client, nil
+		}
+		client, err := e.wait(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+		if client != nil {
+			return client, nil
 		}
 	}
 }
 
+// ReleaseClient — возврат клиента в пул. Неисправный клиент закрывается,
+// исправный передаётся ожидающему или остаётся простаивать в пуле,
+// если не превышено максимальное количество простаивающих.
 func (e *etcdPool) ReleaseClient(client clientV3.KV) error {
-	select {
-	case e.pool <- client:
+
+	cli, ok := client.(*clientV3.Client)
+
+	if !ok || cli == nil {
+		return ErrForeignClient
+	}
+	e.mu.Lock()
+	e.inUse--
+
+	switch {
+	case e.closed || !isHealthy(cli):
+		e.numOpen--
+		e.notifyWaiter()
+	case len(e.waiters) > 0:
+		e.inUse++
+		e.handOff(cli)
+		e.mu.Unlock()
 		return nil
-	default:
-		if cli, ok := client.(*clientV3.Client); ok {
-			if err := cli.Close(); err != nil {
-				e.sLog.Error(env.MSG+"etcdPool: Close the client failed", "err", err)
-				return err
-			} else {
-				e.connections--
-			}
-		}
+	case len(e.idle) < e.maxIdle:
+		e.idle = append(e.idle, cli)
+		e.mu.Unlock()
 		return nil
+	default:
+		e.numOpen--
 	}
+	e.mu.Unlock()
+
+	if err := cli.Close(); err != nil {
+		e.sLog.Error(env.MSG+"etcdPool: Close the client failed", "err", err)
+		return err
+	}
+	return nil
 }
 
+// GracefulClose — закрытие пула: простаивающие клиенты закрываются сразу,
+// занятые при возврате в пул, ожидающие получают ErrPoolClosed.
 func (e *etcdPool) GracefulClose() (err error) {
-	if e.pool == nil {
-		return fmt.Errorf("close of nil channel")
+
+	e.mu.Lock()
+
+	if e.closed {
+		e.mu.Unlock()
+		return ErrPoolClosed
+	}
+	e.closed = true
+	close(e.done)
+	idle := e.idle
+	e.idle = nil
+	e.numOpen -= len(idle)
+
+	for len(e.waiters) > 0 {
+		e.handOff(nil)
 	}
-	close(e.pool)
+	e.mu.Unlock()
 
-	for client := range e.pool {
-		if cli, ok := client.(*clientV3.Client); ok {
-			err = cli.Close()
+	for _, client := range idle {
+		if e := client.Close(); e != nil {
+			err = e
 		}
 	}
 	return err
 }
 
-func (e *etcdPool) createClientToChan() clientV3.KV {
+// Stats — текущая статистика использования пула.
+func (e *etcdPool) Stats() pool.Stats {
 
-	client, err := clientV3.New(e.clientConfig)
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-	if err != nil {
-		e.sLog.Error(env.MSG+"etcdPool: Create the client failed", "err", err)
+	return pool.Stats{
+		Idle:         len(e.idle),
+		InUse:        e.inUse,
+		Open:         e.numOpen,
+		WaitCount:    e.waitCount,
+		WaitDuration: e.waitDuration,
 	}
-	e.pool <- client
-	e.connections++
+}
+
+// wait ожидание клиента, освобождённого другим потребителем.
+// Вызывается под e.mu, освобождает его. Клиент nil означает,
+// что место в пуле освободилось и можно повторить попытку.
+func (e *etcdPool) wait(ctx context.Context) (*clientV3.Client, error) {
+
+	req := make(chan *clientV3.Client, 1)
+	e.waiters = append(e.waiters, req)
+	e.waitCount++
+	e.mu.Unlock()
+	start := time.Now()
 
-	return client
+	defer func() {
+		e.mu.Lock()
+		e.waitDuration += time.Since(start)
+		e.mu.Unlock()
+	}()
+	select {
+	case client, ok := <-req:
+		if !ok {
+			return nil, ErrPoolClosed
+		}
+		return client, nil
+	case <-ctx.Done():
+		e.mu.Lock()
+
+		for i, w := range e.waiters {
+			if w == req {
+				e.waiters = append(e.waiters[:i], e.waiters[i+1:]...)
+				e.mu.Unlock()
+				return nil, ctx.Err()
+			}
+		}
+		e.mu.Unlock()
+		// Клиент или освободившееся место уже переданы этому ожидающему,
+		// возвращаем их в пул.
+		if client, ok := <-req; ok && client != nil {
+			_ = e.ReleaseClient(client)
+		} else if ok {
+			e.mu.Lock()
+			e.notifyWaiter()
+			e.mu.Unlock()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// handOff передача клиента первому ожидающему, вызывается под e.mu.
+// При закрытом пуле канал ожидающего закрывается.
+func (e *etcdPool) handOff(client *clientV3.Client) {
+
+	req := e.waiters[0]
+	e.waiters = e.waiters[1:]
+
+	if e.closed {
+		close(req)
+		return
+	}
+	req <- client
+}
+
+// notifyWaiter сообщает первому ожидающему об освободившемся месте в пуле,
+// вызывается под e.mu.
+func (e *etcdPool) notifyWaiter() {
+	if len(e.waiters) > 0 {
+		e.handOff(nil)
+	}
+}
+
+// healthProbe фоновая проверка простаивающих клиентов: клиенты
+// в состоянии Shutdown или TransientFailure закрываются,
+// пул пополняется до минимального количества простаивающих.
+func (e *etcdPool) healthProbe() {
+
+	ticker := time.NewTicker(e.healthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+			e.evictUnhealthy()
+			e.fillMinIdle()
+		}
+	}
+}
+
+func (e *etcdPool) evictUnhealthy() {
+
+	e.mu.Lock()
+	var evicted []*clientV3.Client
+	idle := e.idle[:0]
+
+	for _, client := range e.idle {
+		if isHealthy(client) {
+			idle = append(idle, client)
+		} else {
+			evicted = append(evicted, client)
+		}
+	}
+	e.idle = idle
+	e.numOpen -= len(evicted)
+
+	for range evicted {
+		e.notifyWaiter()
+	}
+	e.mu.Unlock()
+
+	for _, client := range evicted {
+		e.sLog.Warn(env.MSG+"etcdPool: evict unhealthy client", "state", getStateActiveConn(client).String())
+		e.closeClient(client)
+	}
 }
 
-func getStateActiveConn(client clientV3.KV) connectivity.State {
-	if cli, ok := client.(*clientV3.Client); ok {
-		if client == nil {
-			return -2
-		} else if cli.ActiveConnection() == nil {
-			return -3
+func (e *etcdPool) fillMinIdle() {
+
+	e.mu.Lock()
+	n := min(e.minIdle-len(e.idle), e.maxOpen-e.numOpen)
+
+	if e.closed || n <= 0 {
+		e.mu.Unlock()
+		return
+	}
+	e.numOpen += n
+	e.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		client, err := clientV3.New(e.clientConfig)
+		e.mu.Lock()
+
+		switch {
+		case err != nil:
+			e.sLog.Error(env.MSG+"etcdPool: Create the client failed", "err", err)
+			e.numOpen--
+			e.notifyWaiter()
+		case e.closed:
+			e.numOpen--
+			e.mu.Unlock()
+			e.closeClient(client)
+			continue
+		case len(e.waiters) > 0:
+			e.inUse++
+			e.handOff(client)
+		default:
+			e.idle = append(e.idle, client)
 		}
-		return cli.ActiveConnection().GetState()
+		e.mu.Unlock()
+	}
+}
+
+func (e *etcdPool) closeClient(client *clientV3.Client) {
+	if err := client.Close(); err != nil {
+		e.sLog.Error(env.MSG+"etcdPool: Close the client failed", "err", err)
+	}
+}
+
+func isHealthy(client *clientV3.Client) bool {
+	switch getStateActiveConn(client) {
+	case connectivity.Shutdown, connectivity.TransientFailure:
+		return false
+	}
+	return client.ActiveConnection() != nil
+}
+
+func getStateActiveConn(client *clientV3.Client) connectivity.State {
+	if client == nil || client.ActiveConnection() == nil {
+		return connectivity.Shutdown
 	}
-	return -1
+	return client.ActiveConnection().GetState()
 }
 
 //!-
@@ -0,0 +1,173 @@
+/*
+ * This file was last modified at 2026-10-18 15:10 by Victor N. Skurikhin.
+ * This is free and unencumbered software released into the public domain.
+ * For more information, please refer to <http://unlicense.org>
+ * etcd_pool_test.go
+ * $Id$
+ */
+//!+
+
+package etcd_pool
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
+	"github.com/victor-skurikhin/etcd-client/v1/pool"
+	"log/slog"
+	"testing"
+	"time"
+
+	clientV3 "go.etcd.io/etcd/client/v3"
+)
+
+func TestEtcdPool(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fRun func(*testing.T) (interface{}, error)
+		want func(*testing.T, interface{}) bool
+	}{
+		{
+			"test #0 positive for function AcquireClient(context.Context) reuse idle client",
+			positiveAcquireClientReuse,
+			positiveAcquireClientReuseCheck,
+		},
+		{
+			"test #1 negative for function AcquireClient(context.Context) wait until context done",
+			negativeAcquireClientWait,
+			negativeAcquireClientWaitCheck,
+		},
+		{
+			"test #2 positive for function AcquireClient(context.Context) wake up on released client",
+			positiveAcquireClientHandOff,
+			positiveAcquireClientHandOffCheck,
+		},
+		{
+			"test #3 negative for function AcquireClient(context.Context) after GracefulClose",
+			negativeAcquireClientClosed,
+			negativeAcquireClientClosedCheck,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fRun(t)
+			assert.Nil(t, err)
+			assert.NotNil(t, got)
+			assert.True(t, test.want(t, got))
+		})
+	}
+}
+
+func newTestEtcdPool(maxOpen int) *etcdPool {
+	return newEtcdPool(
+		clientV3.Config{Endpoints: []string{"localhost:0"}},
+		env.PoolConfig{HealthInterval: time.Hour, MaxIdle: maxOpen, MaxOpen: maxOpen},
+		slog.Default(),
+	)
+}
+
+func positiveAcquireClientReuse(_ *testing.T) (interface{}, error) {
+
+	p := newTestEtcdPool(1)
+	defer func() { _ = p.GracefulClose() }()
+	first, err := p.AcquireClient(context.Background())
+
+	if err != nil {
+		return nil, err
+	}
+	if err = p.ReleaseClient(first); err != nil {
+		return nil, err
+	}
+	second, err := p.AcquireClient(context.Background())
+
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = p.ReleaseClient(second) }()
+
+	return p.Stats(), nil
+}
+
+func positiveAcquireClientReuseCheck(t *testing.T, i interface{}) bool {
+	return assert.Equal(t, pool.Stats{InUse: 1, Open: 1}, i)
+}
+
+func negativeAcquireClientWait(_ *testing.T) (interface{}, error) {
+
+	p := newTestEtcdPool(1)
+	defer func() { _ = p.GracefulClose() }()
+	client, err := p.AcquireClient(context.Background())
+
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = p.ReleaseClient(client) }()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = p.AcquireClient(ctx)
+
+	return []interface{}{err, p.Stats()}, nil
+}
+
+func negativeAcquireClientWaitCheck(t *testing.T, i interface{}) bool {
+	if got, ok := i.([]interface{}); ok {
+		stats := got[1].(pool.Stats)
+		return assert.True(t, errors.Is(got[0].(error), context.DeadlineExceeded)) &&
+			assert.Equal(t, int64(1), stats.WaitCount) &&
+			assert.GreaterOrEqual(t, stats.WaitDuration, 50*time.Millisecond)
+	}
+	return false
+}
+
+func positiveAcquireClientHandOff(_ *testing.T) (interface{}, error) {
+
+	p := newTestEtcdPool(1)
+	defer func() { _ = p.GracefulClose() }()
+	client, err := p.AcquireClient(context.Background())
+
+	if err != nil {
+		return nil, err
+	}
+	time.AfterFunc(20*time.Millisecond, func() { _ = p.ReleaseClient(client) })
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	got, err := p.AcquireClient(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = p.ReleaseClient(got) }()
+
+	return p.Stats(), nil
+}
+
+func positiveAcquireClientHandOffCheck(t *testing.T, i interface{}) bool {
+	if stats, ok := i.(pool.Stats); ok {
+		return assert.Equal(t, 1, stats.InUse) &&
+			assert.Equal(t, 1, stats.Open) &&
+			assert.Equal(t, int64(1), stats.WaitCount)
+	}
+	return false
+}
+
+func negativeAcquireClientClosed(_ *testing.T) (interface{}, error) {
+
+	p := newTestEtcdPool(1)
+
+	if err := p.GracefulClose(); err != nil {
+		return nil, err
+	}
+	_, err := p.AcquireClient(context.Background())
+
+	return err, nil
+}
+
+func negativeAcquireClientClosedCheck(t *testing.T, i interface{}) bool {
+	if err, ok := i.(error); ok {
+		return assert.ErrorIs(t, err, ErrPoolClosed)
+	}
+	return false
+}
+
+//!-
+/* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
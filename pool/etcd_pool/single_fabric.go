@@ -1,10 +1,12 @@
 package etcd_pool
 
 import (
+	"context"
 	"github.com/victor-skurikhin/etcd-client/v1/internal/env"
 	"github.com/victor-skurikhin/etcd-client/v1/pool"
 	clientV3 "go.etcd.io/etcd/client/v3"
 	"sync"
+	"sync/atomic"
 )
 
 var _ pool.EtcdPool = (*singleFabricEtcdClient)(nil)
@@ -16,6 +18,7 @@ var (
 
 type singleFabricEtcdClient struct {
 	clientConfig clientV3.Config
+	inUse        atomic.Int64
 }
 
 func GetSingleFabricEtcdClient(cfg env.Config) pool.EtcdPool {
@@ -26,12 +29,27 @@ func GetSingleFabricEtcdClient(cfg env.Config) pool.EtcdPool {
 	return singleFabricEtcdClientInst
 }
 
-func (s *singleFabricEtcdClient) AcquireClient() (clientV3.KV, error) {
-	return clientV3.New(s.clientConfig)
+// NewSingleFabric — создание фабрики клиентов etcd вне синглтона.
+func NewSingleFabric(clientConfig clientV3.Config) pool.EtcdPool {
+	return &singleFabricEtcdClient{clientConfig: clientConfig}
+}
+
+func (s *singleFabricEtcdClient) AcquireClient(ctx context.Context) (clientV3.KV, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	client, err := clientV3.New(s.clientConfig)
+
+	if err == nil {
+		s.inUse.Add(1)
+	}
+	return client, err
 }
 
 func (s *singleFabricEtcdClient) ReleaseClient(client clientV3.KV) error {
 	if cli, ok := client.(*clientV3.Client); ok {
+		s.inUse.Add(-1)
 		return cli.Close()
 	}
 	return nil
@@ -40,3 +58,8 @@ func (s *singleFabricEtcdClient) ReleaseClient(client clientV3.KV) error {
 func (s *singleFabricEtcdClient) GracefulClose() error {
 	return nil
 }
+
+func (s *singleFabricEtcdClient) Stats() pool.Stats {
+	inUse := int(s.inUse.Load())
+	return pool.Stats{InUse: inUse, Open: inUse}
+}
@@ -1,11 +1,23 @@
 package pool
 
 import (
+	"context"
 	clientV3 "go.etcd.io/etcd/client/v3"
+	"time"
 )
 
 type EtcdPool interface {
-	AcquireClient() (clientV3.KV, error)
+	AcquireClient(ctx context.Context) (clientV3.KV, error)
 	ReleaseClient(clientV3.KV) error
 	GracefulClose() error
+	Stats() Stats
+}
+
+// Stats статистика использования пула клиентов.
+type Stats struct {
+	Idle         int           `json:"idle"`
+	InUse        int           `json:"in_use"`
+	Open         int           `json:"open"`
+	WaitCount    int64         `json:"wait_count"`
+	WaitDuration time.Duration `json:"wait_duration"`
 }
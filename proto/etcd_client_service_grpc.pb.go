@@ -19,9 +19,15 @@ import (
 const _ = grpc.SupportPackageIsVersion8
 
 const (
-	EtcdClientService_Delete_FullMethodName = "/proto.EtcdClientService/Delete"
-	EtcdClientService_Get_FullMethodName    = "/proto.EtcdClientService/Get"
-	EtcdClientService_Put_FullMethodName    = "/proto.EtcdClientService/Put"
+	EtcdClientService_Delete_FullMethodName         = "/proto.EtcdClientService/Delete"
+	EtcdClientService_Get_FullMethodName            = "/proto.EtcdClientService/Get"
+	EtcdClientService_LeaseGrant_FullMethodName     = "/proto.EtcdClientService/LeaseGrant"
+	EtcdClientService_LeaseKeepAlive_FullMethodName = "/proto.EtcdClientService/LeaseKeepAlive"
+	EtcdClientService_LeaseRevoke_FullMethodName    = "/proto.EtcdClientService/LeaseRevoke"
+	EtcdClientService_List_FullMethodName           = "/proto.EtcdClientService/List"
+	EtcdClientService_Put_FullMethodName            = "/proto.EtcdClientService/Put"
+	EtcdClientService_Txn_FullMethodName            = "/proto.EtcdClientService/Txn"
+	EtcdClientService_Watch_FullMethodName          = "/proto.EtcdClientService/Watch"
 )
 
 // EtcdClientServiceClient is the client API for EtcdClientService service.
@@ -30,7 +36,13 @@ const (
 type EtcdClientServiceClient interface {
 	Delete(ctx context.Context, in *EtcdClientRequest, opts ...grpc.CallOption) (*EtcdClientResponse, error)
 	Get(ctx context.Context, in *EtcdClientRequest, opts ...grpc.CallOption) (*EtcdClientResponse, error)
+	LeaseGrant(ctx context.Context, in *LeaseGrantRequest, opts ...grpc.CallOption) (*LeaseResponse, error)
+	LeaseKeepAlive(ctx context.Context, in *LeaseRequest, opts ...grpc.CallOption) (*LeaseResponse, error)
+	LeaseRevoke(ctx context.Context, in *LeaseRequest, opts ...grpc.CallOption) (*LeaseResponse, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
 	Put(ctx context.Context, in *EtcdClientRequest, opts ...grpc.CallOption) (*EtcdClientResponse, error)
+	Txn(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (EtcdClientService_WatchClient, error)
 }
 
 type etcdClientServiceClient struct {
@@ -61,6 +73,46 @@ func (c *etcdClientServiceClient) Get(ctx context.Context, in *EtcdClientRequest
 	return out, nil
 }
 
+func (c *etcdClientServiceClient) LeaseGrant(ctx context.Context, in *LeaseGrantRequest, opts ...grpc.CallOption) (*LeaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaseResponse)
+	err := c.cc.Invoke(ctx, EtcdClientService_LeaseGrant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *etcdClientServiceClient) LeaseKeepAlive(ctx context.Context, in *LeaseRequest, opts ...grpc.CallOption) (*LeaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaseResponse)
+	err := c.cc.Invoke(ctx, EtcdClientService_LeaseKeepAlive_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *etcdClientServiceClient) LeaseRevoke(ctx context.Context, in *LeaseRequest, opts ...grpc.CallOption) (*LeaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaseResponse)
+	err := c.cc.Invoke(ctx, EtcdClientService_LeaseRevoke_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *etcdClientServiceClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, EtcdClientService_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *etcdClientServiceClient) Put(ctx context.Context, in *EtcdClientRequest, opts ...grpc.CallOption) (*EtcdClientResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(EtcdClientResponse)
@@ -71,13 +123,62 @@ func (c *etcdClientServiceClient) Put(ctx context.Context, in *EtcdClientRequest
 	return out, nil
 }
 
+func (c *etcdClientServiceClient) Txn(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, EtcdClientService_Txn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *etcdClientServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (EtcdClientService_WatchClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EtcdClientService_ServiceDesc.Streams[0], EtcdClientService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &etcdClientServiceWatchClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EtcdClientService_WatchClient interface {
+	Recv() (*WatchEvent, error)
+	grpc.ClientStream
+}
+
+type etcdClientServiceWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *etcdClientServiceWatchClient) Recv() (*WatchEvent, error) {
+	m := new(WatchEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // EtcdClientServiceServer is the server API for EtcdClientService service.
 // All implementations must embed UnimplementedEtcdClientServiceServer
 // for forward compatibility
 type EtcdClientServiceServer interface {
 	Delete(context.Context, *EtcdClientRequest) (*EtcdClientResponse, error)
 	Get(context.Context, *EtcdClientRequest) (*EtcdClientResponse, error)
+	LeaseGrant(context.Context, *LeaseGrantRequest) (*LeaseResponse, error)
+	LeaseKeepAlive(context.Context, *LeaseRequest) (*LeaseResponse, error)
+	LeaseRevoke(context.Context, *LeaseRequest) (*LeaseResponse, error)
+	List(context.Context, *ListRequest) (*ListResponse, error)
 	Put(context.Context, *EtcdClientRequest) (*EtcdClientResponse, error)
+	Txn(context.Context, *TxnRequest) (*TxnResponse, error)
+	Watch(*WatchRequest, EtcdClientService_WatchServer) error
 	mustEmbedUnimplementedEtcdClientServiceServer()
 }
 
@@ -91,9 +192,27 @@ func (UnimplementedEtcdClientServiceServer) Delete(context.Context, *EtcdClientR
 func (UnimplementedEtcdClientServiceServer) Get(context.Context, *EtcdClientRequest) (*EtcdClientResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
 }
+func (UnimplementedEtcdClientServiceServer) LeaseGrant(context.Context, *LeaseGrantRequest) (*LeaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LeaseGrant not implemented")
+}
+func (UnimplementedEtcdClientServiceServer) LeaseKeepAlive(context.Context, *LeaseRequest) (*LeaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LeaseKeepAlive not implemented")
+}
+func (UnimplementedEtcdClientServiceServer) LeaseRevoke(context.Context, *LeaseRequest) (*LeaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LeaseRevoke not implemented")
+}
+func (UnimplementedEtcdClientServiceServer) List(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
 func (UnimplementedEtcdClientServiceServer) Put(context.Context, *EtcdClientRequest) (*EtcdClientResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Put not implemented")
 }
+func (UnimplementedEtcdClientServiceServer) Txn(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Txn not implemented")
+}
+func (UnimplementedEtcdClientServiceServer) Watch(*WatchRequest, EtcdClientService_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
 func (UnimplementedEtcdClientServiceServer) mustEmbedUnimplementedEtcdClientServiceServer() {}
 
 // UnsafeEtcdClientServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -143,6 +262,78 @@ func _EtcdClientService_Get_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EtcdClientService_LeaseGrant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseGrantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EtcdClientServiceServer).LeaseGrant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EtcdClientService_LeaseGrant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EtcdClientServiceServer).LeaseGrant(ctx, req.(*LeaseGrantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EtcdClientService_LeaseKeepAlive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EtcdClientServiceServer).LeaseKeepAlive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EtcdClientService_LeaseKeepAlive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EtcdClientServiceServer).LeaseKeepAlive(ctx, req.(*LeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EtcdClientService_LeaseRevoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EtcdClientServiceServer).LeaseRevoke(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EtcdClientService_LeaseRevoke_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EtcdClientServiceServer).LeaseRevoke(ctx, req.(*LeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EtcdClientService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EtcdClientServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EtcdClientService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EtcdClientServiceServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _EtcdClientService_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(EtcdClientRequest)
 	if err := dec(in); err != nil {
@@ -161,6 +352,45 @@ func _EtcdClientService_Put_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EtcdClientService_Txn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EtcdClientServiceServer).Txn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EtcdClientService_Txn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EtcdClientServiceServer).Txn(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EtcdClientService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EtcdClientServiceServer).Watch(m, &etcdClientServiceWatchServer{ServerStream: stream})
+}
+
+type EtcdClientService_WatchServer interface {
+	Send(*WatchEvent) error
+	grpc.ServerStream
+}
+
+type etcdClientServiceWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *etcdClientServiceWatchServer) Send(m *WatchEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // EtcdClientService_ServiceDesc is the grpc.ServiceDesc for EtcdClientService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -176,11 +406,37 @@ var EtcdClientService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Get",
 			Handler:    _EtcdClientService_Get_Handler,
 		},
+		{
+			MethodName: "LeaseGrant",
+			Handler:    _EtcdClientService_LeaseGrant_Handler,
+		},
+		{
+			MethodName: "LeaseKeepAlive",
+			Handler:    _EtcdClientService_LeaseKeepAlive_Handler,
+		},
+		{
+			MethodName: "LeaseRevoke",
+			Handler:    _EtcdClientService_LeaseRevoke_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _EtcdClientService_List_Handler,
+		},
 		{
 			MethodName: "Put",
 			Handler:    _EtcdClientService_Put_Handler,
 		},
+		{
+			MethodName: "Txn",
+			Handler:    _EtcdClientService_Txn_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _EtcdClientService_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/etcd_client_service.proto",
 }
@@ -0,0 +1,300 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v5.27.1
+// source: proto/key_value_data_service.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	KeyValueDataService_Delete_FullMethodName  = "/proto.KeyValueDataService/Delete"
+	KeyValueDataService_Get_FullMethodName     = "/proto.KeyValueDataService/Get"
+	KeyValueDataService_History_FullMethodName = "/proto.KeyValueDataService/History"
+	KeyValueDataService_List_FullMethodName    = "/proto.KeyValueDataService/List"
+	KeyValueDataService_Put_FullMethodName     = "/proto.KeyValueDataService/Put"
+	KeyValueDataService_Txn_FullMethodName     = "/proto.KeyValueDataService/Txn"
+)
+
+// KeyValueDataServiceClient is the client API for KeyValueDataService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type KeyValueDataServiceClient interface {
+	Delete(ctx context.Context, in *KeyValueDataRequest, opts ...grpc.CallOption) (*KeyValueDataResponse, error)
+	Get(ctx context.Context, in *KeyValueDataRequest, opts ...grpc.CallOption) (*KeyValueDataResponse, error)
+	History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*KeyValueDataListResponse, error)
+	Put(ctx context.Context, in *KeyValueDataRequest, opts ...grpc.CallOption) (*KeyValueDataResponse, error)
+	Txn(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+}
+
+type keyValueDataServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKeyValueDataServiceClient(cc grpc.ClientConnInterface) KeyValueDataServiceClient {
+	return &keyValueDataServiceClient{cc}
+}
+
+func (c *keyValueDataServiceClient) Delete(ctx context.Context, in *KeyValueDataRequest, opts ...grpc.CallOption) (*KeyValueDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KeyValueDataResponse)
+	err := c.cc.Invoke(ctx, KeyValueDataService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueDataServiceClient) Get(ctx context.Context, in *KeyValueDataRequest, opts ...grpc.CallOption) (*KeyValueDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KeyValueDataResponse)
+	err := c.cc.Invoke(ctx, KeyValueDataService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueDataServiceClient) History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HistoryResponse)
+	err := c.cc.Invoke(ctx, KeyValueDataService_History_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueDataServiceClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*KeyValueDataListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KeyValueDataListResponse)
+	err := c.cc.Invoke(ctx, KeyValueDataService_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueDataServiceClient) Put(ctx context.Context, in *KeyValueDataRequest, opts ...grpc.CallOption) (*KeyValueDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KeyValueDataResponse)
+	err := c.cc.Invoke(ctx, KeyValueDataService_Put_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueDataServiceClient) Txn(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, KeyValueDataService_Txn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// KeyValueDataServiceServer is the server API for KeyValueDataService service.
+// All implementations must embed UnimplementedKeyValueDataServiceServer
+// for forward compatibility
+type KeyValueDataServiceServer interface {
+	Delete(context.Context, *KeyValueDataRequest) (*KeyValueDataResponse, error)
+	Get(context.Context, *KeyValueDataRequest) (*KeyValueDataResponse, error)
+	History(context.Context, *HistoryRequest) (*HistoryResponse, error)
+	List(context.Context, *ListRequest) (*KeyValueDataListResponse, error)
+	Put(context.Context, *KeyValueDataRequest) (*KeyValueDataResponse, error)
+	Txn(context.Context, *TxnRequest) (*TxnResponse, error)
+	mustEmbedUnimplementedKeyValueDataServiceServer()
+}
+
+// UnimplementedKeyValueDataServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedKeyValueDataServiceServer struct {
+}
+
+func (UnimplementedKeyValueDataServiceServer) Delete(context.Context, *KeyValueDataRequest) (*KeyValueDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedKeyValueDataServiceServer) Get(context.Context, *KeyValueDataRequest) (*KeyValueDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedKeyValueDataServiceServer) History(context.Context, *HistoryRequest) (*HistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method History not implemented")
+}
+func (UnimplementedKeyValueDataServiceServer) List(context.Context, *ListRequest) (*KeyValueDataListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedKeyValueDataServiceServer) Put(context.Context, *KeyValueDataRequest) (*KeyValueDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Put not implemented")
+}
+func (UnimplementedKeyValueDataServiceServer) Txn(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Txn not implemented")
+}
+func (UnimplementedKeyValueDataServiceServer) mustEmbedUnimplementedKeyValueDataServiceServer() {}
+
+// UnsafeKeyValueDataServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KeyValueDataServiceServer will
+// result in compilation errors.
+type UnsafeKeyValueDataServiceServer interface {
+	mustEmbedUnimplementedKeyValueDataServiceServer()
+}
+
+func RegisterKeyValueDataServiceServer(s grpc.ServiceRegistrar, srv KeyValueDataServiceServer) {
+	s.RegisterService(&KeyValueDataService_ServiceDesc, srv)
+}
+
+func _KeyValueDataService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyValueDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueDataServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueDataService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueDataServiceServer).Delete(ctx, req.(*KeyValueDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueDataService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyValueDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueDataServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueDataService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueDataServiceServer).Get(ctx, req.(*KeyValueDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueDataService_History_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueDataServiceServer).History(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueDataService_History_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueDataServiceServer).History(ctx, req.(*HistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueDataService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueDataServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueDataService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueDataServiceServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueDataService_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyValueDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueDataServiceServer).Put(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueDataService_Put_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueDataServiceServer).Put(ctx, req.(*KeyValueDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueDataService_Txn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueDataServiceServer).Txn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueDataService_Txn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueDataServiceServer).Txn(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// KeyValueDataService_ServiceDesc is the grpc.ServiceDesc for KeyValueDataService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var KeyValueDataService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.KeyValueDataService",
+	HandlerType: (*KeyValueDataServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Delete",
+			Handler:    _KeyValueDataService_Delete_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _KeyValueDataService_Get_Handler,
+		},
+		{
+			MethodName: "History",
+			Handler:    _KeyValueDataService_History_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _KeyValueDataService_List_Handler,
+		},
+		{
+			MethodName: "Put",
+			Handler:    _KeyValueDataService_Put_Handler,
+		},
+		{
+			MethodName: "Txn",
+			Handler:    _KeyValueDataService_Txn_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/key_value_data_service.proto",
+}
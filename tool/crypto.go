@@ -12,15 +12,19 @@ package tool
 
 import (
 	"crypto/aes"
+	"crypto/cipher"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 )
 
 var (
-	ErrEncryptAES = fmt.Errorf("encrypt with AES")
-	ErrEncryptRSA = fmt.Errorf("encrypt with RSA")
-	ErrDecryptRSA = fmt.Errorf("decrypt with RSA")
+	ErrDecryptAESGCM = fmt.Errorf("decrypt with AES-GCM")
+	ErrEncryptAES    = fmt.Errorf("encrypt with AES")
+	ErrEncryptRSA    = fmt.Errorf("encrypt with RSA")
+	ErrDecryptRSA    = fmt.Errorf("decrypt with RSA")
 )
 
 func EncryptAES(secretKey, plain []byte) ([]byte, error) {
@@ -49,6 +53,50 @@ func DecryptAES(secretKey, bytes []byte) ([]byte, error) {
 	return plain, nil
 }
 
+// EncryptAESGCM шифрование AES-GCM со случайным nonce, который
+// записывается перед шифротекстом; aad проверяется при расшифровке,
+// но не шифруется.
+func EncryptAESGCM(secretKey, plain, aad []byte) ([]byte, error) {
+
+	gcm, err := newGCM(secretKey)
+
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, aad), nil
+}
+
+// DecryptAESGCM расшифровка результата EncryptAESGCM с тем же aad.
+func DecryptAESGCM(secretKey, bytes, aad []byte) ([]byte, error) {
+
+	gcm, err := newGCM(secretKey)
+
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, ErrDecryptAESGCM
+	}
+	nonce, ciphertext := bytes[:gcm.NonceSize()], bytes[gcm.NonceSize():]
+
+	return gcm.Open(nil, nonce, ciphertext, aad)
+}
+
+func newGCM(secretKey []byte) (cipher.AEAD, error) {
+
+	cipherBlock, err := aes.NewCipher(secretKey)
+
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(cipherBlock)
+}
+
 func EncryptRSA(rsaPublicKey *rsa.PublicKey, plain []byte) ([]byte, error) {
 
 	if rsaPublicKey != nil {
@@ -73,5 +121,31 @@ func DecryptRSA(rsaPrivateKey *rsa.PrivateKey, bytes []byte) ([]byte, error) {
 	return nil, ErrDecryptRSA
 }
 
+// EncryptRSAOAEP шифрование RSA-OAEP с SHA-256.
+func EncryptRSAOAEP(rsaPublicKey *rsa.PublicKey, plain []byte) ([]byte, error) {
+
+	if rsaPublicKey != nil {
+		return rsa.EncryptOAEP(sha256.New(), rand.Reader, rsaPublicKey, plain, nil)
+	}
+	return nil, ErrEncryptRSA
+}
+
+// DecryptRSAOAEP расшифровка результата EncryptRSAOAEP.
+func DecryptRSAOAEP(rsaPrivateKey *rsa.PrivateKey, bytes []byte) ([]byte, error) {
+
+	if rsaPrivateKey != nil {
+		return rsa.DecryptOAEP(sha256.New(), nil, rsaPrivateKey, bytes, nil)
+	}
+	return nil, ErrDecryptRSA
+}
+
+// HashSHA256 SHA-256 строки s в шестнадцатеричном виде.
+func HashSHA256(s string) string {
+
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:])
+}
+
 //!-
 /* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
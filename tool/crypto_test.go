@@ -46,6 +46,22 @@ func TestCrypto(t *testing.T) {
 			name: "negative test #4 RSA",
 			fRun: testRSANegativeCase,
 		},
+		{
+			name: "positive test #5 SHA-256",
+			fRun: testHashSHA256PositiveCase,
+		},
+		{
+			name: "positive test #6 AES-GCM",
+			fRun: testAESGCMPositiveCase,
+		},
+		{
+			name: "negative test #7 AES-GCM wrong aad",
+			fRun: testAESGCMNegativeCase,
+		},
+		{
+			name: "positive test #8 RSA-OAEP",
+			fRun: testRSAOAEPPositiveCase,
+		},
 	}
 	assert.NotNil(t, t)
 	for _, test := range tests {
@@ -108,5 +124,45 @@ func testRSANegativeCase(t *testing.T) {
 	assert.Equal(t, expected, string(got))
 }
 
+func testHashSHA256PositiveCase(t *testing.T) {
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", HashSHA256(""))
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", HashSHA256("abc"))
+}
+
+func testAESGCMPositiveCase(t *testing.T) {
+	secret := make([]byte, 32)
+	if _, err := rand.Reader.Read(secret); err != nil {
+		t.Fail()
+	}
+	expected := "Supercalifragilisticexpialidocious"
+	encrypt, err := EncryptAESGCM(secret, []byte(expected), []byte("/secrets/key"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(encrypt), expected)
+	got, err := DecryptAESGCM(secret, encrypt, []byte("/secrets/key"))
+	assert.Nil(t, err)
+	assert.Equal(t, expected, string(got))
+}
+
+func testAESGCMNegativeCase(t *testing.T) {
+	secret := make([]byte, 32)
+	encrypt, err := EncryptAESGCM(secret, []byte("test"), []byte("/secrets/key"))
+	assert.Nil(t, err)
+	_, err = DecryptAESGCM(secret, encrypt, []byte("/secrets/other"))
+	assert.NotNil(t, err)
+	_, err = DecryptAESGCM(secret, encrypt[:4], nil)
+	assert.ErrorIs(t, err, ErrDecryptAESGCM)
+}
+
+func testRSAOAEPPositiveCase(t *testing.T) {
+	expected := make([]byte, 32)
+	encrypt, err := EncryptRSAOAEP(LoadPublicKey("test_public-key.pem"), expected)
+	assert.Nil(t, err)
+	got, err := DecryptRSAOAEP(LoadPrivateKey("test_private-key.pem"), encrypt)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+	_, err = EncryptRSAOAEP(nil, expected)
+	assert.ErrorIs(t, err, ErrEncryptRSA)
+}
+
 //!-
 /* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -39,7 +39,56 @@ func LoadClientTLSCredentials(caCertFile string) (credentials.TransportCredentia
 	return credentials.NewTLS(config), nil
 }
 
-func LoadServerTLSCredentials(certFile, keyFile string) (credentials.TransportCredentials, error) {
+// LoadClientTLSConfig TLS конфигурация клиента: сертификат сервера проверяется
+// по caFile (пустой — по системным корневым сертификатам), клиентский
+// сертификат certFile и ключ keyFile передаются серверу, если заданы.
+func LoadClientTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pemServerCA, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(pemServerCA) {
+			return nil, fmt.Errorf("failed to add server CA's certificate")
+		}
+		config.RootCAs = certPool
+	}
+	if certFile == "" && keyFile == "" {
+		return config, nil
+	}
+	// Загрузка клиентского сертификата и закрытого ключа.
+	clientCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config.Certificates = []tls.Certificate{clientCert}
+
+	return config, nil
+}
+
+// LoadServerTLSCredentials TLS реквизиты gRPC-сервера, см. LoadServerTLSConfig.
+func LoadServerTLSCredentials(
+	certFile, keyFile, caFile string,
+	clientAuth tls.ClientAuthType,
+) (credentials.TransportCredentials, error) {
+
+	config, err := LoadServerTLSConfig(certFile, keyFile, caFile, clientAuth)
+
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// LoadServerTLSConfig TLS конфигурация сервера; при проверке клиентских
+// сертификатов (clientAuth не tls.NoClientCert) они проверяются
+// по сертификату центра сертификации из caFile.
+func LoadServerTLSConfig(certFile, keyFile, caFile string, clientAuth tls.ClientAuthType) (*tls.Config, error) {
 
 	// Загрузка серверного сертификата и закрытого ключа.
 	serverCert, err := tls.LoadX509KeyPair(certFile, keyFile)
@@ -50,9 +99,24 @@ func LoadServerTLSCredentials(certFile, keyFile string) (credentials.TransportCr
 	// Создание учётных данных для конфигурации TLS.
 	config := &tls.Config{
 		Certificates: []tls.Certificate{serverCert},
-		ClientAuth:   tls.NoClientCert,
+		ClientAuth:   clientAuth,
 	}
-	return credentials.NewTLS(config), nil
+	if clientAuth == tls.NoClientCert {
+		return config, nil
+	}
+	// Загрузка сертификата центра сертификации, подписавшего сертификаты клиентов.
+	pemClientCA, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(pemClientCA) {
+		return nil, fmt.Errorf("failed to add client CA's certificate")
+	}
+	config.ClientCAs = certPool
+
+	return config, nil
 }
 
 //!-
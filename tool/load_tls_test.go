@@ -10,6 +10,7 @@
 package tool
 
 import (
+	"crypto/tls"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -75,6 +76,56 @@ func positiveLoadClientTLSCredentials(t *testing.T, caCertFile string) {
 	assert.Equal(t, expectedInfo, got.Info())
 }
 
+func TestLoadClientTLSConfig(t *testing.T) {
+	type input struct {
+		caFile   string
+		certFile string
+		keyFile  string
+	}
+	var tests = []struct {
+		name  string
+		input input
+		err   bool
+	}{
+		{
+			name:  "positive test #0 LoadClientTLSConfig with CA file",
+			input: input{caFile: "test_ca-cert.pem"},
+		},
+		{
+			name: "positive test #1 LoadClientTLSConfig with client certificate",
+			input: input{
+				caFile:   "test_ca-cert.pem",
+				certFile: "test_server-cert.pem",
+				keyFile:  "test_server-key.pem",
+			},
+		},
+		{
+			name:  "negative test #2 LoadClientTLSConfig bad CA file",
+			input: input{caFile: "test_server-key.pem"},
+			err:   true,
+		},
+		{
+			name:  "negative test #3 LoadClientTLSConfig certificate without key",
+			input: input{certFile: "test_server-cert.pem"},
+			err:   true,
+		},
+	}
+
+	assert.NotNil(t, t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := LoadClientTLSConfig(test.input.caFile, test.input.certFile, test.input.keyFile)
+			if test.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.NotNil(t, got.RootCAs)
+			assert.Equal(t, test.input.certFile != "", len(got.Certificates) > 0)
+		})
+	}
+}
+
 func TestLoadServerTLSCredentials(t *testing.T) {
 	type input struct {
 		certFile string
@@ -112,7 +163,7 @@ func TestLoadServerTLSCredentials(t *testing.T) {
 }
 
 func negativeLoadServerTLSCredentials(t *testing.T, certFile, keyFile string) {
-	_, err := LoadServerTLSCredentials(certFile, keyFile)
+	_, err := LoadServerTLSCredentials(certFile, keyFile, "", tls.NoClientCert)
 	assert.NotNil(t, err)
 }
 
@@ -125,11 +176,76 @@ func positiveLoadServerTLSCredentials(t *testing.T, certFile, keyFile string) {
 			ServerName:       "",
 		})
 
-	got, err := LoadServerTLSCredentials(certFile, keyFile)
+	got, err := LoadServerTLSCredentials(certFile, keyFile, "", tls.NoClientCert)
 	assert.Nil(t, err)
 	assert.NotNil(t, got)
 	assert.Equal(t, expectedInfo, got.Info())
 }
 
+func TestLoadServerTLSConfig(t *testing.T) {
+	type input struct {
+		caFile     string
+		clientAuth tls.ClientAuthType
+	}
+	var tests = []struct {
+		name  string
+		input input
+		fRun  func(*testing.T, string, tls.ClientAuthType)
+	}{
+		{
+			name: "positive test #0 LoadServerTLSConfig require client certificate",
+			input: input{
+				caFile:     "test_ca-cert.pem",
+				clientAuth: tls.RequireAndVerifyClientCert,
+			},
+			fRun: positiveLoadServerTLSConfig,
+		},
+		{
+			name: "positive test #1 LoadServerTLSConfig verify client certificate if given",
+			input: input{
+				caFile:     "test_ca-cert.pem",
+				clientAuth: tls.VerifyClientCertIfGiven,
+			},
+			fRun: positiveLoadServerTLSConfig,
+		},
+		{
+			name: "negative test #2 LoadServerTLSConfig without CA file",
+			input: input{
+				caFile:     "",
+				clientAuth: tls.RequireAndVerifyClientCert,
+			},
+			fRun: negativeLoadServerTLSConfig,
+		},
+		{
+			name: "negative test #3 LoadServerTLSConfig bad CA file",
+			input: input{
+				caFile:     "test_server-key.pem",
+				clientAuth: tls.RequireAndVerifyClientCert,
+			},
+			fRun: negativeLoadServerTLSConfig,
+		},
+	}
+
+	assert.NotNil(t, t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fRun(t, test.input.caFile, test.input.clientAuth)
+		})
+	}
+}
+
+func negativeLoadServerTLSConfig(t *testing.T, caFile string, clientAuth tls.ClientAuthType) {
+	_, err := LoadServerTLSConfig("test_server-cert.pem", "test_server-key.pem", caFile, clientAuth)
+	assert.NotNil(t, err)
+}
+
+func positiveLoadServerTLSConfig(t *testing.T, caFile string, clientAuth tls.ClientAuthType) {
+	got, err := LoadServerTLSConfig("test_server-cert.pem", "test_server-key.pem", caFile, clientAuth)
+	assert.Nil(t, err)
+	assert.NotNil(t, got)
+	assert.Equal(t, clientAuth, got.ClientAuth)
+	assert.NotNil(t, got.ClientCAs)
+}
+
 //!-
 /* vim: set tabstop=4 softtabstop=4 shiftwidth=4 noexpandtab: */
@@ -1,5 +1,5 @@
 /*
- * This file was last modified at 2024-07-31 16:17 by Victor N. Skurikhin.
+ * This file was last modified at 2026-10-18 17:40 by Victor N. Skurikhin.
  * This is free and unencumbered software released into the public domain.
  * For more information, please refer to <http://unlicense.org>
  * trace.go
@@ -13,24 +13,21 @@ package tool
 import (
 	"context"
 	"fmt"
-	"log/slog"
-	"time"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func TraceInOut(ctx context.Context, name, format string, values ...any) func() {
+const tracerName = "github.com/victor-skurikhin/etcd-client/v1/tool"
 
-	if !slog.Default().Enabled(ctx, slog.LevelDebug) {
-		return func() {}
-	}
-	start := time.Now()
-	f := fmt.Sprintf(" %s(", name) + format + ")"
-	i := fmt.Sprintf(f, values...)
-	sLog.DebugContext(ctx, MSG+"trace", "callIn", i)
+// TraceInOut — спан name на время вызова, аргументы вызова по format
+// в атрибуте args. Возвращает контекст со спаном и завершение спана.
+func TraceInOut(ctx context.Context, name, format string, values ...any) (context.Context, func()) {
 
-	return func() {
-		o := fmt.Sprintf("%s [%s]", name, time.Since(start))
-		sLog.DebugContext(ctx, MSG+"trace", "callOut", o)
-	}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name,
+		trace.WithAttributes(attribute.String("args", fmt.Sprintf(format, values...))),
+	)
+	return ctx, func() { span.End() }
 }
 
 //!-
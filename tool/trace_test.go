@@ -1,5 +1,5 @@
 /*
- * This file was last modified at 2024-07-31 16:17 by Victor N. Skurikhin.
+ * This file was last modified at 2026-10-18 17:40 by Victor N. Skurikhin.
  * This is free and unencumbered software released into the public domain.
  * For more information, please refer to <http://unlicense.org>
  * trace_test.go
@@ -12,10 +12,14 @@ package tool
 
 import (
 	"context"
-	"log/slog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestTraceInOut(t *testing.T) {
@@ -24,12 +28,12 @@ func TestTraceInOut(t *testing.T) {
 		fRun func(*testing.T)
 	}{
 		{
-			name: "test #1 positive ",
-			fRun: testTraceInOutLevelInfo,
+			name: "test #1 positive for function TraceInOut without provider",
+			fRun: testTraceInOutNoop,
 		},
 		{
-			name: "test #2 positive ",
-			fRun: testTraceInOutLevelDebug,
+			name: "test #2 positive for function TraceInOut with span recorder",
+			fRun: testTraceInOutRecorder,
 		},
 	}
 
@@ -41,15 +45,27 @@ func TestTraceInOut(t *testing.T) {
 	}
 }
 
-func testTraceInOutLevelInfo(t *testing.T) {
-	slog.SetLogLoggerLevel(slog.LevelInfo)
-	defer TraceInOut(context.TODO(), "name", "%v, %d, %s, %T", true, 13, "test", func() {})
+func testTraceInOutNoop(t *testing.T) {
+	ctx, end := TraceInOut(context.TODO(), "name", "%v, %d, %s, %T", true, 13, "test", func() {})
+	defer end()
+	assert.False(t, trace.SpanFromContext(ctx).IsRecording())
 }
 
-func testTraceInOutLevelDebug(t *testing.T) {
-	slog.SetLogLoggerLevel(slog.LevelDebug)
-	SetLogger(slog.Default())
-	defer TraceInOut(context.TODO(), "name", "%v, %d, %s, %T", true, 13, "test", func() {})
+func testTraceInOutRecorder(t *testing.T) {
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	ctx, end := TraceInOut(context.TODO(), "name", "%v, %d, %s", true, 13, "test")
+	assert.True(t, trace.SpanFromContext(ctx).IsRecording())
+	end()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "name", spans[0].Name())
+	assert.Equal(t, "true, 13, test", spans[0].Attributes()[0].Value.AsString())
 }
 
 //!-